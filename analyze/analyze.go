@@ -17,7 +17,6 @@
 package analyze
 
 import (
-	"encoding/csv"
 	"fmt"
 	"log"
 	"os"
@@ -26,6 +25,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/spencerkimball/stargazers/company"
 	"github.com/spencerkimball/stargazers/fetch"
 )
 
@@ -33,8 +33,13 @@ const (
 	// nMostCorrelated is the number of correlated starred or subscribed
 	// repos to include in the csv output.
 	nMostCorrelated = 50
+	// nCommitterTopRepos is the number of top repos listed per
+	// committer.
+	nCommitterTopRepos = 5
 )
 
+// Stargazers sorts stargazers by the time at which they starred,
+// breaking ties by login.
 type Stargazers []*fetch.Stargazer
 
 func (slice Stargazers) Len() int {
@@ -42,13 +47,18 @@ func (slice Stargazers) Len() int {
 }
 
 func (slice Stargazers) Less(i, j int) bool {
-	return slice[i].StarredAt < slice[j].StarredAt
+	if slice[i].StarredAt != slice[j].StarredAt {
+		return slice[i].StarredAt < slice[j].StarredAt
+	}
+	return slice[i].Login < slice[j].Login
 }
 
 func (slice Stargazers) Swap(i, j int) {
 	slice[i], slice[j] = slice[j], slice[i]
 }
 
+// Contributors sorts stargazers by descending commits, breaking ties
+// by login.
 type Contributors []*fetch.Stargazer
 
 func (slice Contributors) Len() int {
@@ -58,7 +68,10 @@ func (slice Contributors) Len() int {
 func (slice Contributors) Less(i, j int) bool {
 	iC, _, _ := slice[i].TotalCommits()
 	jC, _, _ := slice[j].TotalCommits()
-	return iC > jC /* descending order */
+	if iC != jC {
+		return iC > jC /* descending order */
+	}
+	return slice[i].Login < slice[j].Login
 }
 
 func (slice Contributors) Swap(i, j int) {
@@ -70,6 +83,7 @@ type RepoCount struct {
 	count int
 }
 
+// RepoCounts sorts repos by descending count, breaking ties by name.
 type RepoCounts []*RepoCount
 
 func (slice RepoCounts) Len() int {
@@ -77,72 +91,332 @@ func (slice RepoCounts) Len() int {
 }
 
 func (slice RepoCounts) Less(i, j int) bool {
-	return slice[i].count > slice[j].count /* descending order */
+	if slice[i].count != slice[j].count {
+		return slice[i].count > slice[j].count /* descending order */
+	}
+	return slice[i].name < slice[j].name
 }
 
 func (slice RepoCounts) Swap(i, j int) {
 	slice[i], slice[j] = slice[j], slice[i]
 }
 
-// RunAll runs all analyses.
-func RunAll(c *fetch.Context, sg []*fetch.Stargazer, rs map[string]*fetch.Repo) error {
-	if err := RunCumulativeStars(c, sg); err != nil {
-		return err
+// Results holds the results of all analyses.
+type Results struct {
+	CumulativeStars      *CumulativeStarsResult
+	Forecast             *ForecastResult
+	ForecastModels       *ForecastModelsResult
+	CorrelatedStarred    *CorrelatedReposResult
+	StarredHistogram     *CorrelationHistogramResult
+	CorrelatedSubscribed *CorrelatedReposResult
+	SubscribedHistogram  *CorrelationHistogramResult
+	StarredOwners        *CorrelatedOwnersResult
+	SubscribedOwners     *CorrelatedOwnersResult
+	Influence            *InfluenceResult
+	Followers            *FollowersResult
+	Committers           *CommittersResult
+	CommitterMatrix      *CommitterMatrixResult
+	AttributesByTime     *AttributesByTimeResult
+	Distributions        *DistributionsResult
+	Histograms           *HistogramsResult
+	StargazerReport      *StargazerReportResult
+	SimilarRepos         *SimilarReposResult
+	Recommendations      *RecommendationsResult
+	Communities          *CommunitiesResult
+	StarBursts           *StarBurstsResult
+	ChangePoints         *ChangePointsResult
+	StarQuality          *StarQualityResult
+	CleanedStars         *CumulativeStarsResult
+	Locations            *LocationsResult
+	Geography            *GeographyResult
+	StargazerEmployers   *StargazerEmployersResult
+	Employers            *EmployersResult
+	EmployersByMonth     *EmployersByMonthResult
+	StarHeatmap          *StarHeatmapResult
+	StargazerTimezones   *StargazerTimezonesResult
+	Timezones            *TimezonesResult
+	AudienceHours        *AudienceHoursResult
+	Cohorts              *CohortsResult
+	CohortRetention      *CohortRetentionResult
+	BioKeywords          *KeywordsResult
+	RepoKeywords         *KeywordsResult
+	StargazerRoles       *StargazerRolesResult
+	Roles                *RolesResult
+	RolesByPeriod        *RolesResult
+	Leads                *LeadsResult
+	Shortlists           *ShortlistsResult
+}
+
+// All returns all results in output order.
+func (r *Results) All() []Result {
+	return []Result{
+		r.CumulativeStars,
+		r.Forecast,
+		r.ForecastModels,
+		r.CorrelatedStarred,
+		r.StarredHistogram,
+		r.CorrelatedSubscribed,
+		r.SubscribedHistogram,
+		r.StarredOwners,
+		r.SubscribedOwners,
+		r.Influence,
+		r.Followers,
+		r.Committers,
+		r.CommitterMatrix,
+		r.AttributesByTime,
+		r.Distributions,
+		r.Histograms,
+		r.StargazerReport,
+		r.SimilarRepos,
+		r.Recommendations,
+		r.Communities,
+		r.StarBursts,
+		r.ChangePoints,
+		r.StarQuality,
+		r.CleanedStars,
+		r.Locations,
+		r.Geography,
+		r.StargazerEmployers,
+		r.Employers,
+		r.EmployersByMonth,
+		r.StarHeatmap,
+		r.StargazerTimezones,
+		r.Timezones,
+		r.AudienceHours,
+		r.Cohorts,
+		r.CohortRetention,
+		r.BioKeywords,
+		r.RepoKeywords,
+		r.StargazerRoles,
+		r.Roles,
+		r.RolesByPeriod,
+		r.Leads,
+		r.Shortlists,
 	}
-	if err := RunCorrelatedRepos(c, "starred", sg, rs); err != nil {
-		return err
+}
+
+// Options control how analyses are computed.
+type Options struct {
+	Repo              string  // Analyzed repository (:owner/:repo)
+	CorrelationMetric string  // Ranking metric for correlated repos; see CorrelationMetrics
+	MinSupport        int     // Minimum count for a correlated repo to be ranked
+	Population        float64 // Estimated GitHub users; DefaultPopulation if zero
+	StargazerSort     string  // Sort key for the stargazer report
+	// StargazerJumpWeight is the weight of stargazers relative to
+	// other users as targets of PageRank random jumps, favoring users
+	// followed by and close to stargazers when computing influence;
+	// one if zero. Follower edges themselves are unweighted.
+	StargazerJumpWeight float64
+	// SuspicionThreshold is the star quality score at or above which a
+	// star is suspicious; DefaultSuspicionThreshold if zero.
+	SuspicionThreshold float64
+	// CompanyOverrides are user-supplied company normalization rules;
+	// may be nil.
+	CompanyOverrides *company.Overrides
+	// Snapshots are the stargazers as of each fetch, from oldest to
+	// newest; may be empty.
+	Snapshots []*fetch.Snapshot
+	// Resampler divides time series and tables by period into
+	// calendar periods; nil for the legacy preset.
+	Resampler *Resampler
+	// AsOf is the reference time for ages and recency; the current
+	// time if zero.
+	AsOf time.Time
+	// Roles is the taxonomy of stargazer roles, mapping each role to
+	// the keywords in bios which indicate it; DefaultRoles if nil.
+	Roles map[string][]string
+	// LeadScoring configures the lead scoring analysis; defaults if
+	// nil.
+	LeadScoring *LeadScoring
+	// Redact blanks the profile URLs of stargazers and drops result
+	// rows describing fewer than MinGroupSize stargazers, for
+	// stargazer data which has been redacted.
+	Redact       bool
+	MinGroupSize int
+}
+
+// asOf returns the reference time of the analyses.
+func (o Options) asOf() time.Time {
+	if o.AsOf.IsZero() {
+		return time.Now().UTC().Truncate(time.Second)
 	}
-	if err := RunCorrelatedRepos(c, "subscribed", sg, rs); err != nil {
-		return err
+	return o.AsOf
+}
+
+// ComputeAll computes all analyses without writing any output.
+func ComputeAll(sg []*fetch.Stargazer, rs map[string]*fetch.Repo, opts Options) (*Results, error) {
+	var err error
+	res := &Results{}
+	if res.CumulativeStars, err = CumulativeStars(sg, opts.Resampler); err != nil {
+		return nil, err
 	}
-	if err := RunFollowers(c, sg); err != nil {
-		return err
+	// The forecast models daily stars.
+	daily := res.CumulativeStars
+	if !opts.Resampler.Legacy() && opts.Resampler.Granularity != GranularityDaily {
+		if daily, err = dailyCumulativeStars(sg, opts.Resampler); err != nil {
+			return nil, err
+		}
 	}
-	if err := RunCommitters(c, sg, rs); err != nil {
-		return err
+	res.Forecast, res.ForecastModels = Forecast(daily, opts.asOf(), opts.Resampler)
+	if res.CorrelatedStarred, res.StarredHistogram, err = CorrelatedRepos("starred", sg, rs, opts); err != nil {
+		return nil, err
+	}
+	if res.CorrelatedSubscribed, res.SubscribedHistogram, err = CorrelatedRepos("subscribed", sg, rs, opts); err != nil {
+		return nil, err
+	}
+	res.StarredOwners = CorrelatedOwners("starred", sg, opts)
+	res.SubscribedOwners = CorrelatedOwners("subscribed", sg, opts)
+	res.Influence = Influence(sg, opts)
+	res.Followers = Followers(sg, res.Influence)
+	res.Committers, res.CommitterMatrix = Committers(sg, opts.Repo, res.CorrelatedStarred, res.CorrelatedSubscribed)
+	if res.AttributesByTime, err = AttributesByTime(sg, opts); err != nil {
+		return nil, err
+	}
+	if res.Distributions, res.Histograms, err = Distributions(sg, opts); err != nil {
+		return nil, err
+	}
+	if res.StargazerReport, err = StargazerReport(sg, res.CorrelatedStarred, res.CorrelatedSubscribed,
+		res.Influence, opts.StargazerSort); err != nil {
+		return nil, err
+	}
+	res.SimilarRepos, res.Recommendations = Recommend(sg, rs, opts)
+	if res.StarBursts, res.ChangePoints, err = StarBursts(sg, opts.Resampler); err != nil {
+		return nil, err
+	}
+	if res.StarQuality, res.CleanedStars, err = StarQualityScores(sg, opts.SuspicionThreshold, opts.Resampler); err != nil {
+		return nil, err
+	}
+	if res.Locations, res.Geography, err = Geography(sg, opts.Resampler); err != nil {
+		return nil, err
+	}
+	if res.StargazerEmployers, res.Employers, res.EmployersByMonth, err = Employers(sg, opts); err != nil {
+		return nil, err
+	}
+	res.Communities = Communities(sg, res.StargazerEmployers)
+	if res.StarHeatmap, res.StargazerTimezones, res.Timezones, res.AudienceHours, err = Timezones(sg, opts.Resampler); err != nil {
+		return nil, err
+	}
+	if res.Cohorts, res.CohortRetention, err = Cohorts(sg, opts); err != nil {
+		return nil, err
+	}
+	res.BioKeywords, res.RepoKeywords = Keywords(sg, rs, res.CorrelatedStarred)
+	if res.StargazerRoles, res.Roles, res.RolesByPeriod, err = Roles(sg, opts); err != nil {
+		return nil, err
+	}
+	if res.Leads, res.Shortlists, err = Leads(sg, rs, res.Influence, res.StargazerRoles, opts); err != nil {
+		return nil, err
+	}
+	if opts.Redact {
+		res.suppress(opts.MinGroupSize)
 	}
-	if err := RunAttributesByTime(c, sg, rs); err != nil {
+	return res, nil
+}
+
+// RunAll runs all analyses and writes each result to a file in the
+// repo's cache directory, along with the GeoJSON map of stargazers.
+func RunAll(c *Context, sg []*fetch.Stargazer, rs map[string]*fetch.Repo) error {
+	res, err := ComputeAll(sg, rs, c.Options)
+	if err != nil {
 		return err
 	}
-	return nil
+	for _, r := range res.All() {
+		if err := WriteResult(c, r); err != nil {
+			return err
+		}
+	}
+	return WriteGeoJSON(c, res.Locations)
 }
 
-// RunCumulativeStars creates a table of date and cumulative
-// star count for the provided stargazers.
-func RunCumulativeStars(c *fetch.Context, sg []*fetch.Stargazer) error {
-	log.Printf("running cumulative stars analysis")
+// CumulativeStarsDay holds the count of new stars in a period (a
+// day, under the legacy preset) and the cumulative count of stars
+// through that period.
+type CumulativeStarsDay struct {
+	Date       time.Time `json:"date"`
+	New        int       `json:"new"`
+	Cumulative int       `json:"cumulative"`
+}
 
-	// Open file and prepare.
-	f, err := createFile(c, "cumulative_stars.csv")
-	if err != nil {
-		return fmt.Errorf("failed to create file: %s", err)
+// CumulativeStarsResult is the result of the cumulative stars analysis.
+type CumulativeStarsResult struct {
+	// Cleaned is set when suspicious stars have been excluded.
+	Cleaned bool                  `json:"cleaned,omitempty"`
+	Days    []*CumulativeStarsDay `json:"days"`
+
+	resampler *Resampler
+}
+
+func (r *CumulativeStarsResult) Name() string {
+	if r.Cleaned {
+		return "cumulative_stars_cleaned"
+	}
+	return "cumulative_stars"
+}
+
+func (r *CumulativeStarsResult) Header() []string { return []string{"Date", "New", "Cumulative"} }
+
+func (r *CumulativeStarsResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Days))
+	for _, d := range r.Days {
+		rows = append(rows, []string{r.resampler.formatDate(d.Date), strconv.Itoa(d.New), strconv.Itoa(d.Cumulative)})
 	}
-	defer f.Close()
-	w := csv.NewWriter(f)
-	if err := w.Write([]string{"Date", "New", "Cumulative"}); err != nil {
-		return fmt.Errorf("failed to write to CSV: %s", err)
+	return rows
+}
+
+func (r *CumulativeStarsResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Days))
+	for i, d := range r.Days {
+		recs[i] = d
 	}
+	return recs
+}
+
+// CumulativeStars computes the new and cumulative star counts for
+// the provided stargazers in each period of the resampler. Under the
+// legacy preset, periods are UTC days and days without stars are
+// skipped.
+func CumulativeStars(sg []*fetch.Stargazer, r *Resampler) (*CumulativeStarsResult, error) {
+	log.Printf("running cumulative stars analysis")
+	res := &CumulativeStarsResult{resampler: r}
 
 	// Sort the stargazers.
 	slice := Stargazers(sg)
 	sort.Sort(slice)
 
+	times := make([]time.Time, len(slice))
+	for i, s := range slice {
+		t, err := time.Parse(time.RFC3339, s.StarredAt)
+		if err != nil {
+			return nil, err
+		}
+		times[i] = t
+	}
+	if !r.Legacy() {
+		periods, index := r.Resample(times)
+		for _, p := range periods {
+			res.Days = append(res.Days, &CumulativeStarsDay{Date: p})
+		}
+		for _, i := range index {
+			res.Days[i].New++
+		}
+		total := 0
+		for _, d := range res.Days {
+			total += d.New
+			d.Cumulative = total
+		}
+		return res, nil
+	}
+
 	// Now accumulate by days.
 	lastDay := int64(0)
 	total := 0
 	count := 0
-	for _, s := range slice {
-		t, err := time.Parse(time.RFC3339, s.StarredAt)
-		if err != nil {
-			return err
-		}
+	for _, t := range times {
 		day := t.Unix() / int64(60*60*24)
 		if day != lastDay {
 			if count > 0 {
-				t := time.Unix(lastDay*60*60*24, 0)
-				if err := w.Write([]string{t.Format("01/02/2006"), strconv.Itoa(count), strconv.Itoa(total)}); err != nil {
-					return fmt.Errorf("failed to write to CSV: %s", err)
-				}
+				res.Days = append(res.Days, &CumulativeStarsDay{
+					Date: time.Unix(lastDay*60*60*24, 0), New: count, Cumulative: total,
+				})
 			}
 			lastDay = day
 			count = 1
@@ -152,32 +426,131 @@ func RunCumulativeStars(c *fetch.Context, sg []*fetch.Stargazer) error {
 		total++
 	}
 	if count > 0 {
-		t := time.Unix(lastDay*60*60*24, 0)
-		if err := w.Write([]string{t.Format("01/02/2006"), strconv.Itoa(count), strconv.Itoa(total)}); err != nil {
-			return fmt.Errorf("failed to write to CSV: %s", err)
-		}
+		res.Days = append(res.Days, &CumulativeStarsDay{
+			Date: time.Unix(lastDay*60*60*24, 0), New: count, Cumulative: total,
+		})
+	}
+	return res, nil
+}
+
+// RunCumulativeStars creates a table of date and cumulative
+// star count for the provided stargazers.
+func RunCumulativeStars(c *Context, sg []*fetch.Stargazer) error {
+	res, err := CumulativeStars(sg, c.Options.Resampler)
+	if err != nil {
+		return err
 	}
-	w.Flush()
-	log.Printf("wrote cumulative stars analysis to %s", f.Name())
+	return WriteResult(c, res)
+}
 
-	return nil
+// CorrelatedRepo holds the count of stargazers who have starred or
+// subscribed to a repo, along with the repo's commit statistics and
+// popularity-normalized correlation scores.
+type CorrelatedRepo struct {
+	Name       string  `json:"name"`
+	URL        string  `json:"url"`
+	Count      int     `json:"count"`
+	Stargazers int     `json:"stargazers"`
+	Lift       float64 `json:"lift"`
+	Jaccard    float64 `json:"jaccard"`
+	PMI        float64 `json:"pmi"`
+	Z          float64 `json:"z"`
+	PValue     float64 `json:"p_value"`
+	Committers int     `json:"committers"`
+	Commits    int     `json:"commits"`
+	Additions  int     `json:"additions"`
+	Deletions  int     `json:"deletions"`
 }
 
-// RunCorrelatedRepos creates a map from repo name to count of
-// repos for repo lists of each stargazer.
-func RunCorrelatedRepos(c *fetch.Context, listType string, sg []*fetch.Stargazer, rs map[string]*fetch.Repo) error {
-	log.Printf("running correlated starred repos analysis")
+// CorrelatedReposResult is the result of the correlated repos
+// analysis for either starred or subscribed repos.
+type CorrelatedReposResult struct {
+	ListType   string            `json:"list_type"`
+	Metric     string            `json:"metric"`
+	MinSupport int               `json:"min_support"`
+	Repos      []*CorrelatedRepo `json:"repos"`
+}
+
+func (r *CorrelatedReposResult) Name() string { return fmt.Sprintf("correlated_%s_repos", r.ListType) }
+
+func (r *CorrelatedReposResult) Header() []string {
+	return []string{"Repository", "URL", "Count", "Stargazers", "Lift", "Jaccard", "PMI", "Z-Score", "P-Value",
+		"Committers", "Commits", "Additions", "Deletions"}
+}
 
-	// Open file and prepare.
-	f, err := createFile(c, fmt.Sprintf("correlated_%s_repos.csv", listType))
+func (r *CorrelatedReposResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Repos))
+	for _, cr := range r.Repos {
+		rows = append(rows, []string{cr.Name, cr.URL, strconv.Itoa(cr.Count), strconv.Itoa(cr.Stargazers),
+			fmt.Sprintf("%.2f", cr.Lift), fmt.Sprintf("%.6f", cr.Jaccard), fmt.Sprintf("%.2f", cr.PMI),
+			fmt.Sprintf("%.2f", cr.Z), fmt.Sprintf("%.3g", cr.PValue), strconv.Itoa(cr.Committers),
+			strconv.Itoa(cr.Commits), strconv.Itoa(cr.Additions), strconv.Itoa(cr.Deletions)})
+	}
+	return rows
+}
+
+func (r *CorrelatedReposResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Repos))
+	for i, cr := range r.Repos {
+		recs[i] = cr
+	}
+	return recs
+}
+
+// CorrelationBin holds the number of repos which share the same
+// correlation count.
+type CorrelationBin struct {
+	Correlation int `json:"correlation"`
+	Count       int `json:"count"`
+}
+
+// CorrelationHistogramResult is the histogram of correlation counts
+// over all starred or subscribed repos.
+type CorrelationHistogramResult struct {
+	ListType string            `json:"list_type"`
+	Bins     []*CorrelationBin `json:"bins"`
+}
+
+func (r *CorrelationHistogramResult) Name() string {
+	return fmt.Sprintf("correlated_%s_repos_hist", r.ListType)
+}
+
+func (r *CorrelationHistogramResult) Header() []string { return []string{"Correlation", "Count"} }
+
+func (r *CorrelationHistogramResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Bins))
+	for _, b := range r.Bins {
+		rows = append(rows, []string{strconv.Itoa(b.Correlation), strconv.Itoa(b.Count)})
+	}
+	return rows
+}
+
+func (r *CorrelationHistogramResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Bins))
+	for i, b := range r.Bins {
+		recs[i] = b
+	}
+	return recs
+}
+
+// CorrelatedRepos computes the count of occurrences of each repo in
+// the starred or subscribed repo lists of each stargazer, along with
+// correlation scores normalized by each repo's overall popularity.
+// Returns the repos occurring at least opts.MinSupport times which
+// rank highest by opts.CorrelationMetric, and a histogram of
+// correlation counts over all repos.
+func CorrelatedRepos(listType string, sg []*fetch.Stargazer, rs map[string]*fetch.Repo, opts Options) (
+	*CorrelatedReposResult, *CorrelationHistogramResult, error) {
+	log.Printf("running correlated %s repos analysis", listType)
+	metric, err := correlationMetric(opts.CorrelationMetric)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %s", err)
+		return nil, nil, err
 	}
-	defer f.Close()
-	w := csv.NewWriter(f)
-	if err := w.Write([]string{"Repository", "URL", "Count", "Committers", "Commits", "Additions", "Deletions"}); err != nil {
-		return fmt.Errorf("failed to write to CSV: %s", err)
+	population := opts.Population
+	if population == 0 {
+		population = DefaultPopulation
 	}
+
 	// Compute counts.
 	counts := map[string]int{}
 	for _, s := range sg {
@@ -189,45 +562,57 @@ func RunCorrelatedRepos(c *fetch.Context, listType string, sg []*fetch.Stargazer
 			counts[rName]++
 		}
 	}
-	// Sort repos by count.
-	repos := RepoCounts{}
+	// Score repos meeting the minimum support.
+	var scored []*CorrelatedRepo
 	for rName, count := range counts {
-		repos = append(repos, &RepoCount{name: rName, count: count})
+		if count < opts.MinSupport {
+			continue
+		}
+		r := rs[rName]
+		cs := scoreCorrelation(count, len(sg), r.StargazersCount, population)
+		c, a, d := r.TotalCommits()
+		scored = append(scored, &CorrelatedRepo{
+			Name:       rName,
+			URL:        fmt.Sprintf("https://github.com/%s", r.FullName),
+			Count:      count,
+			Stargazers: r.StargazersCount,
+			Lift:       cs.Lift,
+			Jaccard:    cs.Jaccard,
+			PMI:        cs.PMI,
+			Z:          cs.Z,
+			PValue:     cs.PValue,
+			Committers: len(r.Statistics),
+			Commits:    c,
+			Additions:  a,
+			Deletions:  d,
+		})
 	}
-	sort.Sort(repos)
-	// Output repos by count (respecting minimum threshold).
-	for i, r := range repos {
+	// Sort repos by the ranking metric and output the most correlated.
+	sort.Sort(byMetric{scored, metric})
+	res := &CorrelatedReposResult{ListType: listType, Metric: opts.CorrelationMetric, MinSupport: opts.MinSupport}
+	if len(res.Metric) == 0 {
+		res.Metric = "count"
+	}
+	for i, r := range scored {
 		if i > nMostCorrelated {
 			break
 		}
-		c, a, d := rs[r.name].TotalCommits()
-		url := fmt.Sprintf("https://github.com/%s", rs[r.name].FullName)
-		if err := w.Write([]string{r.name, url, strconv.Itoa(r.count), strconv.Itoa(len(rs[r.name].Statistics)),
-			strconv.Itoa(c), strconv.Itoa(a), strconv.Itoa(d)}); err != nil {
-			return fmt.Errorf("failed to write to CSV: %s", err)
-		}
+		res.Repos = append(res.Repos, r)
 	}
-	w.Flush()
-	log.Printf("wrote correlated %s repos analysis to %s", listType, f.Name())
 
-	// Open histogram file.
-	fHist, err := createFile(c, fmt.Sprintf("correlated_%s_repos_hist.csv", listType))
-	if err != nil {
-		return fmt.Errorf("failed to create file: %s", err)
-	}
-	defer fHist.Close()
-	wHist := csv.NewWriter(fHist)
-	if err := wHist.Write([]string{"Correlation", "Count"}); err != nil {
-		return fmt.Errorf("failed to write to CSV: %s", err)
+	// Compute histogram over all repos, sorted by count.
+	repos := RepoCounts{}
+	for rName, count := range counts {
+		repos = append(repos, &RepoCount{name: rName, count: count})
 	}
+	sort.Sort(repos)
+	hist := &CorrelationHistogramResult{ListType: listType}
 	lastCorrelation := 0
 	count := 0
 	for _, r := range repos {
 		if lastCorrelation != r.count {
 			if count > 0 {
-				if err := wHist.Write([]string{strconv.Itoa(lastCorrelation), strconv.Itoa(count)}); err != nil {
-					return fmt.Errorf("failed to write to CSV: %s", err)
-				}
+				hist.Bins = append(hist.Bins, &CorrelationBin{Correlation: lastCorrelation, Count: count})
 			}
 			lastCorrelation = r.count
 			count = 1
@@ -236,31 +621,71 @@ func RunCorrelatedRepos(c *fetch.Context, listType string, sg []*fetch.Stargazer
 		}
 	}
 	if count > 0 {
-		if err := wHist.Write([]string{strconv.Itoa(lastCorrelation), strconv.Itoa(count)}); err != nil {
-			return fmt.Errorf("failed to write to CSV: %s", err)
-		}
+		hist.Bins = append(hist.Bins, &CorrelationBin{Correlation: lastCorrelation, Count: count})
+	}
+	return res, hist, nil
+}
+
+// RunCorrelatedRepos creates a map from repo name to count of
+// repos for repo lists of each stargazer.
+func RunCorrelatedRepos(c *Context, listType string, sg []*fetch.Stargazer, rs map[string]*fetch.Repo) error {
+	res, hist, err := CorrelatedRepos(listType, sg, rs, c.Options)
+	if err != nil {
+		return err
 	}
-	wHist.Flush()
-	log.Printf("wrote correlated %s repos histogram to %s", listType, fHist.Name())
+	if err := WriteResult(c, res); err != nil {
+		return err
+	}
+	return WriteResult(c, hist)
+}
 
-	return nil
+// FollowerStats holds a stargazer's count of followers and the count
+// of those followers who also follow other stargazers.
+type FollowerStats struct {
+	Name            string  `json:"name"`
+	Login           string  `json:"login"`
+	URL             string  `json:"url"`
+	AvatarURL       string  `json:"avatar_url"`
+	Company         string  `json:"company"`
+	Location        string  `json:"location"`
+	Followers       int     `json:"followers"`
+	SharedFollowers int     `json:"shared_followers"`
+	Influence       float64 `json:"influence"`
 }
 
-// RunFollowers computes the size of follower networks, as well as
-// the count of shared followers.
-func RunFollowers(c *fetch.Context, sg []*fetch.Stargazer) error {
-	log.Printf("running followers analysis")
+// FollowersResult is the result of the followers analysis.
+type FollowersResult struct {
+	Stargazers []*FollowerStats `json:"stargazers"`
+}
 
-	// Open file and prepare.
-	f, err := createFile(c, "followers.csv")
-	if err != nil {
-		return fmt.Errorf("failed to create file: %s", err)
+func (r *FollowersResult) Name() string { return "followers" }
+
+func (r *FollowersResult) Header() []string {
+	return []string{"Name", "Login", "URL", "Avatar URL", "Company", "Location", "Followers", "Shared Followers", "Influence"}
+}
+
+func (r *FollowersResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Stargazers))
+	for _, f := range r.Stargazers {
+		rows = append(rows, []string{f.Name, f.Login, f.URL, f.AvatarURL, f.Company, f.Location,
+			strconv.Itoa(f.Followers), strconv.Itoa(f.SharedFollowers), fmt.Sprintf("%.4f", f.Influence)})
 	}
-	defer f.Close()
-	w := csv.NewWriter(f)
-	if err := w.Write([]string{"Name", "Login", "URL", "Avatar URL", "Company", "Location", "Followers", "Shared Followers"}); err != nil {
-		return fmt.Errorf("failed to write to CSV: %s", err)
+	return rows
+}
+
+func (r *FollowersResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Stargazers))
+	for i, f := range r.Stargazers {
+		recs[i] = f
 	}
+	return recs
+}
+
+// Followers computes the size of follower networks, as well as
+// the count of shared followers. Each stargazer's influence is
+// included from the results of the influence analysis.
+func Followers(sg []*fetch.Stargazer, influence *InfluenceResult) *FollowersResult {
+	log.Printf("running followers analysis")
 
 	shared := map[string]int{}
 	for _, s := range sg {
@@ -270,7 +695,7 @@ func RunFollowers(c *fetch.Context, sg []*fetch.Stargazer) error {
 	}
 
 	// For each stargazer, output followers, and shared followers.
-	// Now accumulate by days.
+	res := &FollowersResult{}
 	for _, s := range sg {
 		sharedCount := 0
 		for _, f := range s.Followers {
@@ -278,86 +703,334 @@ func RunFollowers(c *fetch.Context, sg []*fetch.Stargazer) error {
 				sharedCount++
 			}
 		}
-		url := fmt.Sprintf("https://github.com/%s", s.Login)
-		if err := w.Write([]string{s.Name, s.Login, url, s.AvatarURL, s.Company, s.Location, strconv.Itoa(s.User.Followers), strconv.Itoa(sharedCount)}); err != nil {
-			return fmt.Errorf("failed to write to CSV: %s", err)
-		}
+		res.Stargazers = append(res.Stargazers, &FollowerStats{
+			Name:            s.Name,
+			Login:           s.Login,
+			URL:             fmt.Sprintf("https://github.com/%s", s.Login),
+			AvatarURL:       s.AvatarURL,
+			Company:         s.Company,
+			Location:        s.Location,
+			Followers:       s.User.Followers,
+			SharedFollowers: sharedCount,
+			Influence:       influence.ByLogin[s.Login],
+		})
 	}
-	w.Flush()
-	log.Printf("wrote followers analysis to %s", f.Name())
+	return res
+}
 
-	return nil
+// RunFollowers computes the size of follower networks, as well as
+// the count of shared followers.
+func RunFollowers(c *Context, sg []*fetch.Stargazer) error {
+	return WriteResult(c, Followers(sg, Influence(sg, c.Options)))
 }
 
-// RunCommitters lists stargazers by commits to subscribed repos, from
-// most prolific committer to least.
-func RunCommitters(c *fetch.Context, sg []*fetch.Stargazer, rs map[string]*fetch.Repo) error {
-	log.Printf("running committers analysis")
+// CommitterStats holds a stargazer's total commits, additions and
+// deletions to subscribed repos, along with the repos to which they
+// committed most and their commits to the analyzed repo and to the
+// most correlated repos.
+type CommitterStats struct {
+	Login     string `json:"login"`
+	Email     string `json:"email"`
+	Commits   int    `json:"commits"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	// Repos is the number of repos with commits; TopRepos lists those
+	// with the most commits.
+	Repos    int          `json:"repos"`
+	TopRepos []*NameCount `json:"top_repos"`
+	// RepoCommits are commits to the analyzed repo; CorrelatedCommits
+	// are commits to the most correlated starred and subscribed repos
+	// other than it, and OtherCommits those elsewhere.
+	RepoCommits       int `json:"repo_commits"`
+	CorrelatedCommits int `json:"correlated_commits"`
+	OtherCommits      int `json:"other_commits"`
+	// CorrelatedShare is the percent of commits outside the analyzed
+	// repo which are to the most correlated repos.
+	CorrelatedShare float64 `json:"correlated_share"`
+}
 
-	// Open file and prepare.
-	f, err := createFile(c, "committers.csv")
-	if err != nil {
-		return fmt.Errorf("failed to create file: %s", err)
+// CommittersResult is the result of the committers analysis.
+type CommittersResult struct {
+	Committers []*CommitterStats `json:"committers"`
+}
+
+func (r *CommittersResult) Name() string { return "committers" }
+
+func (r *CommittersResult) Header() []string {
+	return []string{"Login", "Email", "Commits", "Additions", "Deletions", "Repos", "Top Repos",
+		"Repo Commits", "Correlated Commits", "Other Commits", "Correlated Share %"}
+}
+
+func (r *CommittersResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Committers))
+	for _, cs := range r.Committers {
+		rows = append(rows, []string{cs.Login, cs.Email, strconv.Itoa(cs.Commits),
+			strconv.Itoa(cs.Additions), strconv.Itoa(cs.Deletions), strconv.Itoa(cs.Repos),
+			joinNameCounts(cs.TopRepos), strconv.Itoa(cs.RepoCommits), strconv.Itoa(cs.CorrelatedCommits),
+			strconv.Itoa(cs.OtherCommits),
+			fmt.Sprintf("%.1f", cs.CorrelatedShare)})
+	}
+	return rows
+}
+
+func (r *CommittersResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Committers))
+	for i, cs := range r.Committers {
+		recs[i] = cs
+	}
+	return recs
+}
+
+// CommitterMatrixRow holds the commits of each committer to a repo.
+// The analyzed repo is never marked correlated.
+type CommitterMatrixRow struct {
+	Repo       string `json:"repo"`
+	Correlated bool   `json:"correlated"`
+	Commits    int    `json:"commits"`
+	// ByCommitter maps the logins of committers to the repo to their
+	// commits.
+	ByCommitter map[string]int `json:"by_committer"`
+}
+
+// CommitterMatrixResult is the repo by committer matrix of commits,
+// with a row per repo and a column per committer.
+type CommitterMatrixResult struct {
+	Committers []string              `json:"committers"` // Column order
+	Repos      []*CommitterMatrixRow `json:"repos"`
+}
+
+func (r *CommitterMatrixResult) Name() string { return "committer_matrix" }
+
+func (r *CommitterMatrixResult) Header() []string {
+	return append([]string{"Repository", "Correlated", "Commits"}, r.Committers...)
+}
+
+func (r *CommitterMatrixResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Repos))
+	for _, mr := range r.Repos {
+		row := []string{mr.Repo, strconv.FormatBool(mr.Correlated), strconv.Itoa(mr.Commits)}
+		for _, login := range r.Committers {
+			row = append(row, strconv.Itoa(mr.ByCommitter[login]))
+		}
+		rows = append(rows, row)
 	}
-	defer f.Close()
-	w := csv.NewWriter(f)
-	if err := w.Write([]string{"Login", "Email", "Commits", "Additions", "Deletions"}); err != nil {
-		return fmt.Errorf("failed to write to CSV: %s", err)
+	return rows
+}
+
+func (r *CommitterMatrixResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Repos))
+	for i, mr := range r.Repos {
+		recs[i] = mr
+	}
+	return recs
+}
+
+// Committers lists stargazers by commits to subscribed repos, from
+// most prolific committer to least, with each committer's top repos,
+// their commits to the analyzed repo, and the share of their other
+// commits to the most correlated starred and subscribed repos. The
+// analyzed repo, which every stargazer starred, is counted apart
+// rather than as correlated. Also returns the matrix of commits by
+// repo and committer, with repos in order of descending commits and
+// committers in the order listed.
+func Committers(sg []*fetch.Stargazer, repo string, starred, subscribed *CorrelatedReposResult) (
+	*CommittersResult, *CommitterMatrixResult) {
+	log.Printf("running committers analysis")
+	correlated := map[string]struct{}{}
+	for _, res := range []*CorrelatedReposResult{starred, subscribed} {
+		for _, r := range res.Repos {
+			if r.Name != repo {
+				correlated[r.Name] = struct{}{}
+			}
+		}
 	}
 
 	// Sort the stargazers.
 	slice := Contributors(sg)
 	sort.Sort(slice)
 
-	// Now accumulate by days.
+	res := &CommittersResult{}
+	matrix := &CommitterMatrixResult{}
+	byRepo := map[string]*CommitterMatrixRow{}
 	for _, s := range slice {
 		c, a, d := s.TotalCommits()
 		if c == 0 {
 			break
 		}
-		if err := w.Write([]string{s.Login, s.Email, strconv.Itoa(c), strconv.Itoa(a), strconv.Itoa(d)}); err != nil {
-			return fmt.Errorf("failed to write to CSV: %s", err)
+		cs := &CommitterStats{
+			Login: s.Login, Email: s.Email, Commits: c, Additions: a, Deletions: d,
+		}
+		var repos RepoCounts
+		for rName, contrib := range s.Contributions {
+			if contrib.Commits == 0 {
+				continue
+			}
+			repos = append(repos, &RepoCount{name: rName, count: contrib.Commits})
+			_, isCorrelated := correlated[rName]
+			switch {
+			case rName == repo:
+				cs.RepoCommits += contrib.Commits
+			case isCorrelated:
+				cs.CorrelatedCommits += contrib.Commits
+			default:
+				cs.OtherCommits += contrib.Commits
+			}
+			mr, ok := byRepo[rName]
+			if !ok {
+				mr = &CommitterMatrixRow{Repo: rName, Correlated: isCorrelated, ByCommitter: map[string]int{}}
+				byRepo[rName] = mr
+				matrix.Repos = append(matrix.Repos, mr)
+			}
+			mr.Commits += contrib.Commits
+			mr.ByCommitter[s.Login] = contrib.Commits
+		}
+		sort.Sort(repos)
+		cs.Repos = len(repos)
+		for i, r := range repos {
+			if i >= nCommitterTopRepos {
+				break
+			}
+			cs.TopRepos = append(cs.TopRepos, &NameCount{Name: r.name, Count: r.count})
 		}
+		cs.CorrelatedShare = percent(cs.CorrelatedCommits, c-cs.RepoCommits)
+		res.Committers = append(res.Committers, cs)
+		matrix.Committers = append(matrix.Committers, s.Login)
 	}
-	w.Flush()
-	log.Printf("wrote committers analysis to %s", f.Name())
+	sort.Sort(byMatrixCommits(matrix.Repos))
+	return res, matrix
+}
 
-	return nil
+// RunCommitters lists stargazers by commits to subscribed repos, from
+// most prolific committer to least, and writes the matrix of commits
+// by repo and committer.
+func RunCommitters(c *Context, sg []*fetch.Stargazer, rs map[string]*fetch.Repo) error {
+	starred, _, err := CorrelatedRepos("starred", sg, rs, c.Options)
+	if err != nil {
+		return err
+	}
+	subscribed, _, err := CorrelatedRepos("subscribed", sg, rs, c.Options)
+	if err != nil {
+		return err
+	}
+	res, matrix := Committers(sg, c.Options.Repo, starred, subscribed)
+	if err := WriteResult(c, res); err != nil {
+		return err
+	}
+	return WriteResult(c, matrix)
 }
 
-// RunCumulativeStars creates a table of date and cumulative
-// star count for the provided stargazers.
-func RunAttributesByTime(c *fetch.Context, sg []*fetch.Stargazer, rs map[string]*fetch.Repo) error {
-	log.Printf("running stargazer attributes by time analysis")
+// byMatrixCommits sorts committer matrix rows by descending commits,
+// breaking ties by repo.
+type byMatrixCommits []*CommitterMatrixRow
 
-	// Open file and prepare.
-	f, err := createFile(c, "attributes_by_time.csv")
-	if err != nil {
-		return fmt.Errorf("failed to create file: %s", err)
+func (slice byMatrixCommits) Len() int {
+	return len(slice)
+}
+
+func (slice byMatrixCommits) Less(i, j int) bool {
+	if slice[i].Commits != slice[j].Commits {
+		return slice[i].Commits > slice[j].Commits /* descending order */
 	}
-	defer f.Close()
-	w := csv.NewWriter(f)
-	if err := w.Write([]string{"Date", "New Stars", "Avg Age", "Avg Followers", "Avg Commits"}); err != nil {
-		return fmt.Errorf("failed to write to CSV: %s", err)
+	return slice[i].Repo < slice[j].Repo
+}
+
+func (slice byMatrixCommits) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// AttributesSample holds the averaged attributes of stargazers who
+// starred the repo during a sample period. Averages are zero for
+// periods without stars.
+type AttributesSample struct {
+	Date         time.Time `json:"date"`
+	NewStars     int       `json:"new_stars"`
+	AvgAge       float64   `json:"avg_age"`
+	AvgFollowers float64   `json:"avg_followers"`
+	AvgCommits   float64   `json:"avg_commits"`
+}
+
+// AttributesByTimeResult is the result of the stargazer attributes by
+// time analysis.
+type AttributesByTimeResult struct {
+	Samples []*AttributesSample `json:"samples"`
+
+	resampler *Resampler
+}
+
+func (r *AttributesByTimeResult) Name() string { return "attributes_by_time" }
+
+func (r *AttributesByTimeResult) Header() []string {
+	return []string{"Date", "New Stars", "Avg Age", "Avg Followers", "Avg Commits"}
+}
+
+func (r *AttributesByTimeResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Samples))
+	for _, s := range r.Samples {
+		rows = append(rows, []string{r.resampler.formatDate(s.Date), strconv.Itoa(s.NewStars),
+			fmt.Sprintf("%.2f", s.AvgAge), fmt.Sprintf("%.2f", s.AvgFollowers), fmt.Sprintf("%.2f", s.AvgCommits)})
 	}
+	return rows
+}
 
-	output := func(day int64, count, age, followers, commits int) error {
-		t := time.Unix(day*60*60*24, 0)
-		avgAge := fmt.Sprintf("%.2f", float64(age)/float64(count))
-		avgFollowers := fmt.Sprintf("%.2f", float64(followers)/float64(count))
-		avgCommits := fmt.Sprintf("%.2f", float64(commits)/float64(count))
-		if err := w.Write([]string{t.Format("01/02/2006"), strconv.Itoa(count), avgAge, avgFollowers, avgCommits}); err != nil {
-			return fmt.Errorf("failed to write to CSV: %s", err)
-		}
-		return nil
+func (r *AttributesByTimeResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Samples))
+	for i, s := range r.Samples {
+		recs[i] = s
 	}
+	return recs
+}
+
+// AttributesByTime computes the average age (as of opts.AsOf),
+// followers and commits of stargazers, sampled in each period of
+// opts.Resampler by the time at which they starred. Under the legacy
+// preset, samples are of seven day periods anchored at the first
+// star.
+func AttributesByTime(sg []*fetch.Stargazer, opts Options) (*AttributesByTimeResult, error) {
+	log.Printf("running stargazer attributes by time analysis")
+	r, asOf := opts.Resampler, opts.asOf()
+	res := &AttributesByTimeResult{resampler: r}
 
 	const daySeconds = 60 * 60 * 24
 
+	output := func(date time.Time, count, age, followers, commits int) {
+		sample := &AttributesSample{Date: date, NewStars: count}
+		if count > 0 {
+			sample.AvgAge = float64(age) / float64(count)
+			sample.AvgFollowers = float64(followers) / float64(count)
+			sample.AvgCommits = float64(commits) / float64(count)
+		}
+		res.Samples = append(res.Samples, sample)
+	}
+
 	// Sort the stargazers.
 	slice := Stargazers(sg)
 	sort.Sort(slice)
 
+	times := make([]time.Time, len(slice))
+	for i, s := range slice {
+		t, err := time.Parse(time.RFC3339, s.StarredAt)
+		if err != nil {
+			return nil, err
+		}
+		times[i] = t
+	}
+	if !r.Legacy() {
+		periods, index := r.Resample(times)
+		counts := make([][4]int, len(periods))
+		for i, s := range slice {
+			c, _, _ := s.TotalCommits()
+			p := &counts[index[i]]
+			p[0]++
+			p[1] += int(s.Age(asOf) / daySeconds)
+			p[2] += len(s.Followers)
+			p[3] += c
+		}
+		for i, p := range periods {
+			output(p, counts[i][0], counts[i][1], counts[i][2], counts[i][3])
+		}
+		return res, nil
+	}
+
 	// Accumulation factor means the count of days over which to average each sample.
 	factor := int64(7) // weekly
 
@@ -365,48 +1038,50 @@ func RunAttributesByTime(c *fetch.Context, sg []*fetch.Stargazer, rs map[string]
 	firstDay := int64(0)
 	lastDay := int64(0)
 	count, age, followers, commits := 0, 0, 0, 0
-	for _, s := range slice {
-		t, err := time.Parse(time.RFC3339, s.StarredAt)
-		if err != nil {
-			return err
-		}
-		day := t.Unix() / daySeconds
+	for i, s := range slice {
+		day := times[i].Unix() / daySeconds
 		if firstDay == 0 {
 			firstDay = day
 		}
 		if day != lastDay && (day-firstDay)%factor == 0 {
 			if count > 0 {
-				if err := output(lastDay, count, age, followers, commits); err != nil {
-					return err
-				}
+				output(time.Unix(lastDay*daySeconds, 0), count, age, followers, commits)
 			}
 			lastDay = day
 			count = 1
-			age = int(s.Age() / daySeconds)
+			age = int(s.Age(asOf) / daySeconds)
 			followers = len(s.Followers)
 			commits, _, _ = s.TotalCommits()
 		} else {
 			count++
-			age += int(s.Age() / daySeconds)
+			age += int(s.Age(asOf) / daySeconds)
 			followers += len(s.Followers)
 			c, _, _ := s.TotalCommits()
 			commits += c
 		}
 	}
 	if count > 0 {
-		if err := output(lastDay, count, age, followers, commits); err != nil {
-			return err
-		}
+		output(time.Unix(lastDay*daySeconds, 0), count, age, followers, commits)
 	}
-	w.Flush()
-	log.Printf("wrote stargazer attributes by time analysis to %s", f.Name())
+	return res, nil
+}
 
-	return nil
+// RunAttributesByTime creates a table of the average attributes of
+// stargazers, sampled by the time at which they starred.
+func RunAttributesByTime(c *Context, sg []*fetch.Stargazer) error {
+	res, err := AttributesByTime(sg, c.Options)
+	if err != nil {
+		return err
+	}
+	return WriteResult(c, res)
 }
 
-func createFile(c *fetch.Context, baseName string) (*os.File, error) {
-	filename := filepath.Join(c.CacheDir, c.Repo, baseName)
-	f, err := os.Create(filename)
+func createFile(c *Context, baseName string) (*os.File, error) {
+	dir, err := MakeOutputDir(c.Context, c.Segment)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Create(filepath.Join(dir, baseName))
 	if err != nil {
 		return nil, err
 	}
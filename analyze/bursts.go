@@ -0,0 +1,362 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+const (
+	// burstWindow is the number of trailing days averaged to form the
+	// baseline daily star rate.
+	burstWindow = 28
+	// burstMinHistory is the number of days of history required
+	// before a day may be flagged as a burst.
+	burstMinHistory = 7
+	// burstZ is the standard score at which a day is flagged as the
+	// peak of a burst; burstExtendZ is the score at which neighboring
+	// days are included in the burst.
+	burstZ       = 4.0
+	burstExtendZ = 2.0
+	// burstMinExcess is the minimum excess stars on a peak day.
+	burstMinExcess = 5.0
+	// cusumSlack and cusumThreshold are the allowance (in standard
+	// deviations per day) and decision threshold of the CUSUM change
+	// point detector.
+	cusumSlack     = 0.5
+	cusumThreshold = 8.0
+)
+
+// StarProfile summarizes the stargazers who starred during a period.
+type StarProfile struct {
+	Stargazers         int     `json:"stargazers"`
+	MedianAgeAtStar    float64 `json:"median_age_at_star"` // Days
+	MedianFollowers    float64 `json:"median_followers"`
+	CommittersPct      float64 `json:"committers_pct"`
+	EmptyProfilePct    float64 `json:"empty_profile_pct"`
+	MedianPublicRepos  float64 `json:"median_public_repos"`
+	MedianStarredRepos float64 `json:"median_starred_repos"`
+}
+
+// StarEpisode is a period of anomalous starring activity. The
+// baseline episode (ID zero) covers all days outside of bursts.
+type StarEpisode struct {
+	ID       int         `json:"id"`
+	Start    time.Time   `json:"start"`
+	End      time.Time   `json:"end"`
+	Days     int         `json:"days"`
+	Stars    int         `json:"stars"`
+	Expected float64     `json:"expected"`
+	Excess   float64     `json:"excess"`
+	PeakZ    float64     `json:"peak_z"`
+	Profile  StarProfile `json:"profile"`
+}
+
+// StarBurstsResult is the result of the star bursts analysis.
+type StarBurstsResult struct {
+	Baseline *StarEpisode   `json:"baseline"`
+	Bursts   []*StarEpisode `json:"bursts"`
+}
+
+func (r *StarBurstsResult) Name() string { return "star_bursts" }
+
+func (r *StarBurstsResult) Header() []string {
+	return []string{"Episode", "Start", "End", "Days", "Stars", "Expected", "Excess", "Peak Z",
+		"Stargazers", "Median Age At Star", "Median Followers", "Committers %", "Empty Profile %",
+		"Median Public Repos", "Median Starred Repos"}
+}
+
+func (r *StarBurstsResult) episodes() []*StarEpisode {
+	return append([]*StarEpisode{r.Baseline}, r.Bursts...)
+}
+
+func (r *StarBurstsResult) Rows() [][]string {
+	var rows [][]string
+	for _, e := range r.episodes() {
+		id := strconv.Itoa(e.ID)
+		if e.ID == 0 {
+			id = "baseline"
+		}
+		p := e.Profile
+		rows = append(rows, []string{id, e.Start.Format("2006-01-02"), e.End.Format("2006-01-02"),
+			strconv.Itoa(e.Days), strconv.Itoa(e.Stars), fmt.Sprintf("%.1f", e.Expected),
+			fmt.Sprintf("%.1f", e.Excess), fmt.Sprintf("%.2f", e.PeakZ),
+			strconv.Itoa(p.Stargazers), fmt.Sprintf("%.0f", p.MedianAgeAtStar), fmt.Sprintf("%.0f", p.MedianFollowers),
+			fmt.Sprintf("%.1f", p.CommittersPct), fmt.Sprintf("%.1f", p.EmptyProfilePct),
+			fmt.Sprintf("%.0f", p.MedianPublicRepos), fmt.Sprintf("%.0f", p.MedianStarredRepos)})
+	}
+	return rows
+}
+
+func (r *StarBurstsResult) Records() []interface{} {
+	var recs []interface{}
+	for _, e := range r.episodes() {
+		recs = append(recs, e)
+	}
+	return recs
+}
+
+// ChangePoint is a day at which the baseline daily star rate shifted.
+type ChangePoint struct {
+	Date      time.Time `json:"date"`
+	Direction string    `json:"direction"` // "increase" or "decrease"
+	// RateBefore and RateAfter are the mean daily stars over the
+	// burstWindow days before and after the change point.
+	RateBefore float64 `json:"rate_before"`
+	RateAfter  float64 `json:"rate_after"`
+}
+
+// ChangePointsResult is the result of the star change point analysis.
+type ChangePointsResult struct {
+	ChangePoints []*ChangePoint `json:"change_points"`
+}
+
+func (r *ChangePointsResult) Name() string { return "star_change_points" }
+
+func (r *ChangePointsResult) Header() []string {
+	return []string{"Date", "Direction", "Rate Before", "Rate After"}
+}
+
+func (r *ChangePointsResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.ChangePoints))
+	for _, cp := range r.ChangePoints {
+		rows = append(rows, []string{cp.Date.Format("2006-01-02"), cp.Direction,
+			fmt.Sprintf("%.2f", cp.RateBefore), fmt.Sprintf("%.2f", cp.RateAfter)})
+	}
+	return rows
+}
+
+func (r *ChangePointsResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.ChangePoints))
+	for i, cp := range r.ChangePoints {
+		recs[i] = cp
+	}
+	return recs
+}
+
+// dailyStars returns the count of stars on each calendar day in loc
+// from the day of the first star through the day of the last, along
+// with the stargazers on each day and the number of the first day
+// (see dayNumber).
+func dailyStars(sg []*fetch.Stargazer, loc *time.Location) ([]float64, [][]*fetch.Stargazer, int64, error) {
+	if len(sg) == 0 {
+		return nil, nil, 0, nil
+	}
+	days := make([]int64, len(sg))
+	first, last := int64(math.MaxInt64), int64(math.MinInt64)
+	for i, s := range sg {
+		t, err := time.Parse(time.RFC3339, s.StarredAt)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		days[i] = dayNumber(t, loc)
+		if days[i] < first {
+			first = days[i]
+		}
+		if days[i] > last {
+			last = days[i]
+		}
+	}
+	counts := make([]float64, last-first+1)
+	byDay := make([][]*fetch.Stargazer, last-first+1)
+	for i, s := range sg {
+		counts[days[i]-first]++
+		byDay[days[i]-first] = append(byDay[days[i]-first], s)
+	}
+	return counts, byDay, first, nil
+}
+
+// StarBursts detects bursts and change points in the daily star
+// series. Each day's expected stars are the mean of the trailing
+// burstWindow days, adjusted by a day-of-week seasonal factor; days
+// scoring as a burst peak contribute their expected rather than actual
+// stars to later baselines, so a spike doesn't mask the next one while
+// a sustained shift is still absorbed. Standard scores assume Poisson
+// variance. A burst is a run of days scoring at least burstExtendZ,
+// including a peak of at least burstZ. Change points are detected by
+// a two-sided CUSUM over the standard scores of non-burst days.
+//
+// Days are calendar days in the time zone of r (see
+// Resampler.DayLocation). Each burst is profiled by the stargazers
+// who starred during it, for comparison with the baseline of all
+// other stargazers.
+func StarBursts(sg []*fetch.Stargazer, r *Resampler) (*StarBurstsResult, *ChangePointsResult, error) {
+	log.Printf("running star bursts analysis")
+	loc := r.DayLocation()
+	counts, byDay, first, err := dailyStars(sg, loc)
+	if err != nil {
+		return nil, nil, err
+	}
+	dayTime := func(i int) time.Time { return dayStart(first+int64(i), loc) }
+
+	// Day-of-week seasonal factors.
+	var weekday [7]float64
+	var weekdayDays [7]int
+	total := 0.0
+	for i, c := range counts {
+		wd := dayTime(i).Weekday()
+		weekday[wd] += c
+		weekdayDays[wd]++
+		total += c
+	}
+	var factor [7]float64
+	for wd := range factor {
+		factor[wd] = 1
+		if weekdayDays[wd] > 0 && total > 0 {
+			factor[wd] = (weekday[wd] / float64(weekdayDays[wd])) / (total / float64(len(counts)))
+		}
+	}
+
+	// Compute expected stars and standard scores in a single forward
+	// pass, replacing burst peaks with their expectation.
+	expected := make([]float64, len(counts))
+	z := make([]float64, len(counts))
+	cleaned := make([]float64, len(counts))
+	for i, c := range counts {
+		lo := i - burstWindow
+		if lo < 0 {
+			lo = 0
+		}
+		cleaned[i] = c
+		if i-lo < burstMinHistory {
+			continue
+		}
+		expected[i] = mean(cleaned[lo:i]) * factor[dayTime(i).Weekday()]
+		z[i] = (c - expected[i]) / math.Sqrt(math.Max(expected[i], 1))
+		if z[i] >= burstZ && c-expected[i] >= burstMinExcess {
+			cleaned[i] = expected[i]
+		}
+	}
+
+	// Identify bursts as runs of elevated days containing a peak.
+	bursts := &StarBurstsResult{}
+	inBurst := make([]bool, len(counts))
+	for i := 0; i < len(counts); {
+		if z[i] < burstExtendZ {
+			i++
+			continue
+		}
+		j := i
+		e := &StarEpisode{}
+		for ; j < len(counts) && z[j] >= burstExtendZ; j++ {
+			e.Stars += int(counts[j])
+			e.Expected += expected[j]
+			if z[j] > e.PeakZ && counts[j]-expected[j] >= burstMinExcess {
+				e.PeakZ = z[j]
+			}
+		}
+		if e.PeakZ >= burstZ {
+			e.ID = len(bursts.Bursts) + 1
+			e.Start, e.End, e.Days = dayTime(i), dayTime(j-1), j-i
+			e.Excess = float64(e.Stars) - e.Expected
+			var members []*fetch.Stargazer
+			for k := i; k < j; k++ {
+				inBurst[k] = true
+				members = append(members, byDay[k]...)
+			}
+			e.Profile = starProfile(members)
+			bursts.Bursts = append(bursts.Bursts, e)
+		}
+		i = j
+	}
+	baseline := &StarEpisode{}
+	var members []*fetch.Stargazer
+	for i, c := range counts {
+		if !inBurst[i] {
+			baseline.Days++
+			baseline.Stars += int(c)
+			members = append(members, byDay[i]...)
+		}
+	}
+	if len(counts) > 0 {
+		baseline.Start, baseline.End = dayTime(0), dayTime(len(counts)-1)
+		baseline.Expected = float64(baseline.Stars)
+	}
+	baseline.Profile = starProfile(members)
+	bursts.Baseline = baseline
+
+	// Detect sustained shifts with a two-sided CUSUM, ignoring bursts.
+	changes := &ChangePointsResult{}
+	hi, lo := 0.0, 0.0
+	for i := range counts {
+		if inBurst[i] || i < burstMinHistory {
+			continue
+		}
+		hi = math.Max(0, hi+z[i]-cusumSlack)
+		lo = math.Max(0, lo-z[i]-cusumSlack)
+		if hi < cusumThreshold && lo < cusumThreshold {
+			continue
+		}
+		cp := &ChangePoint{Date: dayTime(i), Direction: "increase"}
+		if lo >= cusumThreshold {
+			cp.Direction = "decrease"
+		}
+		cp.RateBefore = mean(counts[maxInt(0, i-burstWindow):i])
+		cp.RateAfter = mean(counts[i:minInt(len(counts), i+burstWindow)])
+		changes.ChangePoints = append(changes.ChangePoints, cp)
+		hi, lo = 0, 0
+	}
+	return bursts, changes, nil
+}
+
+// starProfile summarizes the specified stargazers.
+func starProfile(sg []*fetch.Stargazer) StarProfile {
+	p := StarProfile{Stargazers: len(sg)}
+	var ages, followers, repos, starred []float64
+	committers, empty := 0, 0
+	for _, s := range sg {
+		if age := ageAtStar(s); !math.IsNaN(age) {
+			ages = append(ages, age)
+		}
+		followers = append(followers, float64(s.User.Followers))
+		repos = append(repos, float64(s.PublicRepos))
+		starred = append(starred, float64(len(s.Starred)))
+		if c, _, _ := s.TotalCommits(); c > 0 {
+			committers++
+		}
+		if emptyProfile(s) {
+			empty++
+		}
+	}
+	p.MedianAgeAtStar = median(ages)
+	p.MedianFollowers = median(followers)
+	p.MedianPublicRepos = median(repos)
+	p.MedianStarredRepos = median(starred)
+	p.CommittersPct = percent(committers, len(sg))
+	p.EmptyProfilePct = percent(empty, len(sg))
+	return p
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
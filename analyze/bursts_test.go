@@ -0,0 +1,158 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+// dailyStargazers returns stargazers starring at noon UTC on
+// consecutive days starting 2016-01-04, counts[i] on day i.
+func dailyStargazers(counts []int) []*fetch.Stargazer {
+	start := time.Date(2016, 1, 4, 12, 0, 0, 0, time.UTC)
+	var sg []*fetch.Stargazer
+	for d, c := range counts {
+		for j := 0; j < c; j++ {
+			s := &fetch.Stargazer{StarredAt: start.AddDate(0, 0, d).Format(time.RFC3339)}
+			s.Login = fmt.Sprintf("user-%d-%d", d, j)
+			sg = append(sg, s)
+		}
+	}
+	return sg
+}
+
+// series returns n days of count stars, overridden by the specified
+// day -> count entries.
+func series(n, count int, days map[int]int) []int {
+	counts := make([]int, n)
+	for i := range counts {
+		counts[i] = count
+		if c, ok := days[i]; ok {
+			counts[i] = c
+		}
+	}
+	return counts
+}
+
+// step returns n days of before stars, switching to after stars on
+// day at.
+func step(n, at, before, after int) []int {
+	counts := series(n, before, nil)
+	for i := at; i < n; i++ {
+		counts[i] = after
+	}
+	return counts
+}
+
+func TestStarBursts(t *testing.T) {
+	day := func(i int) time.Time { return time.Date(2016, 1, 4+i, 0, 0, 0, 0, time.UTC) }
+	testCases := []struct {
+		name     string
+		counts   []int
+		bursts   []StarEpisode
+		baseline int
+		changes  []ChangePoint
+	}{
+		// Spike of 20 on day 40 of a steady 2/day. The day-of-week
+		// factor for day 40 is (34/8)/(138/60) = 1.847826, so the
+		// expectation is 3.695652 and z = 16.304348/sqrt(3.695652).
+		{"spike", series(60, 2, map[int]int{40: 20}),
+			[]StarEpisode{{ID: 1, Start: day(40), End: day(40), Days: 1, Stars: 20,
+				Expected: 3.695652, Excess: 16.304348, PeakZ: 8.481211}}, 118, nil},
+		// z = 4 against an expectation of zero, but an excess of only
+		// four stars is below burstMinExcess.
+		{"small spike", series(60, 0, map[int]int{0: 1, 40: 4}), nil, 5, nil},
+		// Spike within the first burstMinHistory days has no baseline.
+		{"no history", series(60, 2, map[int]int{3: 20}), nil, 138, nil},
+		// Step from 2/day to 5/day on day 42 scores z = 2.12 on the
+		// first day, below any burst peak. The trailing mean absorbs
+		// the shift while CUSUM accumulates a single increase on day
+		// 48, when the prior 28 days average (22*2+6*5)/28 stars.
+		{"increase", step(84, 42, 2, 5),
+			nil, 294, []ChangePoint{{Date: day(48), Direction: "increase", RateBefore: 2.642857, RateAfter: 5}}},
+	}
+	approx := func(a, b float64) bool { return math.Abs(a-b) < 1e-5 }
+	for _, tc := range testCases {
+		bursts, changes, err := StarBursts(dailyStargazers(tc.counts), nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.name, err)
+		}
+		if len(bursts.Bursts) != len(tc.bursts) {
+			t.Errorf("%s: expected %d bursts; got %d", tc.name, len(tc.bursts), len(bursts.Bursts))
+			continue
+		}
+		for i, e := range bursts.Bursts {
+			exp := tc.bursts[i]
+			if e.ID != exp.ID || !e.Start.Equal(exp.Start) || !e.End.Equal(exp.End) || e.Days != exp.Days ||
+				e.Stars != exp.Stars || !approx(e.Expected, exp.Expected) || !approx(e.Excess, exp.Excess) ||
+				!approx(e.PeakZ, exp.PeakZ) {
+				t.Errorf("%s: expected burst %+v; got %+v", tc.name, exp, *e)
+			}
+			if e.Profile.Stargazers != e.Stars {
+				t.Errorf("%s: expected %d profiled stargazers; got %d", tc.name, e.Stars, e.Profile.Stargazers)
+			}
+		}
+		if bursts.Baseline.Stars != tc.baseline {
+			t.Errorf("%s: expected baseline of %d stars; got %d", tc.name, tc.baseline, bursts.Baseline.Stars)
+		}
+		if len(changes.ChangePoints) != len(tc.changes) {
+			t.Errorf("%s: expected %d change points; got %d", tc.name, len(tc.changes), len(changes.ChangePoints))
+			continue
+		}
+		for i, cp := range changes.ChangePoints {
+			exp := tc.changes[i]
+			if !cp.Date.Equal(exp.Date) || cp.Direction != exp.Direction ||
+				!approx(cp.RateBefore, exp.RateBefore) || !approx(cp.RateAfter, exp.RateAfter) {
+				t.Errorf("%s: expected change point %+v; got %+v", tc.name, exp, *cp)
+			}
+		}
+	}
+}
+
+func TestStarBurstsTimezone(t *testing.T) {
+	ny, err := NewResampler(GranularityWeekly, "America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %s", err)
+	}
+	// Twenty stars at 03:00 UTC on day 40 fall on day 39 in New York.
+	sg := dailyStargazers(series(60, 2, nil))
+	for i := 0; i < 20; i++ {
+		s := &fetch.Stargazer{StarredAt: "2016-02-13T03:00:00Z"}
+		s.Login = fmt.Sprintf("burst-%d", i)
+		sg = append(sg, s)
+	}
+	for _, tc := range []struct {
+		r     *Resampler
+		start time.Time
+	}{
+		{nil, time.Date(2016, 2, 13, 0, 0, 0, 0, time.UTC)},
+		{ny, time.Date(2016, 2, 12, 0, 0, 0, 0, ny.Location)},
+	} {
+		bursts, _, err := StarBursts(sg, tc.r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bursts.Bursts) != 1 || !bursts.Bursts[0].Start.Equal(tc.start) {
+			t.Errorf("expected one burst starting %s; got %+v", tc.start, bursts.Bursts)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+// Stargazers whose profile was updated within recentDays and
+// activeDays of the reference time are counted as recently active.
+const (
+	recentDays = 30
+	activeDays = 90
+)
+
+// Cohort summarizes the stargazers who first starred the repo in a
+// month, and their later behavior.
+type Cohort struct {
+	Month      string  `json:"month"`      // Period; see Resampler.Period
+	Stargazers int     `json:"stargazers"` // Ever starred, across snapshots
+	Current    int     `json:"current"`    // Still starred
+	Retention  float64 `json:"retention"`  // Percent still starred
+	// WatchingPct and ContributorsPct are the percentages of current
+	// stargazers who watch, or have committed to, the repo.
+	WatchingPct     float64 `json:"watching_pct"`
+	ContributorsPct float64 `json:"contributors_pct"`
+	// Active30Pct and Active90Pct are the percentages of current
+	// stargazers whose profiles were updated within recentDays and
+	// activeDays of the reference time.
+	Active30Pct           float64     `json:"active_30_pct"`
+	Active90Pct           float64     `json:"active_90_pct"`
+	MedianDaysSinceActive float64     `json:"median_days_since_active"`
+	Profile               StarProfile `json:"profile"` // Of current stargazers
+}
+
+// CohortsResult is the result of the cohort analysis.
+type CohortsResult struct {
+	// Reference is the time to which activity recency is measured.
+	Reference time.Time `json:"reference"`
+	Cohorts   []*Cohort `json:"cohorts"`
+}
+
+func (r *CohortsResult) Name() string { return "star_cohorts" }
+
+func (r *CohortsResult) Header() []string {
+	return []string{"Cohort", "Stargazers", "Current", "Retention %", "Watching %", "Contributors %",
+		"Active 30d %", "Active 90d %", "Median Days Since Active", "Median Age At Star", "Median Followers",
+		"Committers %", "Empty Profile %", "Median Public Repos", "Median Starred Repos"}
+}
+
+func (r *CohortsResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Cohorts))
+	for _, c := range r.Cohorts {
+		p := c.Profile
+		rows = append(rows, []string{c.Month, strconv.Itoa(c.Stargazers), strconv.Itoa(c.Current),
+			fmt.Sprintf("%.1f", c.Retention), fmt.Sprintf("%.1f", c.WatchingPct), fmt.Sprintf("%.1f", c.ContributorsPct),
+			fmt.Sprintf("%.1f", c.Active30Pct), fmt.Sprintf("%.1f", c.Active90Pct), fmt.Sprintf("%.0f", c.MedianDaysSinceActive),
+			fmt.Sprintf("%.0f", p.MedianAgeAtStar), fmt.Sprintf("%.0f", p.MedianFollowers),
+			fmt.Sprintf("%.1f", p.CommittersPct), fmt.Sprintf("%.1f", p.EmptyProfilePct),
+			fmt.Sprintf("%.0f", p.MedianPublicRepos), fmt.Sprintf("%.0f", p.MedianStarredRepos)})
+	}
+	return rows
+}
+
+func (r *CohortsResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Cohorts))
+	for i, c := range r.Cohorts {
+		recs[i] = c
+	}
+	return recs
+}
+
+// CohortRetention is the share of a cohort which starred the repo as
+// of each snapshot.
+type CohortRetention struct {
+	Month      string `json:"month"`
+	Stargazers int    `json:"stargazers"`
+	// Retention is indexed by snapshot; NaN (null in JSON) for
+	// snapshots which precede the cohort.
+	Retention []jsonFloat `json:"retention"`
+}
+
+// CohortRetentionResult is the cohort retention table, with a column
+// per snapshot.
+type CohortRetentionResult struct {
+	Snapshots []time.Time        `json:"snapshots"`
+	Cohorts   []*CohortRetention `json:"cohorts"`
+}
+
+func (r *CohortRetentionResult) Name() string { return "star_cohort_retention" }
+
+func (r *CohortRetentionResult) Header() []string {
+	header := []string{"Cohort", "Stargazers"}
+	for _, t := range r.Snapshots {
+		header = append(header, t.Format("2006-01-02 15:04"))
+	}
+	return header
+}
+
+func (r *CohortRetentionResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Cohorts))
+	for _, c := range r.Cohorts {
+		row := []string{c.Month, strconv.Itoa(c.Stargazers)}
+		for _, v := range c.Retention {
+			if math.IsNaN(float64(v)) {
+				row = append(row, "")
+			} else {
+				row = append(row, fmt.Sprintf("%.1f", float64(v)))
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (r *CohortRetentionResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Cohorts))
+	for i, c := range r.Cohorts {
+		recs[i] = c
+	}
+	return recs
+}
+
+// jsonFloat is a float64 which encodes NaN as null.
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(f)) {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.FormatFloat(float64(f), 'f', -1, 64)), nil
+}
+
+// Cohorts groups stargazers by the period of opts.Resampler (by
+// default, the UTC month) in which they first starred the repo and
+// tracks each cohort's later behavior: the share still starring the
+// repo as of each snapshot in opts.Snapshots (or, without snapshots,
+// as of the reference time), the share watching or contributing to
+// the repo, how recently their profiles were updated, and the
+// cohort's profile (see StarProfile). Stargazers who removed their
+// star are known only from snapshots, so all but retention describe
+// current stargazers. Recency is measured to opts.AsOf if set, else
+// to the time of the latest snapshot, or to now.
+func Cohorts(sg []*fetch.Stargazer, opts Options) (*CohortsResult, *CohortRetentionResult, error) {
+	log.Printf("running cohorts analysis")
+	snaps := opts.Snapshots
+	ref := opts.asOf()
+	if len(snaps) > 0 {
+		if opts.AsOf.IsZero() {
+			ref = snaps[len(snaps)-1].FetchedAt
+		}
+	} else {
+		snap := &fetch.Snapshot{FetchedAt: ref}
+		for _, s := range sg {
+			snap.Stargazers = append(snap.Stargazers, &fetch.SnapshotEntry{Login: s.Login, StarredAt: s.StarredAt})
+		}
+		snaps = []*fetch.Snapshot{snap}
+	}
+
+	// Assign every stargazer ever seen to the month of their first star.
+	first := map[string]time.Time{}
+	record := func(login, starredAt string) error {
+		t, err := time.Parse(time.RFC3339, starredAt)
+		if err != nil {
+			return err
+		}
+		if f, ok := first[login]; !ok || t.Before(f) {
+			first[login] = t
+		}
+		return nil
+	}
+	for _, snap := range snaps {
+		for _, e := range snap.Stargazers {
+			if err := record(e.Login, e.StarredAt); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	for _, s := range sg {
+		if err := record(s.Login, s.StarredAt); err != nil {
+			return nil, nil, err
+		}
+	}
+	members := map[string][]string{}
+	var months []string
+	for login, t := range first {
+		month := opts.Resampler.Period(t)
+		if _, ok := members[month]; !ok {
+			months = append(months, month)
+		}
+		members[month] = append(members[month], login)
+	}
+	sort.Strings(months)
+	current := map[string][]*fetch.Stargazer{}
+	for _, s := range sg {
+		month := opts.Resampler.Period(first[s.Login])
+		current[month] = append(current[month], s)
+	}
+
+	res := &CohortsResult{Reference: ref}
+	retention := &CohortRetentionResult{}
+	starred := make([]map[string]bool, len(snaps))
+	for i, snap := range snaps {
+		retention.Snapshots = append(retention.Snapshots, snap.FetchedAt)
+		starred[i] = map[string]bool{}
+		for _, e := range snap.Stargazers {
+			starred[i][e.Login] = true
+		}
+	}
+	for _, month := range months {
+		c := &Cohort{Month: month, Stargazers: len(members[month]), Current: len(current[month])}
+		c.Retention = percent(c.Current, c.Stargazers)
+		watching, contributors, recent, active := 0, 0, 0, 0
+		var sinceActive []float64
+		for _, s := range current[month] {
+			for _, name := range s.Subscribed {
+				if name == opts.Repo {
+					watching++
+					break
+				}
+			}
+			if contrib, ok := s.Contributions[opts.Repo]; ok && contrib.Commits > 0 {
+				contributors++
+			}
+			if t, err := time.Parse(time.RFC3339, s.UpdatedAt); err == nil {
+				days := ref.Sub(t).Hours() / 24
+				sinceActive = append(sinceActive, days)
+				if days <= recentDays {
+					recent++
+				}
+				if days <= activeDays {
+					active++
+				}
+			}
+		}
+		c.WatchingPct = percent(watching, c.Current)
+		c.ContributorsPct = percent(contributors, c.Current)
+		c.Active30Pct = percent(recent, c.Current)
+		c.Active90Pct = percent(active, c.Current)
+		c.MedianDaysSinceActive = median(sinceActive)
+		c.Profile = starProfile(current[month])
+		res.Cohorts = append(res.Cohorts, c)
+
+		cr := &CohortRetention{Month: month, Stargazers: c.Stargazers}
+		for i, snap := range snaps {
+			joined, still := 0, 0
+			for _, login := range members[month] {
+				if !first[login].After(snap.FetchedAt) {
+					joined++
+					if starred[i][login] {
+						still++
+					}
+				}
+			}
+			if joined == 0 {
+				cr.Retention = append(cr.Retention, jsonFloat(math.NaN()))
+			} else {
+				cr.Retention = append(cr.Retention, jsonFloat(percent(still, joined)))
+			}
+		}
+		retention.Cohorts = append(retention.Cohorts, cr)
+	}
+	return res, retention, nil
+}
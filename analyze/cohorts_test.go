@@ -0,0 +1,102 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+	"github.com/spencerkimball/stargazers/segment"
+)
+
+func TestCohortsSegment(t *testing.T) {
+	makeUser := func(login, company, starredAt string) *fetch.Stargazer {
+		s := &fetch.Stargazer{StarredAt: starredAt}
+		s.Login, s.Company = login, company
+		return s
+	}
+	sg := []*fetch.Stargazer{
+		makeUser("a", "Acme", "2016-01-05T00:00:00Z"),
+		makeUser("b", "Acme", "2016-01-10T00:00:00Z"),
+		makeUser("c", "Other", "2016-01-12T00:00:00Z"),
+		makeUser("e", "Acme", "2016-02-03T00:00:00Z"),
+	}
+	// "d" starred in January and removed their star by March.
+	snapshots := func() []*fetch.Snapshot {
+		entry := func(login, starredAt string) *fetch.SnapshotEntry {
+			return &fetch.SnapshotEntry{Login: login, StarredAt: starredAt}
+		}
+		return []*fetch.Snapshot{
+			{FetchedAt: time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC), Stargazers: []*fetch.SnapshotEntry{
+				entry("a", "2016-01-05T00:00:00Z"), entry("b", "2016-01-10T00:00:00Z"),
+				entry("c", "2016-01-12T00:00:00Z"), entry("d", "2016-01-20T00:00:00Z")}},
+			{FetchedAt: time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC), Stargazers: []*fetch.SnapshotEntry{
+				entry("a", "2016-01-05T00:00:00Z"), entry("b", "2016-01-10T00:00:00Z"),
+				entry("c", "2016-01-12T00:00:00Z"), entry("e", "2016-02-03T00:00:00Z")}},
+		}
+	}
+	nan := math.NaN()
+	testCases := []struct {
+		where     string
+		cohorts   string // month:stargazers/current
+		retention [][]float64
+	}{
+		{"", "2016-01:4/3 2016-02:1/1", [][]float64{{100, 75}, {nan, 100}}},
+		// The segment's January cohort excludes "c", and "d", whose
+		// company is unknown, so retention is that of "a" and "b".
+		{`company ~ "acme"`, "2016-01:2/2 2016-02:1/1", [][]float64{{100, 100}, {nan, 100}}},
+	}
+	for _, tc := range testCases {
+		opts := Options{Repo: "owner/repo", Snapshots: snapshots()}
+		matched := sg
+		if len(tc.where) > 0 {
+			expr, err := segment.Parse(tc.where, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			matched = segment.Filter(sg, expr, nil)
+			logins := map[string]bool{}
+			for _, s := range matched {
+				logins[strings.ToLower(s.Login)] = true
+			}
+			fetch.RestrictSnapshots(opts.Snapshots, logins)
+		}
+		res, retention, err := Cohorts(matched, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cohorts []string
+		for _, c := range res.Cohorts {
+			cohorts = append(cohorts, fmt.Sprintf("%s:%d/%d", c.Month, c.Stargazers, c.Current))
+		}
+		if s := strings.Join(cohorts, " "); s != tc.cohorts {
+			t.Errorf("%q: expected cohorts %s; got %s", tc.where, tc.cohorts, s)
+		}
+		for i, cr := range retention.Cohorts {
+			for j, r := range cr.Retention {
+				exp := tc.retention[i][j]
+				if got := float64(r); got != exp && !(math.IsNaN(got) && math.IsNaN(exp)) {
+					t.Errorf("%q: expected %s retention %v at snapshot %d; got %v", tc.where, cr.Month, exp, j, got)
+				}
+			}
+		}
+	}
+}
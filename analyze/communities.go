@@ -0,0 +1,312 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+const (
+	// minCommunitySize is the minimum number of stargazers in a
+	// community for it to be included in the communities report.
+	minCommunitySize = 3
+	// nCommunityMembers is the number of members listed per community.
+	nCommunityMembers = 10
+	// nCommunityTop is the number of companies, locations and
+	// distinctive repos listed per community.
+	nCommunityTop = 5
+)
+
+// Community describes a cluster of stargazers in the follower graph.
+type Community struct {
+	ID int `json:"id"`
+	// Stargazers is the number of stargazers in the community; Nodes
+	// also includes followers who aren't stargazers.
+	Stargazers int `json:"stargazers"`
+	Nodes      int `json:"nodes"`
+	// Members lists the community's stargazers with the most followers.
+	Members            []string     `json:"members"`
+	Companies          []*NameCount `json:"companies"`
+	Locations          []*NameCount `json:"locations"`
+	DistinctiveStarred []*NameCount `json:"distinctive_starred"`
+}
+
+// NameCount is a name along with its number of occurrences.
+type NameCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// CommunitiesResult is the result of the communities analysis.
+type CommunitiesResult struct {
+	Communities []*Community `json:"communities"`
+	// Membership maps each stargazer's login to its community.
+	Membership map[string]int `json:"membership"`
+}
+
+func (r *CommunitiesResult) Name() string { return "communities" }
+
+func (r *CommunitiesResult) Header() []string {
+	return []string{"Community", "Stargazers", "Nodes", "Members", "Companies", "Locations", "Distinctive Starred Repos"}
+}
+
+func (r *CommunitiesResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Communities))
+	for _, c := range r.Communities {
+		rows = append(rows, []string{strconv.Itoa(c.ID), strconv.Itoa(c.Stargazers), strconv.Itoa(c.Nodes),
+			strings.Join(c.Members, "; "), joinNameCounts(c.Companies), joinNameCounts(c.Locations),
+			joinNameCounts(c.DistinctiveStarred)})
+	}
+	return rows
+}
+
+func (r *CommunitiesResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Communities))
+	for i, c := range r.Communities {
+		recs[i] = c
+	}
+	return recs
+}
+
+func joinNameCounts(ncs []*NameCount) string {
+	strs := make([]string, len(ncs))
+	for i, nc := range ncs {
+		strs[i] = fmt.Sprintf("%s (%d)", nc.Name, nc.Count)
+	}
+	return strings.Join(strs, "; ")
+}
+
+// Communities clusters the follower graph of stargazers and their
+// followers using the Louvain method, and describes each community
+// of at least minCommunitySize stargazers by its most followed
+// members, dominant current employers (as normalized by Employers)
+// and locations, and the starred repos most distinctive of its
+// members relative to all stargazers. Communities are numbered from
+// one in order of descending size.
+func Communities(sg []*fetch.Stargazer, employers *StargazerEmployersResult) *CommunitiesResult {
+	log.Printf("running communities analysis")
+	g := newFollowerGraph(sg)
+	comm := louvain(g.undirected())
+
+	// Group nodes by community.
+	groups := map[int][]int{}
+	for i, c := range comm {
+		groups[c] = append(groups[c], i)
+	}
+	var ordered [][]int
+	for _, nodes := range groups {
+		ordered = append(ordered, nodes)
+	}
+	sort.Sort(bySize{ordered, g})
+
+	// Starred repo counts over all stargazers, for distinctiveness.
+	allCounts := map[string]int{}
+	for _, s := range sg {
+		for _, rName := range s.Starred {
+			allCounts[rName]++
+		}
+	}
+
+	employerByLogin := map[string]string{}
+	for _, e := range employers.Stargazers {
+		if len(e.Key) > 0 && !e.Former {
+			employerByLogin[e.Login] = e.Name
+		}
+	}
+
+	res := &CommunitiesResult{Membership: map[string]int{}}
+	for i, nodes := range ordered {
+		id := i + 1
+		var members []*fetch.Stargazer
+		for _, n := range nodes {
+			if s := g.stargazer[n]; s != nil {
+				members = append(members, s)
+				res.Membership[s.Login] = id
+			}
+		}
+		if len(members) < minCommunitySize {
+			continue
+		}
+		c := &Community{ID: id, Stargazers: len(members), Nodes: len(nodes)}
+		sort.Sort(byFollowerCount(members))
+		companies, locations, starred := map[string]int{}, map[string]int{}, map[string]int{}
+		for j, s := range members {
+			if j < nCommunityMembers {
+				c.Members = append(c.Members, s.Login)
+			}
+			if employer, ok := employerByLogin[s.Login]; ok {
+				companies[employer]++
+			}
+			if location := strings.TrimSpace(s.Location); len(location) > 0 {
+				locations[location]++
+			}
+			for _, rName := range s.Starred {
+				starred[rName]++
+			}
+		}
+		c.Companies = topNameCounts(companies, nCommunityTop)
+		c.Locations = topNameCounts(locations, nCommunityTop)
+		c.DistinctiveStarred = distinctive(starred, len(members), allCounts, len(sg), nCommunityTop)
+		res.Communities = append(res.Communities, c)
+	}
+	return res
+}
+
+// topNameCounts returns the n names with highest counts, breaking
+// ties by name.
+func topNameCounts(counts map[string]int, n int) []*NameCount {
+	var ncs []*NameCount
+	for name, count := range counts {
+		ncs = append(ncs, &NameCount{Name: name, Count: count})
+	}
+	sort.Sort(byCount(ncs))
+	if len(ncs) > n {
+		ncs = ncs[:n]
+	}
+	return ncs
+}
+
+// distinctive returns the n repos starred by a group which have the
+// highest lift relative to all stargazers: the fraction of the group
+// starring the repo divided by the fraction of all stargazers doing
+// so. Repos must be starred by at least two members of the group.
+func distinctive(counts map[string]int, size int, allCounts map[string]int, total int, n int) []*NameCount {
+	var ls byLift
+	for name, count := range counts {
+		if count < 2 {
+			continue
+		}
+		lift := (float64(count) / float64(size)) / (float64(allCounts[name]) / float64(total))
+		ls = append(ls, liftedName{&NameCount{Name: name, Count: count}, lift})
+	}
+	sort.Sort(ls)
+	var ncs []*NameCount
+	for i, l := range ls {
+		if i >= n {
+			break
+		}
+		ncs = append(ncs, l.nc)
+	}
+	return ncs
+}
+
+type liftedName struct {
+	nc   *NameCount
+	lift float64
+}
+
+// byLift sorts names by descending lift, then descending count,
+// breaking ties by name.
+type byLift []liftedName
+
+func (slice byLift) Len() int {
+	return len(slice)
+}
+
+func (slice byLift) Less(i, j int) bool {
+	if slice[i].lift != slice[j].lift {
+		return slice[i].lift > slice[j].lift /* descending order */
+	}
+	if slice[i].nc.Count != slice[j].nc.Count {
+		return slice[i].nc.Count > slice[j].nc.Count
+	}
+	return slice[i].nc.Name < slice[j].nc.Name
+}
+
+func (slice byLift) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// byCount sorts name counts by descending count, breaking ties by name.
+type byCount []*NameCount
+
+func (slice byCount) Len() int {
+	return len(slice)
+}
+
+func (slice byCount) Less(i, j int) bool {
+	if slice[i].Count != slice[j].Count {
+		return slice[i].Count > slice[j].Count /* descending order */
+	}
+	return slice[i].Name < slice[j].Name
+}
+
+func (slice byCount) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// byFollowerCount sorts stargazers by descending follower count,
+// breaking ties by login.
+type byFollowerCount []*fetch.Stargazer
+
+func (slice byFollowerCount) Len() int {
+	return len(slice)
+}
+
+func (slice byFollowerCount) Less(i, j int) bool {
+	if slice[i].User.Followers != slice[j].User.Followers {
+		return slice[i].User.Followers > slice[j].User.Followers /* descending order */
+	}
+	return slice[i].Login < slice[j].Login
+}
+
+func (slice byFollowerCount) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// bySize sorts groups of graph nodes by descending count of
+// stargazers, then total nodes, breaking ties by the group's first
+// (lowest indexed) node.
+type bySize struct {
+	groups [][]int
+	g      *followerGraph
+}
+
+func (s bySize) Len() int {
+	return len(s.groups)
+}
+
+func (s bySize) Less(i, j int) bool {
+	si, sj := s.stargazers(i), s.stargazers(j)
+	if si != sj {
+		return si > sj
+	}
+	if len(s.groups[i]) != len(s.groups[j]) {
+		return len(s.groups[i]) > len(s.groups[j])
+	}
+	return s.groups[i][0] < s.groups[j][0]
+}
+
+func (s bySize) Swap(i, j int) {
+	s.groups[i], s.groups[j] = s.groups[j], s.groups[i]
+}
+
+func (s bySize) stargazers(i int) int {
+	count := 0
+	for _, n := range s.groups[i] {
+		if s.g.stargazer[n] != nil {
+			count++
+		}
+	}
+	return count
+}
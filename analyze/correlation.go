@@ -0,0 +1,122 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultPopulation is the default estimate of the number of GitHub
+// users, used as the base population when computing the base rate at
+// which a repo is starred. Only lift and PMI depend on it, and
+// rankings by either are unaffected by its value.
+const DefaultPopulation = 100000000
+
+// CorrelationMetrics lists the metrics by which correlated repos may
+// be ranked.
+var CorrelationMetrics = []string{"count", "lift", "jaccard", "pmi", "z"}
+
+// correlationScores holds popularity-normalized measures of how much
+// more often a repo is starred by our stargazers than by GitHub users
+// at large.
+type correlationScores struct {
+	Lift    float64 // P(repo | stargazer) / P(repo)
+	Jaccard float64 // |stargazers ∩ repo stargazers| / |stargazers ∪ repo stargazers|
+	PMI     float64 // log2(Lift)
+	Z       float64 // Standard score of count under the base rate
+	PValue  float64 // One-sided p-value of Z
+}
+
+// scoreCorrelation computes correlation scores for a repo which is
+// starred by count of n stargazers and has repoStars stargazers of
+// its own, out of a population of GitHub users.
+func scoreCorrelation(count, n, repoStars int, population float64) correlationScores {
+	// The repo's star count may be stale relative to the star lists.
+	if repoStars < count {
+		repoStars = count
+	}
+	k, nf, s := float64(count), float64(n), float64(repoStars)
+	var cs correlationScores
+	if n == 0 || s == 0 {
+		return cs
+	}
+	p := s / population
+	cs.Lift = (k / nf) / p
+	cs.PMI = math.Log2(cs.Lift)
+	cs.Jaccard = k / (nf + s - k)
+	// Normal approximation to the binomial: under the null hypothesis,
+	// each stargazer stars the repo independently with probability p.
+	if sd := math.Sqrt(nf * p * (1 - p)); sd > 0 {
+		cs.Z = (k - nf*p) / sd
+	}
+	cs.PValue = 0.5 * math.Erfc(cs.Z/math.Sqrt2)
+	return cs
+}
+
+// ValidateCorrelationMetric returns an error if the metric isn't one
+// of CorrelationMetrics.
+func ValidateCorrelationMetric(metric string) error {
+	_, err := correlationMetric(metric)
+	return err
+}
+
+// correlationMetric returns a function which extracts the ranking
+// metric from a correlated repo.
+func correlationMetric(metric string) (func(r *CorrelatedRepo) float64, error) {
+	switch metric {
+	case "", "count":
+		return func(r *CorrelatedRepo) float64 { return float64(r.Count) }, nil
+	case "lift":
+		return func(r *CorrelatedRepo) float64 { return r.Lift }, nil
+	case "jaccard":
+		return func(r *CorrelatedRepo) float64 { return r.Jaccard }, nil
+	case "pmi":
+		return func(r *CorrelatedRepo) float64 { return r.PMI }, nil
+	case "z":
+		return func(r *CorrelatedRepo) float64 { return r.Z }, nil
+	}
+	return nil, fmt.Errorf("unknown correlation metric %q; must be one of %s",
+		metric, strings.Join(CorrelationMetrics, ", "))
+}
+
+// byMetric sorts correlated repos by descending metric, breaking ties
+// by descending count and then by name.
+type byMetric struct {
+	repos  []*CorrelatedRepo
+	metric func(r *CorrelatedRepo) float64
+}
+
+func (s byMetric) Len() int {
+	return len(s.repos)
+}
+
+func (s byMetric) Less(i, j int) bool {
+	a, b := s.repos[i], s.repos[j]
+	if ma, mb := s.metric(a), s.metric(b); ma != mb {
+		return ma > mb /* descending order */
+	}
+	if a.Count != b.Count {
+		return a.Count > b.Count
+	}
+	return a.Name < b.Name
+}
+
+func (s byMetric) Swap(i, j int) {
+	s.repos[i], s.repos[j] = s.repos[j], s.repos[i]
+}
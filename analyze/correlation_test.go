@@ -0,0 +1,76 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScoreCorrelation(t *testing.T) {
+	testCases := []struct {
+		count, n, repoStars int
+		population          float64
+		exp                 correlationScores
+	}{
+		// p = 1000/1e6 = 0.001; lift = 0.1/0.001 = 100; jaccard =
+		// 10/1090; z = (10-0.1)/sqrt(100*0.001*0.999).
+		{10, 100, 1000, 1e6, correlationScores{Lift: 100, PMI: 6.643856, Jaccard: 0.0091743, Z: 31.322214, PValue: 0}},
+		// Starred exactly at the base rate: p = 0.25 and n*p = 1.
+		{1, 4, 2, 8, correlationScores{Lift: 1, PMI: 0, Jaccard: 0.2, Z: 0, PValue: 0.5}},
+		// A stale repo star count is raised to the count: p = 5/100.
+		{5, 10, 3, 100, correlationScores{Lift: 10, PMI: 3.321928, Jaccard: 0.5, Z: 6.529286, PValue: 3.304192e-11}},
+		// Starred less often than the base rate: p = 0.05 and n*p = 5.
+		{1, 100, 50, 1000, correlationScores{Lift: 0.2, PMI: -2.321928, Jaccard: 0.0067114, Z: -1.835326, PValue: 0.966771}},
+		// No stargazers.
+		{0, 0, 10, 1000, correlationScores{}},
+	}
+	for i, c := range testCases {
+		cs := scoreCorrelation(c.count, c.n, c.repoStars, c.population)
+		for _, m := range []struct {
+			name     string
+			got, exp float64
+			tol      float64
+		}{
+			{"lift", cs.Lift, c.exp.Lift, 1e-6},
+			{"pmi", cs.PMI, c.exp.PMI, 1e-6},
+			{"jaccard", cs.Jaccard, c.exp.Jaccard, 1e-7},
+			{"z", cs.Z, c.exp.Z, 1e-6},
+			{"p-value", cs.PValue, c.exp.PValue, 1e-6},
+		} {
+			if math.Abs(m.got-m.exp) > m.tol*math.Max(1, math.Abs(m.exp)) {
+				t.Errorf("%d: expected %s %g; got %g", i, m.name, m.exp, m.got)
+			}
+		}
+	}
+}
+
+func TestCorrelationMetric(t *testing.T) {
+	r := &CorrelatedRepo{Count: 7, Lift: 2, Jaccard: 0.1, PMI: 1, Z: 3}
+	for metric, exp := range map[string]float64{"": 7, "count": 7, "lift": 2, "jaccard": 0.1, "pmi": 1, "z": 3} {
+		fn, err := correlationMetric(metric)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := fn(r); v != exp {
+			t.Errorf("%q: expected %g; got %g", metric, exp, v)
+		}
+	}
+	if err := ValidateCorrelationMetric("chi2"); err == nil {
+		t.Error("expected error for unknown metric")
+	}
+}
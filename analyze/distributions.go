@@ -0,0 +1,259 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+const (
+	// nBootstrap is the number of bootstrap resamples from which
+	// confidence intervals are estimated.
+	nBootstrap = 200
+	// bootstrapSeed seeds the resampling, so results are reproducible.
+	bootstrapSeed = 1
+	// AllPeriods is the period label of the overall distributions.
+	AllPeriods = "all"
+)
+
+// distributionMetrics are the stargazer attributes whose
+// distributions are analyzed.
+var distributionMetrics = []struct {
+	name  string
+	value func(s *fetch.Stargazer, asOf time.Time) float64
+}{
+	{"account_age_days", func(s *fetch.Stargazer, asOf time.Time) float64 {
+		return math.Max(0, float64(s.Age(asOf)/daySeconds))
+	}},
+	{"followers", func(s *fetch.Stargazer, asOf time.Time) float64 { return float64(s.User.Followers) }},
+	{"public_repos", func(s *fetch.Stargazer, asOf time.Time) float64 { return float64(s.PublicRepos) }},
+	{"commits", func(s *fetch.Stargazer, asOf time.Time) float64 {
+		c, _, _ := s.TotalCommits()
+		return float64(c)
+	}},
+	{"starred_repos", func(s *fetch.Stargazer, asOf time.Time) float64 { return float64(len(s.Starred)) }},
+}
+
+// Distribution summarizes the distribution of an attribute of the
+// stargazers who starred in a period, with 95% bootstrap confidence
+// intervals for the mean, median and Gini coefficient.
+type Distribution struct {
+	Period     string  `json:"period"` // See Resampler.Period; "all" overall
+	Metric     string  `json:"metric"`
+	N          int     `json:"n"`
+	Mean       float64 `json:"mean"`
+	MeanLow    float64 `json:"mean_low"`
+	MeanHigh   float64 `json:"mean_high"`
+	Median     float64 `json:"median"`
+	MedianLow  float64 `json:"median_low"`
+	MedianHigh float64 `json:"median_high"`
+	P10        float64 `json:"p10"`
+	P90        float64 `json:"p90"`
+	P99        float64 `json:"p99"`
+	Gini       float64 `json:"gini"`
+	GiniLow    float64 `json:"gini_low"`
+	GiniHigh   float64 `json:"gini_high"`
+}
+
+// DistributionsResult is the result of the attribute distributions
+// analysis.
+type DistributionsResult struct {
+	Distributions []*Distribution `json:"distributions"`
+}
+
+func (r *DistributionsResult) Name() string { return "attribute_distributions" }
+
+func (r *DistributionsResult) Header() []string {
+	return []string{"Period", "Metric", "N", "Mean", "Mean Low", "Mean High", "Median", "Median Low", "Median High",
+		"P10", "P90", "P99", "Gini", "Gini Low", "Gini High"}
+}
+
+func (r *DistributionsResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Distributions))
+	for _, d := range r.Distributions {
+		row := []string{d.Period, d.Metric, strconv.Itoa(d.N)}
+		for _, v := range []float64{d.Mean, d.MeanLow, d.MeanHigh, d.Median, d.MedianLow, d.MedianHigh, d.P10, d.P90, d.P99} {
+			row = append(row, fmt.Sprintf("%.2f", v))
+		}
+		for _, v := range []float64{d.Gini, d.GiniLow, d.GiniHigh} {
+			row = append(row, fmt.Sprintf("%.3f", v))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (r *DistributionsResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Distributions))
+	for i, d := range r.Distributions {
+		recs[i] = d
+	}
+	return recs
+}
+
+// HistogramBin holds the count of stargazers who starred in a period
+// with an attribute in [Low, High). Bins are logarithmic: [0, 1),
+// then successive powers of two.
+type HistogramBin struct {
+	Period string  `json:"period"` // See Resampler.Period; "all" overall
+	Metric string  `json:"metric"`
+	Low    float64 `json:"low"`
+	High   float64 `json:"high"`
+	Count  int     `json:"count"`
+	Share  float64 `json:"share"` // Percent of the period's stargazers
+}
+
+// HistogramsResult is the result of the attribute histograms
+// analysis.
+type HistogramsResult struct {
+	Bins []*HistogramBin `json:"bins"`
+}
+
+func (r *HistogramsResult) Name() string { return "attribute_histograms" }
+
+func (r *HistogramsResult) Header() []string {
+	return []string{"Period", "Metric", "Low", "High", "Count", "Share %"}
+}
+
+func (r *HistogramsResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Bins))
+	for _, b := range r.Bins {
+		rows = append(rows, []string{b.Period, b.Metric, strconv.FormatFloat(b.Low, 'f', -1, 64),
+			strconv.FormatFloat(b.High, 'f', -1, 64), strconv.Itoa(b.Count), fmt.Sprintf("%.1f", b.Share)})
+	}
+	return rows
+}
+
+func (r *HistogramsResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Bins))
+	for i, b := range r.Bins {
+		recs[i] = b
+	}
+	return recs
+}
+
+// Distributions computes the distribution of each stargazer attribute
+// in distributionMetrics (account age as of opts.AsOf, followers,
+// public repos, commits to subscribed repos and starred repos), over
+// all stargazers and for those who starred in each period of
+// opts.Resampler (by default, each UTC month). Unlike the averages of
+// AttributesByTime, the median, percentiles and Gini coefficient are
+// robust to a few stargazers with outsized values.
+func Distributions(sg []*fetch.Stargazer, opts Options) (*DistributionsResult, *HistogramsResult, error) {
+	log.Printf("running attribute distributions analysis")
+	asOf := opts.asOf()
+	byPeriod := map[string][]*fetch.Stargazer{}
+	periods := []string{AllPeriods}
+	for _, s := range sg {
+		t, err := time.Parse(time.RFC3339, s.StarredAt)
+		if err != nil {
+			return nil, nil, err
+		}
+		p := opts.Resampler.Period(t)
+		if _, ok := byPeriod[p]; !ok {
+			periods = append(periods, p)
+		}
+		byPeriod[p] = append(byPeriod[p], s)
+	}
+	sort.Strings(periods[1:])
+	byPeriod[AllPeriods] = sg
+
+	res, hists := &DistributionsResult{}, &HistogramsResult{}
+	for _, p := range periods {
+		members := byPeriod[p]
+		if len(members) == 0 {
+			continue
+		}
+		for _, m := range distributionMetrics {
+			values := make([]float64, len(members))
+			for i, s := range members {
+				values[i] = m.value(s, asOf)
+			}
+			d := distribution(values)
+			d.Period, d.Metric = p, m.name
+			res.Distributions = append(res.Distributions, d)
+			for _, b := range logHistogram(values) {
+				b.Period, b.Metric = p, m.name
+				hists.Bins = append(hists.Bins, b)
+			}
+		}
+	}
+	return res, hists, nil
+}
+
+// distribution summarizes the values, estimating confidence intervals
+// from nBootstrap resamples. The values are sorted in place.
+func distribution(values []float64) *Distribution {
+	sort.Float64s(values)
+	d := &Distribution{
+		N:      len(values),
+		Mean:   mean(values),
+		Median: quantile(values, 0.5),
+		P10:    quantile(values, 0.1),
+		P90:    quantile(values, 0.9),
+		P99:    quantile(values, 0.99),
+		Gini:   gini(values),
+	}
+	rng := rand.New(rand.NewSource(bootstrapSeed))
+	means := make([]float64, nBootstrap)
+	medians := make([]float64, nBootstrap)
+	ginis := make([]float64, nBootstrap)
+	sample := make([]float64, len(values))
+	for i := 0; i < nBootstrap; i++ {
+		for j := range sample {
+			sample[j] = values[rng.Intn(len(values))]
+		}
+		sort.Float64s(sample)
+		means[i], medians[i], ginis[i] = mean(sample), quantile(sample, 0.5), gini(sample)
+	}
+	interval := func(stats []float64) (float64, float64) {
+		sort.Float64s(stats)
+		return quantile(stats, 0.025), quantile(stats, 0.975)
+	}
+	d.MeanLow, d.MeanHigh = interval(means)
+	d.MedianLow, d.MedianHigh = interval(medians)
+	d.GiniLow, d.GiniHigh = interval(ginis)
+	return d
+}
+
+// logHistogram counts the sorted, non-negative values in logarithmic
+// bins, from [0, 1) through the bin of the largest value.
+func logHistogram(sorted []float64) []*HistogramBin {
+	var bins []*HistogramBin
+	for _, v := range sorted {
+		for len(bins) == 0 || v >= bins[len(bins)-1].High {
+			low := 0.0
+			if len(bins) > 0 {
+				low = bins[len(bins)-1].High
+			}
+			bins = append(bins, &HistogramBin{Low: low, High: math.Max(1, 2*low)})
+		}
+		bins[len(bins)-1].Count++
+	}
+	for _, b := range bins {
+		b.Share = percent(b.Count, len(sorted))
+	}
+	return bins
+}
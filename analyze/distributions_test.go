@@ -0,0 +1,60 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLogHistogram(t *testing.T) {
+	type bin struct {
+		low, high float64
+		count     int
+		share     float64
+	}
+	testCases := []struct {
+		sorted   []float64
+		expected []bin
+	}{
+		{nil, nil},
+		{[]float64{0}, []bin{{0, 1, 1, 100}}},
+		// Bins double from [1, 2); empty bins below the largest value
+		// are kept.
+		{[]float64{0, 0.5, 1, 1.9, 2, 3, 9}, []bin{
+			{0, 1, 2, 200.0 / 7},
+			{1, 2, 2, 200.0 / 7},
+			{2, 4, 2, 200.0 / 7},
+			{4, 8, 0, 0},
+			{8, 16, 1, 100.0 / 7},
+		}},
+		{[]float64{1, 1, 1, 1}, []bin{{0, 1, 0, 0}, {1, 2, 4, 100}}},
+	}
+	for i, tc := range testCases {
+		bins := logHistogram(tc.sorted)
+		if len(bins) != len(tc.expected) {
+			t.Errorf("%d: expected %d bins; got %d", i, len(tc.expected), len(bins))
+			continue
+		}
+		for j, b := range bins {
+			exp := tc.expected[j]
+			if b.Low != exp.low || b.High != exp.high || b.Count != exp.count || math.Abs(b.Share-exp.share) > 1e-9 {
+				t.Errorf("%d: expected bin %d %+v; got %+v", i, j, exp, *b)
+			}
+		}
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spencerkimball/stargazers/company"
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+const (
+	// nTopEmployers is the number of employers with the most
+	// stargazers included in the monthly trend.
+	nTopEmployers = 20
+	// nEmployerContributors is the number of top contributors listed
+	// for each employer.
+	nEmployerContributors = 3
+)
+
+// StargazerEmployer is a stargazer's company field and the employer
+// to which it was normalized.
+type StargazerEmployer struct {
+	Login   string `json:"login"`
+	Company string `json:"company"`
+	company.Employer
+}
+
+// StargazerEmployersResult is the result of the company normalization.
+type StargazerEmployersResult struct {
+	Stargazers []*StargazerEmployer `json:"stargazers"`
+}
+
+func (r *StargazerEmployersResult) Name() string { return "stargazer_employers" }
+
+func (r *StargazerEmployersResult) Header() []string {
+	return []string{"Login", "Company", "Employer", "Source", "Former"}
+}
+
+func (r *StargazerEmployersResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Stargazers))
+	for _, s := range r.Stargazers {
+		rows = append(rows, []string{s.Login, s.Company, s.Employer.Name, s.Source, strconv.FormatBool(s.Former)})
+	}
+	return rows
+}
+
+func (r *StargazerEmployersResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Stargazers))
+	for i, s := range r.Stargazers {
+		recs[i] = s
+	}
+	return recs
+}
+
+// EmployerContributor is a stargazer with commits to subscribed repos.
+type EmployerContributor struct {
+	Login   string `json:"login"`
+	Commits int    `json:"commits"`
+}
+
+// EmployerStats holds the stargazers employed by a company.
+type EmployerStats struct {
+	Employer   string  `json:"employer"`
+	Stargazers int     `json:"stargazers"`
+	Share      float64 `json:"share"`  // Percent of all stargazers
+	Former     int     `json:"former"` // Stargazers formerly employed
+	Committers int     `json:"committers"`
+	Commits    int     `json:"commits"`
+	// TopContributors are the current employees with the most commits.
+	TopContributors []*EmployerContributor `json:"top_contributors"`
+
+	key string
+}
+
+// EmployersResult is the result of the employers analysis.
+type EmployersResult struct {
+	Employers []*EmployerStats `json:"employers"`
+}
+
+func (r *EmployersResult) Name() string { return "employers" }
+
+func (r *EmployersResult) Header() []string {
+	return []string{"Employer", "Stargazers", "Share %", "Former", "Committers", "Commits", "Top Contributors"}
+}
+
+func (r *EmployersResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Employers))
+	for _, e := range r.Employers {
+		var top []string
+		for _, c := range e.TopContributors {
+			top = append(top, fmt.Sprintf("%s (%d)", c.Login, c.Commits))
+		}
+		rows = append(rows, []string{e.Employer, strconv.Itoa(e.Stargazers), fmt.Sprintf("%.1f", e.Share),
+			strconv.Itoa(e.Former), strconv.Itoa(e.Committers), strconv.Itoa(e.Commits), strings.Join(top, "; ")})
+	}
+	return rows
+}
+
+func (r *EmployersResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Employers))
+	for i, e := range r.Employers {
+		recs[i] = e
+	}
+	return recs
+}
+
+// EmployerMonth holds the stars from an employer's staff in a month.
+type EmployerMonth struct {
+	Month      string `json:"month"` // Period; see Resampler.Period
+	Employer   string `json:"employer"`
+	NewStars   int    `json:"new_stars"`
+	Cumulative int    `json:"cumulative"`
+}
+
+// EmployersByMonthResult is the monthly trend of stars from the top
+// employers.
+type EmployersByMonthResult struct {
+	Months []*EmployerMonth `json:"months"`
+}
+
+func (r *EmployersByMonthResult) Name() string { return "employers_by_month" }
+
+func (r *EmployersByMonthResult) Header() []string {
+	return []string{"Month", "Employer", "New Stars", "Cumulative"}
+}
+
+func (r *EmployersByMonthResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Months))
+	for _, m := range r.Months {
+		rows = append(rows, []string{m.Month, m.Employer, strconv.Itoa(m.NewStars), strconv.Itoa(m.Cumulative)})
+	}
+	return rows
+}
+
+func (r *EmployersByMonthResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Months))
+	for i, m := range r.Months {
+		recs[i] = m
+	}
+	return recs
+}
+
+// Employers normalizes each stargazer's employer (see
+// company.Normalizer), then counts current and former employees of
+// each employer, their commits to subscribed repos, and for the
+// nTopEmployers employers with the most stargazers, the stars by
+// period of opts.Resampler (by default, UTC month). Each employer is displayed by its most common
+// spelling unless a canonical name is known.
+func Employers(sg []*fetch.Stargazer, opts Options) (*StargazerEmployersResult, *EmployersResult, *EmployersByMonthResult, error) {
+	log.Printf("running employers analysis")
+	profiles := make([]company.Profile, len(sg))
+	for i, s := range sg {
+		profiles[i] = company.Profile{Login: s.Login, Company: s.Company, Email: s.Email, Blog: s.Blog, Orgs: s.Orgs}
+	}
+	employers := company.NewNormalizer(opts.CompanyOverrides).Normalize(profiles)
+
+	// Choose the display name of each employer.
+	spellings := map[string]map[string]int{}
+	for _, e := range employers {
+		if len(e.Key) == 0 {
+			continue
+		}
+		if spellings[e.Key] == nil {
+			spellings[e.Key] = map[string]int{}
+		}
+		spellings[e.Key][e.Name]++
+	}
+	display := map[string]string{}
+	for key, counts := range spellings {
+		best := ""
+		for name, n := range counts {
+			if len(best) == 0 || n > counts[best] || (n == counts[best] && name < best) {
+				best = name
+			}
+		}
+		display[key] = best
+	}
+
+	byLogin := &StargazerEmployersResult{}
+	stats := map[string]*EmployerStats{}
+	var all []*EmployerStats
+	for i, s := range sg {
+		e := employers[i]
+		if len(e.Key) > 0 {
+			e.Name = display[e.Key]
+		}
+		byLogin.Stargazers = append(byLogin.Stargazers, &StargazerEmployer{Login: s.Login, Company: s.Company, Employer: e})
+		if len(e.Key) == 0 {
+			continue
+		}
+		es, ok := stats[e.Key]
+		if !ok {
+			es = &EmployerStats{Employer: e.Name, key: e.Key}
+			stats[e.Key] = es
+			all = append(all, es)
+		}
+		if e.Former {
+			es.Former++
+		} else {
+			es.Stargazers++
+		}
+	}
+
+	// Tally commits, visiting stargazers from most to least prolific.
+	contributors := make(Contributors, len(sg))
+	copy(contributors, sg)
+	sort.Stable(contributors)
+	index := map[*fetch.Stargazer]int{}
+	for i, s := range sg {
+		index[s] = i
+	}
+	for _, s := range contributors {
+		e := employers[index[s]]
+		c, _, _ := s.TotalCommits()
+		if len(e.Key) == 0 || e.Former || c == 0 {
+			continue
+		}
+		es := stats[e.Key]
+		es.Committers++
+		es.Commits += c
+		if len(es.TopContributors) < nEmployerContributors {
+			es.TopContributors = append(es.TopContributors, &EmployerContributor{Login: s.Login, Commits: c})
+		}
+	}
+	for _, es := range all {
+		es.Share = percent(es.Stargazers, len(sg))
+	}
+	sort.Sort(byEmployerStargazers(all))
+	res := &EmployersResult{Employers: all}
+
+	// Monthly trend of the top employers.
+	top := map[string]bool{}
+	for i, es := range all {
+		if i >= nTopEmployers || es.Stargazers == 0 {
+			break
+		}
+		top[es.key] = true
+	}
+	type key struct{ month, employer string }
+	counts := map[key]int{}
+	var months []string
+	seen := map[string]bool{}
+	for i, s := range sg {
+		e := employers[i]
+		if !top[e.Key] || e.Former {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, s.StarredAt)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		month := opts.Resampler.Period(t)
+		if !seen[month] {
+			seen[month] = true
+			months = append(months, month)
+		}
+		counts[key{month, e.Key}]++
+	}
+	sort.Strings(months)
+	trend := &EmployersByMonthResult{}
+	cumulative := map[string]int{}
+	for _, month := range months {
+		for _, es := range all {
+			n := counts[key{month, es.key}]
+			if n == 0 {
+				continue
+			}
+			cumulative[es.key] += n
+			trend.Months = append(trend.Months, &EmployerMonth{
+				Month: month, Employer: es.Employer, NewStars: n, Cumulative: cumulative[es.key],
+			})
+		}
+	}
+	return byLogin, res, trend, nil
+}
+
+// byEmployerStargazers sorts employers by descending stargazers,
+// then descending former employees, then name.
+type byEmployerStargazers []*EmployerStats
+
+func (slice byEmployerStargazers) Len() int {
+	return len(slice)
+}
+
+func (slice byEmployerStargazers) Less(i, j int) bool {
+	if slice[i].Stargazers != slice[j].Stargazers {
+		return slice[i].Stargazers > slice[j].Stargazers
+	}
+	if slice[i].Former != slice[j].Former {
+		return slice[i].Former > slice[j].Former
+	}
+	return slice[i].Employer < slice[j].Employer
+}
+
+func (slice byEmployerStargazers) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
@@ -0,0 +1,399 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+const (
+	// forecastWindow is the number of trailing days of history to
+	// which the forecast models are fit.
+	forecastWindow = 365
+	// forecastMinDays is the minimum days of history required to
+	// backtest and fit the models.
+	forecastMinDays = 28
+	// forecastBacktest is the maximum number of days held out to
+	// backtest each model; at most a quarter of the history is held out.
+	forecastBacktest = 90
+	// forecastSeason is the length in days of the Holt-Winters season.
+	forecastSeason = 7
+	// forecastDamping is the per-day damping of the Holt-Winters trend,
+	// which keeps long horizons from extrapolating a short-lived trend.
+	forecastDamping = 0.98
+	// z80 and z95 are the standard normal quantiles of the 80% and
+	// 95% two-sided prediction intervals.
+	z80 = 1.2816
+	z95 = 1.9600
+)
+
+// ForecastHorizons are the days ahead of the as-of reference time for
+// which forecasts are reported.
+var ForecastHorizons = []int{30, 90, 365}
+
+// ForecastModel describes a candidate model and its backtest error.
+type ForecastModel struct {
+	Model    string  `json:"model"`
+	RMSE     float64 `json:"rmse"` // Root mean squared error of the cumulative count over the holdout
+	MAPE     float64 `json:"mape"` // Mean absolute percentage error over the holdout
+	Selected bool    `json:"selected"`
+}
+
+// ForecastPoint is the forecast cumulative star count on a day, with
+// 80% and 95% prediction intervals.
+type ForecastPoint struct {
+	Horizon  int       `json:"horizon"` // Days after the as-of day
+	Date     time.Time `json:"date"`
+	Forecast float64   `json:"forecast"`
+	Lower80  float64   `json:"lower_80"`
+	Upper80  float64   `json:"upper_80"`
+	Lower95  float64   `json:"lower_95"`
+	Upper95  float64   `json:"upper_95"`
+}
+
+// ForecastResult is the result of the star growth forecast.
+type ForecastResult struct {
+	Model    string           `json:"model"`
+	Horizons []*ForecastPoint `json:"horizons"`
+	// Start is the as-of day from which the forecast starts, and
+	// Current the cumulative star count on that day.
+	Start   time.Time `json:"-"`
+	Current float64   `json:"-"`
+	// Path holds the forecast for every day through the longest horizon.
+	Path []*ForecastPoint `json:"-"`
+}
+
+func (r *ForecastResult) Name() string { return "star_forecast" }
+
+func (r *ForecastResult) Header() []string {
+	return []string{"Horizon", "Date", "Model", "Forecast", "Lower 80", "Upper 80", "Lower 95", "Upper 95"}
+}
+
+func (r *ForecastResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Horizons))
+	for _, p := range r.Horizons {
+		rows = append(rows, []string{strconv.Itoa(p.Horizon), p.Date.Format("2006-01-02"), r.Model,
+			fmt.Sprintf("%.0f", p.Forecast), fmt.Sprintf("%.0f", p.Lower80), fmt.Sprintf("%.0f", p.Upper80),
+			fmt.Sprintf("%.0f", p.Lower95), fmt.Sprintf("%.0f", p.Upper95)})
+	}
+	return rows
+}
+
+func (r *ForecastResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Horizons))
+	for i, p := range r.Horizons {
+		recs[i] = p
+	}
+	return recs
+}
+
+// ForecastModelsResult is the backtest comparison of the forecast models.
+type ForecastModelsResult struct {
+	Holdout int              `json:"holdout"` // Days held out
+	Models  []*ForecastModel `json:"models"`
+}
+
+func (r *ForecastModelsResult) Name() string { return "star_forecast_models" }
+
+func (r *ForecastModelsResult) Header() []string {
+	return []string{"Model", "Holdout Days", "RMSE", "MAPE %", "Selected"}
+}
+
+func (r *ForecastModelsResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Models))
+	for _, m := range r.Models {
+		rows = append(rows, []string{m.Model, strconv.Itoa(r.Holdout), fmt.Sprintf("%.2f", m.RMSE),
+			fmt.Sprintf("%.2f", m.MAPE), strconv.FormatBool(m.Selected)})
+	}
+	return rows
+}
+
+func (r *ForecastModelsResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Models))
+	for i, m := range r.Models {
+		recs[i] = m
+	}
+	return recs
+}
+
+// A forecaster fits a model to a daily cumulative series and returns
+// the forecast for each of the next h days, along with the standard
+// deviation of the model's one-day-ahead errors.
+type forecaster func(y []float64, h int) (path []float64, sigma float64)
+
+var forecasters = []struct {
+	name string
+	fn   forecaster
+}{
+	{"linear", linearForecast},
+	{"log_linear", logLinearForecast},
+	{"holt_winters", holtWintersForecast},
+}
+
+// Forecast projects the cumulative star series forward by each of
+// ForecastHorizons days. Each candidate model is fit to all but the
+// last forecastBacktest days of history (at most a quarter of it) and
+// scored by its error forecasting the held out days; the model with
+// the lowest RMSE is refit to the full history. Prediction intervals
+// assume independent one-day-ahead errors, so their width grows with
+// the square root of the horizon; the lower bounds are clamped to
+// the current count.
+//
+// The cumulative stars must be by calendar day in the time zone of r
+// (see Resampler.DayLocation). Forecasts start from the day of the
+// asOf reference time, with the days since the last star counted as
+// history without stars, and require at least forecastMinDays days of
+// history; with less, the results are empty.
+func Forecast(cum *CumulativeStarsResult, asOf time.Time, r *Resampler) (*ForecastResult, *ForecastModelsResult) {
+	log.Printf("running star forecast analysis")
+	res, models := &ForecastResult{}, &ForecastModelsResult{}
+	if len(cum.Days) == 0 {
+		return res, models
+	}
+
+	// Fill in the cumulative count for days without stars.
+	loc := r.DayLocation()
+	first := dayNumber(cum.Days[0].Date, loc)
+	last := dayNumber(cum.Days[len(cum.Days)-1].Date, loc)
+	if day := dayNumber(asOf, loc); day > last {
+		last = day
+	}
+	y := make([]float64, last-first+1)
+	for _, d := range cum.Days {
+		y[dayNumber(d.Date, loc)-first] = float64(d.Cumulative)
+	}
+	for i := 1; i < len(y); i++ {
+		y[i] = math.Max(y[i], y[i-1])
+	}
+	if len(y) < forecastMinDays {
+		return res, models
+	}
+	if len(y) > forecastWindow {
+		y = y[len(y)-forecastWindow:]
+	}
+
+	// Backtest each model on the held out days.
+	models.Holdout = minInt(forecastBacktest, len(y)/4)
+	train, test := y[:len(y)-models.Holdout], y[len(y)-models.Holdout:]
+	best := -1
+	for i, f := range forecasters {
+		path, _ := f.fn(train, len(test))
+		var sse, ape float64
+		for j, actual := range test {
+			e := path[j] - actual
+			sse += e * e
+			ape += math.Abs(e) / actual
+		}
+		m := &ForecastModel{
+			Model: f.name,
+			RMSE:  math.Sqrt(sse / float64(len(test))),
+			MAPE:  100 * ape / float64(len(test)),
+		}
+		models.Models = append(models.Models, m)
+		if best == -1 || m.RMSE < models.Models[best].RMSE {
+			best = i
+		}
+	}
+	models.Models[best].Selected = true
+	res.Model = models.Models[best].Model
+
+	// Refit the best model to the full history.
+	horizon := ForecastHorizons[len(ForecastHorizons)-1]
+	path, sigma := forecasters[best].fn(y, horizon)
+	current := y[len(y)-1]
+	res.Start, res.Current = dayStart(last, loc), current
+	for i, v := range path {
+		h := i + 1
+		spread := sigma * math.Sqrt(float64(h))
+		p := &ForecastPoint{
+			Horizon:  h,
+			Date:     dayStart(last+int64(h), loc),
+			Forecast: v,
+			Lower80:  math.Max(current, v-z80*spread),
+			Upper80:  v + z80*spread,
+			Lower95:  math.Max(current, v-z95*spread),
+			Upper95:  v + z95*spread,
+		}
+		res.Path = append(res.Path, p)
+		for _, fh := range ForecastHorizons {
+			if h == fh {
+				res.Horizons = append(res.Horizons, p)
+			}
+		}
+	}
+	return res, models
+}
+
+// linearFit returns the least squares intercept and slope of v
+// against its index.
+func linearFit(v []float64) (float64, float64) {
+	n := float64(len(v))
+	var sx, sy, sxx, sxy float64
+	for i, y := range v {
+		x := float64(i)
+		sx += x
+		sy += y
+		sxx += x * x
+		sxy += x * y
+	}
+	denom := n*sxx - sx*sx
+	if denom == 0 {
+		return sy / n, 0
+	}
+	slope := (n*sxy - sx*sy) / denom
+	return (sy - slope*sx) / n, slope
+}
+
+// stddev returns the standard deviation of the values, or zero if
+// there are fewer than two.
+func stddev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	ss := 0.0
+	for _, v := range values {
+		ss += (v - m) * (v - m)
+	}
+	return math.Sqrt(ss / float64(len(values)-1))
+}
+
+// dailyCumulativeStars computes the cumulative stars by calendar day
+// in the time zone of r, as input to Forecast: under the legacy
+// preset, by UTC day, skipping days without stars.
+func dailyCumulativeStars(sg []*fetch.Stargazer, r *Resampler) (*CumulativeStarsResult, error) {
+	if r.Legacy() || r.Granularity == GranularityDaily {
+		return CumulativeStars(sg, r)
+	}
+	return CumulativeStars(sg, &Resampler{Granularity: GranularityDaily, Location: r.DayLocation()})
+}
+
+// linearForecast extends the series from its last value at the least
+// squares slope of the cumulative count.
+func linearForecast(y []float64, h int) ([]float64, float64) {
+	_, slope := linearFit(y)
+	errs := make([]float64, 0, len(y)-1)
+	for i := 1; i < len(y); i++ {
+		errs = append(errs, y[i]-y[i-1]-slope)
+	}
+	path := make([]float64, h)
+	for k := range path {
+		path[k] = y[len(y)-1] + slope*float64(k+1)
+	}
+	return path, stddev(errs)
+}
+
+// logLinearForecast extends the series from its last value at the
+// least squares exponential growth rate of the cumulative count.
+func logLinearForecast(y []float64, h int) ([]float64, float64) {
+	logs := make([]float64, 0, len(y))
+	for _, v := range y {
+		logs = append(logs, math.Log(math.Max(v, 1)))
+	}
+	_, rate := linearFit(logs)
+	growth := math.Exp(rate)
+	errs := make([]float64, 0, len(y)-1)
+	for i := 1; i < len(y); i++ {
+		errs = append(errs, y[i]-y[i-1]*growth)
+	}
+	path := make([]float64, h)
+	v := y[len(y)-1]
+	for k := range path {
+		v *= growth
+		path[k] = v
+	}
+	return path, stddev(errs)
+}
+
+// holtWintersForecast applies additive Holt-Winters smoothing with a
+// damped trend and a weekly season to the daily new stars, choosing the smoothing
+// parameters by grid search to minimize one-day-ahead squared error.
+// Forecast daily stars are floored at zero and accumulated from the
+// last value of the series.
+func holtWintersForecast(y []float64, h int) ([]float64, float64) {
+	daily := make([]float64, len(y)-1)
+	for i := range daily {
+		daily[i] = y[i+1] - y[i]
+	}
+	grid := []float64{0.05, 0.2, 0.5, 0.8}
+	var best *holtWinters
+	for _, alpha := range grid {
+		for _, beta := range grid[:3] {
+			for _, gamma := range grid[:3] {
+				hw := fitHoltWinters(daily, alpha, beta, gamma)
+				if best == nil || hw.sse < best.sse {
+					best = hw
+				}
+			}
+		}
+	}
+	path := make([]float64, h)
+	v := y[len(y)-1]
+	for k := range path {
+		v += math.Max(0, best.forecast(k+1))
+		path[k] = v
+	}
+	return path, stddev(best.errs)
+}
+
+// holtWinters is the state of an additive Holt-Winters model after
+// smoothing a series of length n.
+type holtWinters struct {
+	n            int
+	level, trend float64
+	season       []float64
+	errs         []float64 // One-step-ahead errors
+	sse          float64
+}
+
+// fitHoltWinters smooths v with the specified level, trend and
+// seasonal smoothing parameters. The initial level and trend are
+// taken from the means of the first two seasons.
+func fitHoltWinters(v []float64, alpha, beta, gamma float64) *holtWinters {
+	const m = forecastSeason
+	hw := &holtWinters{n: len(v), season: make([]float64, m)}
+	first, second := mean(v[:m]), mean(v[m:2*m])
+	hw.level, hw.trend = first, (second-first)/m
+	for i := 0; i < m; i++ {
+		hw.season[i] = v[i] - first
+	}
+	for t := m; t < len(v); t++ {
+		s := hw.season[t%m]
+		trend := forecastDamping * hw.trend
+		e := v[t] - (hw.level + trend + s)
+		hw.errs = append(hw.errs, e)
+		hw.sse += e * e
+		level := alpha*(v[t]-s) + (1-alpha)*(hw.level+trend)
+		hw.trend = beta*(level-hw.level) + (1-beta)*trend
+		hw.season[t%m] = gamma*(v[t]-level) + (1-gamma)*s
+		hw.level = level
+	}
+	return hw
+}
+
+// forecast returns the forecast k steps past the end of the series.
+func (hw *holtWinters) forecast(k int) float64 {
+	// Sum of forecastDamping^i for i in [1, k].
+	damped := forecastDamping * (1 - math.Pow(forecastDamping, float64(k))) / (1 - forecastDamping)
+	return hw.level + damped*hw.trend + hw.season[(hw.n-1+k)%forecastSeason]
+}
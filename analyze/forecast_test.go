@@ -0,0 +1,58 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"testing"
+	"time"
+)
+
+func TestForecastStartsAsOf(t *testing.T) {
+	ny, err := NewResampler(GranularityDaily, "America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %s", err)
+	}
+	for _, r := range []*Resampler{nil, ny} {
+		loc := r.DayLocation()
+		start := time.Date(2016, 1, 1, 0, 0, 0, 0, loc)
+		cum := &CumulativeStarsResult{}
+		for i := 0; i < 60; i++ {
+			cum.Days = append(cum.Days, &CumulativeStarsDay{Date: start.AddDate(0, 0, i), New: 2, Cumulative: 2 * (i + 1)})
+		}
+		// 22:00 in New York is the next day in UTC; days in New York
+		// span the change to daylight saving time.
+		asOf := start.AddDate(0, 0, 89).Add(22 * time.Hour)
+		res, _ := Forecast(cum, asOf, r)
+		if len(res.Horizons) != len(ForecastHorizons) {
+			t.Fatalf("%s: expected %d horizons; got %d", loc, len(ForecastHorizons), len(res.Horizons))
+		}
+		if exp := start.AddDate(0, 0, 89); !res.Start.Equal(exp) {
+			t.Errorf("%s: expected forecast to start %s; got %s", loc, exp, res.Start)
+		}
+		if res.Current != 120 {
+			t.Errorf("%s: expected current count 120; got %.0f", loc, res.Current)
+		}
+		for i, p := range res.Horizons {
+			if exp := res.Start.AddDate(0, 0, ForecastHorizons[i]); !p.Date.Equal(exp) {
+				t.Errorf("%s: horizon %d: expected date %s; got %s", loc, p.Horizon, exp, p.Date)
+			}
+			if p.Lower95 < res.Current {
+				t.Errorf("%s: horizon %d: lower bound %.0f below current count", loc, p.Horizon, p.Lower95)
+			}
+		}
+	}
+}
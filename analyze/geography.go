@@ -0,0 +1,276 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+	"github.com/spencerkimball/stargazers/geo"
+)
+
+// unknownCountry is reported for stargazers whose location could
+// not be normalized.
+const unknownCountry = "Unknown"
+
+// StargazerLocation is a stargazer's free-text location and the
+// place to which it was normalized.
+type StargazerLocation struct {
+	Login     string `json:"login"`
+	Location  string `json:"location"`
+	StarredAt string `json:"starred_at"`
+	geo.Place
+}
+
+// LocationsResult is the result of the location normalization.
+type LocationsResult struct {
+	Stargazers []*StargazerLocation `json:"stargazers"`
+}
+
+func (r *LocationsResult) Name() string { return "locations" }
+
+func (r *LocationsResult) Header() []string {
+	return []string{"Login", "Location", "City", "Region", "Country", "Country Code", "Confidence"}
+}
+
+func (r *LocationsResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Stargazers))
+	for _, s := range r.Stargazers {
+		rows = append(rows, []string{s.Login, s.Location, s.City, s.Region, s.Country, s.CountryCode,
+			fmt.Sprintf("%.2f", s.Confidence)})
+	}
+	return rows
+}
+
+func (r *LocationsResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Stargazers))
+	for i, s := range r.Stargazers {
+		recs[i] = s
+	}
+	return recs
+}
+
+// GeoJSON writes a GeoJSON FeatureCollection with a point feature for
+// each distinct place, weighted by its count of stargazers. Places
+// are located at their city, or failing that at the centroid of their
+// region or country.
+func (r *LocationsResult) GeoJSON(w io.Writer) error {
+	byPlace := map[string]*geoFeature{}
+	var features []*geoFeature
+	for _, s := range r.Stargazers {
+		if s.Confidence == 0 {
+			continue
+		}
+		key := s.CountryCode + "/" + s.Region + "/" + s.City
+		f, ok := byPlace[key]
+		if !ok {
+			f = &geoFeature{
+				Type:     "Feature",
+				Geometry: geoGeometry{Type: "Point", Coordinates: [2]float64{s.Lon, s.Lat}},
+				Properties: &geoProperties{Name: s.Place.Name(), City: s.City, Region: s.Region,
+					Country: s.Country, CountryCode: s.CountryCode},
+			}
+			byPlace[key] = f
+			features = append(features, f)
+		}
+		f.Properties.Stargazers++
+		f.Properties.Confidence += s.Confidence
+	}
+	for _, f := range features {
+		f.Properties.Confidence = math.Round(100*f.Properties.Confidence/float64(f.Properties.Stargazers)) / 100
+	}
+	sort.Stable(byPlaceStargazers(features))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Type     string        `json:"type"`
+		Features []*geoFeature `json:"features"`
+	}{"FeatureCollection", features})
+}
+
+type geoProperties struct {
+	Name        string  `json:"name"`
+	City        string  `json:"city,omitempty"`
+	Region      string  `json:"region,omitempty"`
+	Country     string  `json:"country"`
+	CountryCode string  `json:"country_code"`
+	Stargazers  int     `json:"stargazers"`
+	Confidence  float64 `json:"confidence"` // Mean over stargazers
+}
+
+type geoGeometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"` // Longitude, latitude
+}
+
+type geoFeature struct {
+	Type       string         `json:"type"`
+	Geometry   geoGeometry    `json:"geometry"`
+	Properties *geoProperties `json:"properties"`
+}
+
+// GeographyMonth holds the stars from a country in a month.
+type GeographyMonth struct {
+	Month       string  `json:"month"` // Period; see Resampler.Period
+	Country     string  `json:"country"`
+	CountryCode string  `json:"country_code"`
+	NewStars    int     `json:"new_stars"`
+	Cumulative  int     `json:"cumulative"`
+	Share       float64 `json:"share"` // Percent of the month's new stars
+}
+
+// GeographyResult is the result of the geography analysis.
+type GeographyResult struct {
+	Months []*GeographyMonth `json:"months"`
+}
+
+func (r *GeographyResult) Name() string { return "geography" }
+
+func (r *GeographyResult) Header() []string {
+	return []string{"Month", "Country", "Country Code", "New Stars", "Cumulative", "Share %"}
+}
+
+func (r *GeographyResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Months))
+	for _, m := range r.Months {
+		rows = append(rows, []string{m.Month, m.Country, m.CountryCode, strconv.Itoa(m.NewStars),
+			strconv.Itoa(m.Cumulative), fmt.Sprintf("%.1f", m.Share)})
+	}
+	return rows
+}
+
+func (r *GeographyResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Months))
+	for i, m := range r.Months {
+		recs[i] = m
+	}
+	return recs
+}
+
+// Geography normalizes each stargazer's location with the offline
+// gazetteer, then counts new and cumulative stars by country for
+// each period of r (by default, each UTC month) in which the
+// country's stargazers starred the repo. Stargazers without a recognized location are counted under
+// unknownCountry.
+func Geography(sg []*fetch.Stargazer, r *Resampler) (*LocationsResult, *GeographyResult, error) {
+	log.Printf("running geography analysis")
+	locs := &LocationsResult{}
+	type key struct{ month, code string }
+	counts := map[key]int{}
+	names := map[string]string{}
+	monthTotals := map[string]int{}
+	var months []string
+	for _, s := range sg {
+		l := &StargazerLocation{Login: s.Login, Location: s.Location, StarredAt: s.StarredAt, Place: geo.Normalize(s.Location)}
+		locs.Stargazers = append(locs.Stargazers, l)
+		t, err := time.Parse(time.RFC3339, s.StarredAt)
+		if err != nil {
+			return nil, nil, err
+		}
+		month := r.Period(t)
+		if _, ok := monthTotals[month]; !ok {
+			months = append(months, month)
+		}
+		monthTotals[month]++
+		names[l.CountryCode] = l.Country
+		counts[key{month, l.CountryCode}]++
+	}
+	names[""] = unknownCountry
+	sort.Strings(months)
+
+	res := &GeographyResult{}
+	cumulative := map[string]int{}
+	for _, month := range months {
+		var rows []*GeographyMonth
+		for k, n := range counts {
+			if k.month != month {
+				continue
+			}
+			cumulative[k.code] += n
+			rows = append(rows, &GeographyMonth{
+				Month: month, Country: names[k.code], CountryCode: k.code, NewStars: n,
+				Share: percent(n, monthTotals[month]),
+			})
+		}
+		for _, r := range rows {
+			r.Cumulative = cumulative[r.CountryCode]
+		}
+		sort.Sort(byNewStars(rows))
+		res.Months = append(res.Months, rows...)
+	}
+	return locs, res, nil
+}
+
+// WriteGeoJSON writes the GeoJSON map of stargazer locations to
+// geography.geojson in the repo's cache directory.
+func WriteGeoJSON(c *Context, locs *LocationsResult) error {
+	f, err := createFile(c, "geography.geojson")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %s", err)
+	}
+	defer f.Close()
+	if err := locs.GeoJSON(f); err != nil {
+		return err
+	}
+	log.Printf("wrote geography map to %s", f.Name())
+	return nil
+}
+
+// byNewStars sorts countries by descending new stars, then by name.
+type byNewStars []*GeographyMonth
+
+func (slice byNewStars) Len() int {
+	return len(slice)
+}
+
+func (slice byNewStars) Less(i, j int) bool {
+	if slice[i].NewStars != slice[j].NewStars {
+		return slice[i].NewStars > slice[j].NewStars
+	}
+	return slice[i].Country < slice[j].Country
+}
+
+func (slice byNewStars) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// byPlaceStargazers sorts map features by descending count of
+// stargazers, then by name.
+type byPlaceStargazers []*geoFeature
+
+func (slice byPlaceStargazers) Len() int {
+	return len(slice)
+}
+
+func (slice byPlaceStargazers) Less(i, j int) bool {
+	pi, pj := slice[i].Properties, slice[j].Properties
+	if pi.Stargazers != pj.Stargazers {
+		return pi.Stargazers > pj.Stargazers
+	}
+	return pi.Name < pj.Name
+}
+
+func (slice byPlaceStargazers) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
@@ -0,0 +1,140 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+var update = flag.Bool("update", false, "update the golden files of testdata/golden and testdata/golden_legacy")
+
+// TestGolden runs all analyses over the stargazers saved in
+// testdata/saved_state, as of a fixed time, with weekly periods and a
+// minimum support of two, and compares each output file with its
+// golden copy in testdata/golden. Run with -update to rewrite the
+// golden files after an intended change in output.
+func TestGolden(t *testing.T) {
+	resampler, err := NewResampler(GranularityWeekly, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runGolden(t, filepath.Join("testdata", "golden"), Options{
+		MinSupport: 2,
+		Resampler:  resampler,
+		AsOf:       time.Date(2016, 3, 15, 0, 0, 0, 0, time.UTC),
+	})
+}
+
+// TestGoldenLegacy is TestGolden with the default options, including
+// the legacy preset, and golden files in testdata/golden_legacy.
+func TestGoldenLegacy(t *testing.T) {
+	// The legacy preset formats dates in local time.
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local = time.UTC
+	runGolden(t, filepath.Join("testdata", "golden_legacy"), Options{
+		AsOf: time.Date(2016, 3, 15, 0, 0, 0, 0, time.UTC),
+	})
+}
+
+// runGolden runs all analyses with the options over the stargazers
+// saved in testdata/saved_state and compares each output file with
+// its golden copy in goldenDir, or rewrites the golden files with
+// -update.
+func runGolden(t *testing.T, goldenDir string, opts Options) {
+	state, err := ioutil.ReadFile(filepath.Join("testdata", "saved_state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fc := &fetch.Context{Repo: "cockroachdb/cockroach", CacheDir: dir}
+	repoDir := filepath.Join(dir, fc.Repo)
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(repoDir, "saved_state"), state, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sg, rs, err := fetch.LoadState(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Repo = fc.Repo
+	c := &Context{Context: fc, Options: opts}
+	if err := RunAll(c, sg, rs); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := ioutil.ReadDir(repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.RemoveAll(goldenDir); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(goldenDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	written := map[string]bool{}
+	for _, fi := range files {
+		if fi.Name() == "saved_state" {
+			continue
+		}
+		written[fi.Name()] = true
+		got, err := ioutil.ReadFile(filepath.Join(repoDir, fi.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		golden := filepath.Join(goldenDir, fi.Name())
+		if *update {
+			if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Errorf("%s: %s", fi.Name(), err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s differs from its golden file; got:\n%s", fi.Name(), got)
+		}
+	}
+	goldens, err := ioutil.ReadDir(goldenDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fi := range goldens {
+		if !written[fi.Name()] {
+			t.Errorf("%s: golden file was not written", fi.Name())
+		}
+	}
+}
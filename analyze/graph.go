@@ -0,0 +1,304 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"math"
+	"sort"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+// edge is a weighted edge to another node in a graph.
+type edge struct {
+	to int
+	w  float64
+}
+
+// followerGraph is the graph of stargazers and their followers. Nodes
+// are indexed in order of login, so that algorithms which visit nodes
+// in index order are deterministic.
+type followerGraph struct {
+	logins []string
+	index  map[string]int
+	// stargazer is non-nil for nodes which are stargazers.
+	stargazer []*fetch.Stargazer
+	// out holds directed edges from follower to followed.
+	out [][]edge
+}
+
+// newFollowerGraph builds the follower graph from the follower lists
+// of each stargazer and, where they were fetched, the lists of users
+// each stargazer follows. Users who aren't themselves stargazers are
+// included as nodes. All edges have weight one.
+func newFollowerGraph(sg []*fetch.Stargazer) *followerGraph {
+	byLogin := map[string]*fetch.Stargazer{}
+	for _, s := range sg {
+		byLogin[s.Login] = s
+	}
+	for _, s := range sg {
+		for _, list := range [][]*fetch.User{s.Followers, s.Following} {
+			for _, u := range list {
+				if _, ok := byLogin[u.Login]; !ok {
+					byLogin[u.Login] = nil
+				}
+			}
+		}
+	}
+	g := &followerGraph{index: map[string]int{}}
+	for login := range byLogin {
+		g.logins = append(g.logins, login)
+	}
+	sort.Strings(g.logins)
+	g.stargazer = make([]*fetch.Stargazer, len(g.logins))
+	g.out = make([][]edge, len(g.logins))
+	for i, login := range g.logins {
+		g.index[login] = i
+		g.stargazer[i] = byLogin[login]
+	}
+	// A relationship may appear in both a follower and a following
+	// list; add each edge only once.
+	seen := map[[2]int]struct{}{}
+	addEdge := func(from, to int) {
+		if from == to {
+			return
+		}
+		if _, ok := seen[[2]int{from, to}]; ok {
+			return
+		}
+		seen[[2]int{from, to}] = struct{}{}
+		g.out[from] = append(g.out[from], edge{to: to, w: 1})
+	}
+	for _, s := range sg {
+		i := g.index[s.Login]
+		for _, f := range s.Followers {
+			addEdge(g.index[f.Login], i)
+		}
+		for _, f := range s.Following {
+			addEdge(i, g.index[f.Login])
+		}
+	}
+	return g
+}
+
+// undirected returns a symmetric adjacency list, in which each
+// directed edge contributes its weight in both directions.
+func (g *followerGraph) undirected() [][]edge {
+	adj := make([][]edge, len(g.out))
+	for from, es := range g.out {
+		for _, e := range es {
+			adj[from] = append(adj[from], e)
+			adj[e.to] = append(adj[e.to], edge{to: from, w: e.w})
+		}
+	}
+	return adj
+}
+
+// pageRank computes the weighted, personalized PageRank of each node
+// with the specified damping factor. Rank flows along edges from
+// follower to followed, in proportion to edge weight. Random jumps,
+// and the rank of nodes without out edges, land on stargazers with
+// stargazerWeight times the probability of other nodes, so that
+// stargazers hold more rank to pass on to those they follow. Iterates
+// until the L1 change in ranks falls below tolerance, or
+// maxIterations.
+func (g *followerGraph) pageRank(damping, stargazerWeight float64) []float64 {
+	const (
+		tolerance     = 1e-10
+		maxIterations = 100
+	)
+	n := len(g.out)
+	if n == 0 {
+		return nil
+	}
+	outWeight := make([]float64, n)
+	for i, es := range g.out {
+		for _, e := range es {
+			outWeight[i] += e.w
+		}
+	}
+	// The personalization vector, over which random jumps land.
+	jump := make([]float64, n)
+	total := 0.0
+	for i := range jump {
+		jump[i] = 1
+		if g.stargazer[i] != nil {
+			jump[i] = stargazerWeight
+		}
+		total += jump[i]
+	}
+	rank := make([]float64, n)
+	next := make([]float64, n)
+	for i := range rank {
+		jump[i] /= total
+		rank[i] = 1 / float64(n)
+	}
+	for iter := 0; iter < maxIterations; iter++ {
+		dangling := 0.0
+		for i := range rank {
+			if outWeight[i] == 0 {
+				dangling += rank[i]
+			}
+		}
+		base := (1 - damping) + damping*dangling
+		for i := range next {
+			next[i] = base * jump[i]
+		}
+		for i, es := range g.out {
+			for _, e := range es {
+				next[e.to] += damping * rank[i] * e.w / outWeight[i]
+			}
+		}
+		delta := 0.0
+		for i := range rank {
+			delta += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if delta < tolerance {
+			break
+		}
+	}
+	return rank
+}
+
+// louvain partitions an undirected graph into communities using the
+// Louvain method: nodes are greedily moved to the neighboring
+// community yielding the greatest gain in modularity until no move
+// improves it, after which each community is collapsed into a single
+// node and the process repeats. Returns the community of each node,
+// numbered from zero.
+func louvain(adj [][]edge) []int {
+	// comm maps each original node to its node in the current level.
+	comm := make([]int, len(adj))
+	for i := range comm {
+		comm[i] = i
+	}
+	for {
+		level, moved := louvainLevel(adj)
+		if !moved {
+			return renumber(comm)
+		}
+		// Renumber the level's communities and collapse the graph.
+		level = renumber(level)
+		for i := range comm {
+			comm[i] = level[comm[i]]
+		}
+		n := 0
+		for _, c := range level {
+			if c+1 > n {
+				n = c + 1
+			}
+		}
+		weights := make([]map[int]float64, n)
+		for i := range weights {
+			weights[i] = map[int]float64{}
+		}
+		for i, es := range adj {
+			for _, e := range es {
+				weights[level[i]][level[e.to]] += e.w
+			}
+		}
+		adj = make([][]edge, n)
+		for i, ws := range weights {
+			var tos []int
+			for to := range ws {
+				tos = append(tos, to)
+			}
+			sort.Ints(tos)
+			for _, to := range tos {
+				adj[i] = append(adj[i], edge{to: to, w: ws[to]})
+			}
+		}
+	}
+}
+
+// louvainLevel performs the node-moving phase of the Louvain method
+// for a single level. Returns each node's community and whether any
+// node was moved.
+func louvainLevel(adj [][]edge) ([]int, bool) {
+	n := len(adj)
+	comm := make([]int, n)
+	k := make([]float64, n)   // weighted degree of each node
+	tot := make([]float64, n) // total degree of each community
+	m2 := 0.0                 // twice the total edge weight
+	for i, es := range adj {
+		comm[i] = i
+		for _, e := range es {
+			k[i] += e.w
+		}
+		tot[i] = k[i]
+		m2 += k[i]
+	}
+	if m2 == 0 {
+		return comm, false
+	}
+
+	const epsilon = 1e-12
+	moved := false
+	neighborWeight := make([]float64, n)
+	for improved := true; improved; {
+		improved = false
+		for i, es := range adj {
+			// Sum edge weights to each neighboring community.
+			var neighbors []int
+			for _, e := range es {
+				if e.to == i {
+					continue
+				}
+				c := comm[e.to]
+				if neighborWeight[c] == 0 {
+					neighbors = append(neighbors, c)
+				}
+				neighborWeight[c] += e.w
+			}
+			cur := comm[i]
+			tot[cur] -= k[i]
+			best, bestGain := cur, neighborWeight[cur]-tot[cur]*k[i]/m2
+			for _, c := range neighbors {
+				if gain := neighborWeight[c] - tot[c]*k[i]/m2; gain > bestGain+epsilon {
+					best, bestGain = c, gain
+				}
+			}
+			tot[best] += k[i]
+			comm[i] = best
+			if best != cur {
+				improved, moved = true, true
+			}
+			for _, c := range neighbors {
+				neighborWeight[c] = 0
+			}
+			neighborWeight[cur] = 0
+		}
+	}
+	return comm, moved
+}
+
+// renumber maps community labels onto consecutive integers from zero,
+// in order of first appearance.
+func renumber(comm []int) []int {
+	ids := map[int]int{}
+	out := make([]int, len(comm))
+	for i, c := range comm {
+		id, ok := ids[c]
+		if !ok {
+			id = len(ids)
+			ids[c] = id
+		}
+		out[i] = id
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"math"
+	"testing"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+// makeStargazer returns a stargazer with the specified followers.
+func makeStargazer(login string, followers ...string) *fetch.Stargazer {
+	s := &fetch.Stargazer{User: fetch.User{Login: login}}
+	for _, f := range followers {
+		s.Followers = append(s.Followers, &fetch.User{Login: f})
+	}
+	return s
+}
+
+func TestPageRankSumsToOne(t *testing.T) {
+	sg := []*fetch.Stargazer{
+		makeStargazer("a", "x", "y", "b"),
+		makeStargazer("b", "a"),
+		makeStargazer("c"),
+	}
+	g := newFollowerGraph(sg)
+	for _, w := range []float64{1, 10} {
+		sum := 0.0
+		for _, r := range g.pageRank(pageRankDamping, w) {
+			sum += r
+		}
+		if math.Abs(sum-1) > 1e-6 {
+			t.Errorf("weight %g: expected ranks to sum to 1; got %g", w, sum)
+		}
+	}
+}
+
+func TestInfluenceStargazerJumpWeight(t *testing.T) {
+	// "a" is followed by three users who aren't stargazers; "b" by two
+	// stargazers. Unweighted, "a" has more influence; weighting
+	// stargazers favors "b".
+	sg := []*fetch.Stargazer{
+		makeStargazer("a", "x1", "x2", "x3"),
+		makeStargazer("b", "c", "d"),
+		makeStargazer("c"),
+		makeStargazer("d"),
+	}
+	for _, test := range []struct {
+		weight float64
+		first  string
+	}{
+		{1, "a"},
+		{10, "b"},
+	} {
+		res := Influence(sg, Options{StargazerJumpWeight: test.weight})
+		if first := res.Stargazers[0].Login; first != test.first {
+			t.Errorf("weight %g: expected %q to rank first; got %q", test.weight, test.first, first)
+		}
+	}
+}
+
+func TestLouvain(t *testing.T) {
+	// Two triangles joined by a single edge form two communities.
+	var adj [][]edge
+	link := func(a, b int) {
+		for len(adj) <= a || len(adj) <= b {
+			adj = append(adj, nil)
+		}
+		adj[a] = append(adj[a], edge{to: b, w: 1})
+		adj[b] = append(adj[b], edge{to: a, w: 1})
+	}
+	link(0, 1)
+	link(1, 2)
+	link(2, 0)
+	link(3, 4)
+	link(4, 5)
+	link(5, 3)
+	link(2, 3)
+	comm := louvain(adj)
+	expected := []int{0, 0, 0, 1, 1, 1}
+	for i := range expected {
+		if comm[i] != expected[i] {
+			t.Fatalf("expected communities %v; got %v", expected, comm)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+// pageRankDamping is the PageRank damping factor.
+const pageRankDamping = 0.85
+
+// StargazerInfluence holds a stargazer's PageRank in the follower
+// graph, alongside raw follower counts.
+type StargazerInfluence struct {
+	Rank  int    `json:"rank"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+	// Influence is the stargazer's PageRank scaled by the number of
+	// nodes in the graph, so that the average influence is one.
+	Influence float64 `json:"influence"`
+	Followers int     `json:"followers"`
+	// StargazerFollowers is the count of followers who are stargazers.
+	StargazerFollowers int `json:"stargazer_followers"`
+}
+
+// InfluenceResult is the result of the influence analysis.
+type InfluenceResult struct {
+	Stargazers []*StargazerInfluence `json:"stargazers"`
+	// ByLogin maps each stargazer's login to its influence.
+	ByLogin map[string]float64 `json:"-"`
+}
+
+func (r *InfluenceResult) Name() string { return "influence" }
+
+func (r *InfluenceResult) Header() []string {
+	return []string{"Rank", "Login", "Name", "Influence", "Followers", "Stargazer Followers"}
+}
+
+func (r *InfluenceResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Stargazers))
+	for _, s := range r.Stargazers {
+		rows = append(rows, []string{strconv.Itoa(s.Rank), s.Login, s.Name, fmt.Sprintf("%.4f", s.Influence),
+			strconv.Itoa(s.Followers), strconv.Itoa(s.StargazerFollowers)})
+	}
+	return rows
+}
+
+func (r *InfluenceResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Stargazers))
+	for i, s := range r.Stargazers {
+		recs[i] = s
+	}
+	return recs
+}
+
+// Influence ranks stargazers by PageRank over the graph of follower
+// relationships among stargazers, their followers and (if fetched)
+// the users they follow. Random jumps favor stargazers by
+// opts.StargazerJumpWeight, so that endorsement from within the
+// community counts for more than from without.
+func Influence(sg []*fetch.Stargazer, opts Options) *InfluenceResult {
+	log.Printf("running influence analysis")
+	weight := opts.StargazerJumpWeight
+	if weight == 0 {
+		weight = 1
+	}
+	g := newFollowerGraph(sg)
+	rank := g.pageRank(pageRankDamping, weight)
+
+	res := &InfluenceResult{ByLogin: map[string]float64{}}
+	for i, s := range g.stargazer {
+		if s == nil {
+			continue
+		}
+		inf := &StargazerInfluence{
+			Login:     s.Login,
+			Name:      s.Name,
+			Influence: rank[i] * float64(len(rank)),
+			Followers: s.User.Followers,
+		}
+		for _, f := range s.Followers {
+			if g.stargazer[g.index[f.Login]] != nil {
+				inf.StargazerFollowers++
+			}
+		}
+		res.Stargazers = append(res.Stargazers, inf)
+		res.ByLogin[s.Login] = inf.Influence
+	}
+	sort.Sort(byInfluence(res.Stargazers))
+	for i, s := range res.Stargazers {
+		s.Rank = i + 1
+	}
+	return res
+}
+
+// byInfluence sorts by descending influence, breaking ties by login.
+type byInfluence []*StargazerInfluence
+
+func (slice byInfluence) Len() int {
+	return len(slice)
+}
+
+func (slice byInfluence) Less(i, j int) bool {
+	if slice[i].Influence != slice[j].Influence {
+		return slice[i].Influence > slice[j].Influence /* descending order */
+	}
+	return slice[i].Login < slice[j].Login
+}
+
+func (slice byInfluence) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
@@ -0,0 +1,384 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spencerkimball/stargazers/fetch"
+	"github.com/spencerkimball/stargazers/geo"
+	"github.com/spencerkimball/stargazers/segment"
+)
+
+const (
+	// defaultShortlistSize is the number of leads on each shortlist,
+	// unless configured.
+	defaultShortlistSize = 25
+	// nLeadLanguages is the number of languages reported in each
+	// lead's language profile.
+	nLeadLanguages = 3
+)
+
+// Lead scoring factors, each scored from zero to one.
+const (
+	FactorHireable  = "hireable"  // Marked hireable on GitHub
+	FactorCommits   = "commits"   // Commits to subscribed repos, log scaled
+	FactorInfluence = "influence" // Follower graph PageRank, log scaled
+	FactorLocation  = "location"  // Located in a preferred country
+	FactorRole      = "role"      // Classified into a preferred role
+	FactorLanguage  = "language"  // Share of language profile in preferred languages
+)
+
+// LeadFactors lists the lead scoring factors in output order.
+var LeadFactors = []string{FactorHireable, FactorCommits, FactorInfluence, FactorLocation, FactorRole, FactorLanguage}
+
+// DefaultLeadWeights are the weights of the lead scoring factors.
+var DefaultLeadWeights = map[string]float64{
+	FactorHireable:  1,
+	FactorCommits:   2,
+	FactorInfluence: 2,
+	FactorLocation:  0.5,
+	FactorRole:      1,
+	FactorLanguage:  1,
+}
+
+// DefaultShortlists are the lead shortlists, by name, unless
+// configured. Each is a segment expression (see package segment).
+var DefaultShortlists = map[string]string{
+	"hireable":    "hireable",
+	"committers":  "commits > 0",
+	"influencers": "followers >= 100",
+}
+
+// LeadScoring configures the lead scoring analysis.
+type LeadScoring struct {
+	// Weights of the scoring factors; DefaultLeadWeights for factors
+	// not specified.
+	Weights map[string]float64 `json:"weights"`
+	// Countries preferred for the location factor, as names or ISO
+	// codes; if empty, any recognized location scores.
+	Countries []string `json:"countries"`
+	// Roles preferred for the role factor; if empty, any role but
+	// unclassified scores.
+	Roles []string `json:"roles"`
+	// Languages preferred for the language factor; if empty, the
+	// language of the analyzed repo, if known.
+	Languages []string `json:"languages"`
+	// Shortlists map names to segment expressions selecting the
+	// leads on each; DefaultShortlists if empty.
+	Shortlists map[string]string `json:"shortlists"`
+	// ShortlistSize is the number of leads on each shortlist;
+	// defaultShortlistSize if zero.
+	ShortlistSize int `json:"shortlist_size"`
+}
+
+// Lead is a stargazer scored as a lead for outreach or recruiting.
+type Lead struct {
+	Rank      int                `json:"rank"`
+	Login     string             `json:"login"`
+	Name      string             `json:"name"`
+	Email     string             `json:"email"`
+	Score     float64            `json:"score"`   // 0-100
+	Factors   map[string]float64 `json:"factors"` // Weighted contribution of each factor to the score
+	Country   string             `json:"country"`
+	Role      string             `json:"role"`
+	Languages []string           `json:"languages"` // Most common in starred and contributed repos
+}
+
+// LeadsResult is the ranked list of leads.
+type LeadsResult struct {
+	Weights map[string]float64 `json:"weights"`
+	Leads   []*Lead            `json:"leads"`
+}
+
+func (r *LeadsResult) Name() string { return "leads" }
+
+func (r *LeadsResult) Header() []string {
+	header := []string{"Rank", "Login", "Name", "Email", "Score"}
+	for _, f := range LeadFactors {
+		header = append(header, strings.Title(f)+" Score")
+	}
+	return append(header, "Country", "Role", "Languages")
+}
+
+func (r *LeadsResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Leads))
+	for _, l := range r.Leads {
+		row := []string{strconv.Itoa(l.Rank), l.Login, l.Name, l.Email, fmt.Sprintf("%.1f", l.Score)}
+		for _, f := range LeadFactors {
+			row = append(row, fmt.Sprintf("%.1f", l.Factors[f]))
+		}
+		rows = append(rows, append(row, l.Country, l.Role, strings.Join(l.Languages, ";")))
+	}
+	return rows
+}
+
+func (r *LeadsResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Leads))
+	for i, l := range r.Leads {
+		recs[i] = l
+	}
+	return recs
+}
+
+// ShortlistEntry is a lead on a shortlist.
+type ShortlistEntry struct {
+	Shortlist string  `json:"shortlist"`
+	Rank      int     `json:"rank"` // Within the shortlist
+	Login     string  `json:"login"`
+	Name      string  `json:"name"`
+	Email     string  `json:"email"`
+	Score     float64 `json:"score"`
+}
+
+// ShortlistsResult holds the top leads matching each shortlist's
+// criteria.
+type ShortlistsResult struct {
+	Entries []*ShortlistEntry `json:"entries"`
+}
+
+func (r *ShortlistsResult) Name() string { return "lead_shortlists" }
+
+func (r *ShortlistsResult) Header() []string {
+	return []string{"Shortlist", "Rank", "Login", "Name", "Email", "Score"}
+}
+
+func (r *ShortlistsResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Entries))
+	for _, e := range r.Entries {
+		rows = append(rows, []string{e.Shortlist, strconv.Itoa(e.Rank), e.Login, e.Name, e.Email, fmt.Sprintf("%.1f", e.Score)})
+	}
+	return rows
+}
+
+func (r *ShortlistsResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Entries))
+	for i, e := range r.Entries {
+		recs[i] = e
+	}
+	return recs
+}
+
+// Leads scores each stargazer as a lead by the weighted factors of
+// opts.LeadScoring (defaults if nil): whether they're hireable, their
+// commits, their influence, and whether their location, role (from
+// roles) and language profile match the preferences. The language
+// profile counts the languages of the repos a stargazer starred or
+// contributed to. Scores are the weighted mean of the factors, scaled
+// to 0-100; each factor's weighted contribution is reported. Returns
+// the ranked leads and the top leads on each shortlist.
+func Leads(sg []*fetch.Stargazer, rs map[string]*fetch.Repo, influence *InfluenceResult,
+	roles *StargazerRolesResult, opts Options) (*LeadsResult, *ShortlistsResult, error) {
+	log.Printf("running lead scoring analysis")
+	config := opts.LeadScoring
+	if config == nil {
+		config = &LeadScoring{}
+	}
+	weights := map[string]float64{}
+	total := 0.0
+	for _, f := range LeadFactors {
+		w, ok := config.Weights[f]
+		if !ok {
+			w = DefaultLeadWeights[f]
+		}
+		weights[f] = w
+		total += w
+	}
+	for f := range config.Weights {
+		if _, ok := DefaultLeadWeights[f]; !ok {
+			return nil, nil, fmt.Errorf("unknown lead scoring factor %q; must be one of %s", f, strings.Join(LeadFactors, ", "))
+		}
+	}
+	if total <= 0 {
+		return nil, nil, fmt.Errorf("lead scoring weights must sum to a positive value")
+	}
+
+	countries := map[string]bool{}
+	for _, c := range config.Countries {
+		countries[strings.ToLower(c)] = true
+	}
+	preferredRoles := map[string]bool{}
+	for _, r := range config.Roles {
+		preferredRoles[r] = true
+	}
+	languages := map[string]bool{}
+	for _, l := range config.Languages {
+		languages[strings.ToLower(l)] = true
+	}
+	if r, ok := rs[opts.Repo]; ok && len(languages) == 0 && len(r.Language) > 0 {
+		languages[strings.ToLower(r.Language)] = true
+	}
+	roleByLogin := map[string]string{}
+	for _, sr := range roles.Stargazers {
+		roleByLogin[sr.Login] = sr.Role
+	}
+
+	maxCommits, maxInfluence := 0.0, 0.0
+	for _, s := range sg {
+		c, _, _ := s.TotalCommits()
+		maxCommits = math.Max(maxCommits, float64(c))
+		maxInfluence = math.Max(maxInfluence, influence.ByLogin[s.Login])
+	}
+	logScale := func(v, max float64) float64 {
+		if max <= 0 {
+			return 0
+		}
+		return math.Log1p(v) / math.Log1p(max)
+	}
+
+	res := &LeadsResult{Weights: weights}
+	byLogin := map[string]*Lead{}
+	for _, s := range sg {
+		place := geo.Normalize(s.Location)
+		l := &Lead{Login: s.Login, Name: s.Name, Email: s.Email, Factors: map[string]float64{},
+			Country: place.Country, Role: roleByLogin[s.Login]}
+		profile, top := languageProfile(s, rs)
+		l.Languages = top
+
+		factors := map[string]float64{}
+		if s.Hireable {
+			factors[FactorHireable] = 1
+		}
+		c, _, _ := s.TotalCommits()
+		factors[FactorCommits] = logScale(float64(c), maxCommits)
+		factors[FactorInfluence] = logScale(influence.ByLogin[s.Login], maxInfluence)
+		if len(countries) == 0 && len(place.Country) > 0 ||
+			countries[strings.ToLower(place.Country)] || countries[strings.ToLower(place.CountryCode)] {
+			factors[FactorLocation] = 1
+		}
+		if len(preferredRoles) == 0 && l.Role != RoleUnclassified || preferredRoles[l.Role] {
+			factors[FactorRole] = 1
+		}
+		matched, all := 0, 0
+		for lang, n := range profile {
+			all += n
+			if languages[lang] {
+				matched += n
+			}
+		}
+		if all > 0 {
+			factors[FactorLanguage] = float64(matched) / float64(all)
+		}
+		for _, f := range LeadFactors {
+			l.Factors[f] = 100 * weights[f] * factors[f] / total
+			l.Score += l.Factors[f]
+		}
+		res.Leads = append(res.Leads, l)
+		byLogin[l.Login] = l
+	}
+	sort.Sort(byLeadScore(res.Leads))
+	for i, l := range res.Leads {
+		l.Rank = i + 1
+	}
+
+	shortlists := config.Shortlists
+	if len(shortlists) == 0 {
+		shortlists = DefaultShortlists
+	}
+	size := config.ShortlistSize
+	if size == 0 {
+		size = defaultShortlistSize
+	}
+	var names []string
+	for name := range shortlists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lists := &ShortlistsResult{}
+	for _, name := range names {
+		expr, err := segment.Parse(shortlists[name], nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid shortlist %q: %s", name, err)
+		}
+		var matched []*Lead
+		for _, s := range segment.Filter(sg, expr, opts.CompanyOverrides) {
+			matched = append(matched, byLogin[s.Login])
+		}
+		sort.Sort(byLeadScore(matched))
+		for i, l := range matched {
+			if i >= size {
+				break
+			}
+			lists.Entries = append(lists.Entries, &ShortlistEntry{
+				Shortlist: name, Rank: i + 1, Login: l.Login, Name: l.Name, Email: l.Email, Score: l.Score,
+			})
+		}
+	}
+	return res, lists, nil
+}
+
+// languageProfile returns the counts of the languages (lower case) of
+// the repos the stargazer starred or contributed to, and the most
+// common nLeadLanguages, as named by GitHub.
+func languageProfile(s *fetch.Stargazer, rs map[string]*fetch.Repo) (map[string]int, []string) {
+	counts := map[string]int{}
+	names := map[string]string{}
+	add := func(name string) {
+		if r, ok := rs[name]; ok && len(r.Language) > 0 {
+			lang := strings.ToLower(r.Language)
+			counts[lang]++
+			names[lang] = r.Language
+		}
+	}
+	for _, name := range s.Starred {
+		add(name)
+	}
+	var contributed []string
+	for name := range s.Contributions {
+		contributed = append(contributed, name)
+	}
+	sort.Strings(contributed)
+	for _, name := range contributed {
+		add(name)
+	}
+	var langs []*RepoCount
+	for lang, n := range counts {
+		langs = append(langs, &RepoCount{name: lang, count: n})
+	}
+	sort.Sort(RepoCounts(langs))
+	top := []string{}
+	for i, l := range langs {
+		if i >= nLeadLanguages {
+			break
+		}
+		top = append(top, names[l.name])
+	}
+	return counts, top
+}
+
+// byLeadScore sorts leads by descending score, then by login.
+type byLeadScore []*Lead
+
+func (slice byLeadScore) Len() int {
+	return len(slice)
+}
+
+func (slice byLeadScore) Less(i, j int) bool {
+	if slice[i].Score != slice[j].Score {
+		return slice[i].Score > slice[j].Score /* descending order */
+	}
+	return slice[i].Login < slice[j].Login
+}
+
+func (slice byLeadScore) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
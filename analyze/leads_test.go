@@ -0,0 +1,111 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+func TestLeads(t *testing.T) {
+	rs := map[string]*fetch.Repo{
+		"owner/repo": {FullName: "owner/repo", Language: "Go"},
+		"go/x":       {FullName: "go/x", Language: "Go"},
+		"py/y":       {FullName: "py/y", Language: "Python"},
+	}
+	ada := &fetch.Stargazer{Starred: []string{"go/x", "py/y"},
+		Contributions: map[string]*fetch.Contribution{"go/x": {Commits: 99}}}
+	ada.Login, ada.Hireable, ada.Location, ada.User.Followers = "ada", true, "Berlin, Germany", 150
+	bob := &fetch.Stargazer{Contributions: map[string]*fetch.Contribution{"go/x": {Commits: 9}}}
+	bob.Login = "bob"
+	sg := []*fetch.Stargazer{bob, ada}
+	influence := &InfluenceResult{ByLogin: map[string]float64{"ada": 0.3, "bob": 0}}
+	roles := &StargazerRolesResult{Stargazers: []*StargazerRole{
+		{Login: "ada", Role: "engineer"}, {Login: "bob", Role: RoleUnclassified}}}
+
+	// With the default weights (summing to 7.5), ada scores every
+	// factor but language, where two of the three repos she starred
+	// or contributed to are in the analyzed repo's language. Bob's 9
+	// commits log scale against ada's 99 to log(10)/log(100) = 0.5.
+	testCases := []struct {
+		name    string
+		config  *LeadScoring
+		ada     map[string]float64
+		bob     map[string]float64
+		success bool
+	}{
+		{"defaults", nil,
+			map[string]float64{FactorHireable: 13.333333, FactorCommits: 26.666667, FactorInfluence: 26.666667,
+				FactorLocation: 6.666667, FactorRole: 13.333333, FactorLanguage: 8.888889},
+			map[string]float64{FactorCommits: 13.333333, FactorLanguage: 13.333333}, true},
+		{"countries", &LeadScoring{Countries: []string{"us"}},
+			map[string]float64{FactorHireable: 13.333333, FactorCommits: 26.666667, FactorInfluence: 26.666667,
+				FactorRole: 13.333333, FactorLanguage: 8.888889},
+			map[string]float64{FactorCommits: 13.333333, FactorLanguage: 13.333333}, true},
+		{"languages", &LeadScoring{Languages: []string{"Python", "go"}},
+			map[string]float64{FactorHireable: 13.333333, FactorCommits: 26.666667, FactorInfluence: 26.666667,
+				FactorLocation: 6.666667, FactorRole: 13.333333, FactorLanguage: 13.333333},
+			map[string]float64{FactorCommits: 13.333333, FactorLanguage: 13.333333}, true},
+		// Weights now sum to 9.5.
+		{"weights", &LeadScoring{Weights: map[string]float64{FactorHireable: 3}},
+			map[string]float64{FactorHireable: 31.578947, FactorCommits: 21.052632, FactorInfluence: 21.052632,
+				FactorLocation: 5.263158, FactorRole: 10.526316, FactorLanguage: 7.017544},
+			map[string]float64{FactorCommits: 10.526316, FactorLanguage: 10.526316}, true},
+		{"unknown factor", &LeadScoring{Weights: map[string]float64{"stars": 1}}, nil, nil, false},
+		{"zero weights", &LeadScoring{Weights: map[string]float64{FactorHireable: 0, FactorCommits: 0,
+			FactorInfluence: 0, FactorLocation: 0, FactorRole: 0, FactorLanguage: 0}}, nil, nil, false},
+	}
+	for _, tc := range testCases {
+		leads, lists, err := Leads(sg, rs, influence, roles, Options{Repo: "owner/repo", LeadScoring: tc.config})
+		if (err == nil) != tc.success {
+			t.Errorf("%s: expected success %t; got error %v", tc.name, tc.success, err)
+			continue
+		}
+		if !tc.success {
+			continue
+		}
+		if len(leads.Leads) != 2 || leads.Leads[0].Login != "ada" || leads.Leads[0].Rank != 1 || leads.Leads[1].Rank != 2 {
+			t.Errorf("%s: expected ada ranked above bob; got %+v", tc.name, leads.Leads)
+			continue
+		}
+		for i, expected := range []map[string]float64{tc.ada, tc.bob} {
+			l := leads.Leads[i]
+			score := 0.0
+			for _, f := range LeadFactors {
+				score += expected[f]
+				if math.Abs(l.Factors[f]-expected[f]) > 1e-6 {
+					t.Errorf("%s: expected %s %s %.6f; got %.6f", tc.name, l.Login, f, expected[f], l.Factors[f])
+				}
+			}
+			if math.Abs(l.Score-score) > 1e-5 {
+				t.Errorf("%s: expected %s score %.6f; got %.6f", tc.name, l.Login, score, l.Score)
+			}
+		}
+
+		var entries []string
+		for _, e := range lists.Entries {
+			entries = append(entries, e.Shortlist+":"+e.Login)
+		}
+		expected := "[committers:ada committers:bob hireable:ada influencers:ada]"
+		if s := fmt.Sprint(entries); s != expected {
+			t.Errorf("%s: expected shortlists %s; got %s", tc.name, expected, s)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+// nOwnerTopRepos is the number of top repos listed per owner.
+const nOwnerTopRepos = 5
+
+// CorrelatedOwner rolls up the correlated repos of a single owner, a
+// user or organization, such as all of kubernetes/*.
+type CorrelatedOwner struct {
+	Owner string `json:"owner"`
+	URL   string `json:"url"`
+	// Repos is the number of the owner's repos in stargazers' lists.
+	Repos int `json:"repos"`
+	// Count is the number of occurrences of the owner's repos in
+	// stargazers' lists, summed over repos.
+	Count int `json:"count"`
+	// Stargazers is the number of unique stargazers with at least one
+	// of the owner's repos in their list.
+	Stargazers int `json:"stargazers"`
+	// Committers is the number of stargazers who have contributed to
+	// the owner's repos, and Commits, Additions and Deletions their
+	// total contributions.
+	Committers int          `json:"committers"`
+	Commits    int          `json:"commits"`
+	Additions  int          `json:"additions"`
+	Deletions  int          `json:"deletions"`
+	TopRepos   []*NameCount `json:"top_repos"`
+}
+
+// CorrelatedOwnersResult is the result of the correlated owners
+// analysis for either starred or subscribed repos.
+type CorrelatedOwnersResult struct {
+	ListType   string             `json:"list_type"`
+	MinSupport int                `json:"min_support"`
+	Owners     []*CorrelatedOwner `json:"owners"`
+}
+
+func (r *CorrelatedOwnersResult) Name() string {
+	return fmt.Sprintf("correlated_%s_owners", r.ListType)
+}
+
+func (r *CorrelatedOwnersResult) Header() []string {
+	return []string{"Owner", "URL", "Repos", "Count", "Stargazers", "Committers", "Commits", "Additions", "Deletions",
+		"Top Repos"}
+}
+
+func (r *CorrelatedOwnersResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Owners))
+	for _, o := range r.Owners {
+		rows = append(rows, []string{o.Owner, o.URL, strconv.Itoa(o.Repos), strconv.Itoa(o.Count),
+			strconv.Itoa(o.Stargazers), strconv.Itoa(o.Committers), strconv.Itoa(o.Commits),
+			strconv.Itoa(o.Additions), strconv.Itoa(o.Deletions), joinNameCounts(o.TopRepos)})
+	}
+	return rows
+}
+
+func (r *CorrelatedOwnersResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Owners))
+	for i, o := range r.Owners {
+		recs[i] = o
+	}
+	return recs
+}
+
+// repoOwner returns the owner of a repo from its full name.
+func repoOwner(fullName string) string {
+	if i := strings.Index(fullName, "/"); i >= 0 {
+		return fullName[:i]
+	}
+	return fullName
+}
+
+// CorrelatedOwners rolls up the repos in the starred or subscribed
+// lists of each stargazer by owner, counting the occurrences of each
+// owner's repos, the unique stargazers listing any of them and the
+// stargazers' contributions to them. The analyzed repo is excluded,
+// as every stargazer has starred it. Returns the owners listed by at
+// least opts.MinSupport stargazers, in order of descending
+// stargazers, each with their most listed repos.
+func CorrelatedOwners(listType string, sg []*fetch.Stargazer, opts Options) *CorrelatedOwnersResult {
+	log.Printf("running correlated %s owners analysis", listType)
+	owners := map[string]*CorrelatedOwner{}
+	repoCounts := map[string]map[string]int{} // Owner to repo to count
+	getOwner := func(name string) *CorrelatedOwner {
+		o, ok := owners[name]
+		if !ok {
+			o = &CorrelatedOwner{Owner: name, URL: fmt.Sprintf("https://github.com/%s", name)}
+			owners[name] = o
+			repoCounts[name] = map[string]int{}
+		}
+		return o
+	}
+
+	for _, s := range sg {
+		repos := s.Starred
+		if listType == "subscribed" {
+			repos = s.Subscribed
+		}
+		listed := map[string]struct{}{}
+		for _, rName := range repos {
+			if rName == opts.Repo {
+				continue
+			}
+			o := getOwner(repoOwner(rName))
+			o.Count++
+			repoCounts[o.Owner][rName]++
+			listed[o.Owner] = struct{}{}
+		}
+		for name := range listed {
+			owners[name].Stargazers++
+		}
+		contributed := map[string]struct{}{}
+		for rName, contrib := range s.Contributions {
+			if rName == opts.Repo {
+				continue
+			}
+			o := getOwner(repoOwner(rName))
+			o.Commits += contrib.Commits
+			o.Additions += contrib.Additions
+			o.Deletions += contrib.Deletions
+			contributed[o.Owner] = struct{}{}
+		}
+		for name := range contributed {
+			owners[name].Committers++
+		}
+	}
+
+	res := &CorrelatedOwnersResult{ListType: listType, MinSupport: opts.MinSupport}
+	for name, o := range owners {
+		if o.Stargazers == 0 || o.Stargazers < opts.MinSupport {
+			continue
+		}
+		var repos RepoCounts
+		for rName, count := range repoCounts[name] {
+			repos = append(repos, &RepoCount{name: rName, count: count})
+		}
+		sort.Sort(repos)
+		o.Repos = len(repos)
+		for i, r := range repos {
+			if i >= nOwnerTopRepos {
+				break
+			}
+			o.TopRepos = append(o.TopRepos, &NameCount{Name: r.name, Count: r.count})
+		}
+		res.Owners = append(res.Owners, o)
+	}
+	sort.Sort(byOwnerStargazers(res.Owners))
+	if len(res.Owners) > nMostCorrelated {
+		res.Owners = res.Owners[:nMostCorrelated]
+	}
+	return res
+}
+
+// byOwnerStargazers sorts owners by descending stargazers, breaking
+// ties by descending count and then by owner.
+type byOwnerStargazers []*CorrelatedOwner
+
+func (slice byOwnerStargazers) Len() int {
+	return len(slice)
+}
+
+func (slice byOwnerStargazers) Less(i, j int) bool {
+	if slice[i].Stargazers != slice[j].Stargazers {
+		return slice[i].Stargazers > slice[j].Stargazers /* descending order */
+	}
+	if slice[i].Count != slice[j].Count {
+		return slice[i].Count > slice[j].Count
+	}
+	return slice[i].Owner < slice[j].Owner
+}
+
+func (slice byOwnerStargazers) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
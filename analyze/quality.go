@@ -0,0 +1,310 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+// DefaultSuspicionThreshold is the default score at or above which a
+// star is considered suspicious.
+const DefaultSuspicionThreshold = 0.5
+
+// Suspicion signals and the score each contributes. Scores are
+// summed and capped at one.
+var suspicionSignals = []struct {
+	reason string
+	score  float64
+}{
+	{"created within a day of starring", 0.4},
+	{"created within a week of starring", 0.25},
+	{"created within a month of starring", 0.1},
+	{"no public repos", 0.1},
+	{"no followers", 0.1},
+	{"empty profile", 0.15},
+	{"starred in a cluster", 0.2},
+	{"near-duplicate starred list", 0.3},
+}
+
+const (
+	// starClusterWindow and starClusterSize define a cluster of stars:
+	// at least starClusterSize stars (including this one) within
+	// starClusterWindow either side of a star.
+	starClusterWindow = 5 * time.Minute
+	starClusterSize   = 5
+	// duplicateJaccard is the Jaccard similarity at or above which two
+	// starred lists of at least duplicateMinList repos are considered
+	// near-duplicates.
+	duplicateJaccard = 0.8
+	duplicateMinList = 5
+	// minHashBands and minHashRows configure the locality-sensitive
+	// hashing of starred lists used to find near-duplicate candidates.
+	minHashBands = 16
+	minHashRows  = 4
+)
+
+// StarQuality holds a stargazer's suspicion score and the signals
+// which contributed to it.
+type StarQuality struct {
+	Login      string   `json:"login"`
+	Score      float64  `json:"score"`
+	Suspicious bool     `json:"suspicious"`
+	Reasons    []string `json:"reasons"`
+	StarredAt  string   `json:"starred_at"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// StarQualityResult is the result of the star quality analysis.
+type StarQualityResult struct {
+	Threshold  float64        `json:"threshold"`
+	Suspicious int            `json:"suspicious"`
+	Stargazers []*StarQuality `json:"stargazers"`
+}
+
+func (r *StarQualityResult) Name() string { return "star_quality" }
+
+func (r *StarQualityResult) Header() []string {
+	return []string{"Login", "Score", "Suspicious", "Reasons", "Starred At", "Created At"}
+}
+
+func (r *StarQualityResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Stargazers))
+	for _, q := range r.Stargazers {
+		rows = append(rows, []string{q.Login, fmt.Sprintf("%.2f", q.Score), fmt.Sprintf("%t", q.Suspicious),
+			strings.Join(q.Reasons, "; "), q.StarredAt, q.CreatedAt})
+	}
+	return rows
+}
+
+func (r *StarQualityResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Stargazers))
+	for i, q := range r.Stargazers {
+		recs[i] = q
+	}
+	return recs
+}
+
+// StarQualityScores scores each stargazer by heuristic signals of a
+// fake or low-quality star: an account created shortly before
+// starring, no public repos or followers, an empty profile, a star
+// among a cluster of stars within minutes, and a starred list which
+// is a near-duplicate of another stargazer's. Stargazers scoring at
+// least the threshold are marked suspicious. Returns the scores,
+// sorted from most to least suspicious, along with the cumulative
+// stars series excluding suspicious stargazers, resampled by r.
+func StarQualityScores(sg []*fetch.Stargazer, threshold float64, r *Resampler) (*StarQualityResult, *CumulativeStarsResult, error) {
+	log.Printf("running star quality analysis")
+	if threshold == 0 {
+		threshold = DefaultSuspicionThreshold
+	}
+
+	clustered, err := clusteredStars(sg)
+	if err != nil {
+		return nil, nil, err
+	}
+	duplicates := duplicateStarredLists(sg)
+
+	res := &StarQualityResult{Threshold: threshold}
+	var clean []*fetch.Stargazer
+	for i, s := range sg {
+		var signals []bool
+		age := ageAtStar(s)
+		signals = append(signals,
+			age < 1,
+			age >= 1 && age < 7,
+			age >= 7 && age < 30,
+			s.PublicRepos == 0,
+			s.User.Followers == 0,
+			emptyProfile(s),
+			clustered[i],
+			duplicates[i],
+		)
+		q := &StarQuality{Login: s.Login, StarredAt: s.StarredAt, CreatedAt: s.CreatedAt, Reasons: []string{}}
+		for j, ok := range signals {
+			if ok {
+				q.Score += suspicionSignals[j].score
+				q.Reasons = append(q.Reasons, suspicionSignals[j].reason)
+			}
+		}
+		q.Score = math.Min(1, q.Score)
+		if q.Suspicious = q.Score >= threshold; q.Suspicious {
+			res.Suspicious++
+		} else {
+			clean = append(clean, s)
+		}
+		res.Stargazers = append(res.Stargazers, q)
+	}
+	sort.Sort(byScore(res.Stargazers))
+
+	cleaned, err := CumulativeStars(clean, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleaned.Cleaned = true
+	return res, cleaned, nil
+}
+
+// clusteredStars returns whether each stargazer starred the repo
+// within a cluster of at least starClusterSize stars.
+func clusteredStars(sg []*fetch.Stargazer) ([]bool, error) {
+	stars := make(starTimes, len(sg))
+	for i, s := range sg {
+		t, err := time.Parse(time.RFC3339, s.StarredAt)
+		if err != nil {
+			return nil, err
+		}
+		stars[i] = starTime{i, t}
+	}
+	sort.Sort(stars)
+	clustered := make([]bool, len(sg))
+	lo, hi := 0, 0
+	for _, s := range stars {
+		for stars[lo].t.Before(s.t.Add(-starClusterWindow)) {
+			lo++
+		}
+		for hi < len(stars) && !stars[hi].t.After(s.t.Add(starClusterWindow)) {
+			hi++
+		}
+		clustered[s.i] = hi-lo >= starClusterSize
+	}
+	return clustered, nil
+}
+
+// duplicateStarredLists returns whether each stargazer's starred list
+// is a near-duplicate of another stargazer's. Candidate pairs are
+// found by MinHash locality-sensitive hashing and then verified by
+// computing their exact Jaccard similarity.
+func duplicateStarredLists(sg []*fetch.Stargazer) []bool {
+	const nHashes = minHashBands * minHashRows
+	sets := make([]map[string]struct{}, len(sg))
+	buckets := map[[2]uint64][]int{}
+	for i, s := range sg {
+		if len(s.Starred) < duplicateMinList {
+			continue
+		}
+		sets[i] = map[string]struct{}{}
+		var sig [nHashes]uint64
+		for j := range sig {
+			sig[j] = math.MaxUint64
+		}
+		for _, rName := range s.Starred {
+			sets[i][rName] = struct{}{}
+			h := fnv.New64a()
+			h.Write([]byte(rName))
+			base := h.Sum64()
+			for j := range sig {
+				if v := mix64(base + uint64(j)*0x9e3779b97f4a7c15); v < sig[j] {
+					sig[j] = v
+				}
+			}
+		}
+		for b := 0; b < minHashBands; b++ {
+			h := fnv.New64a()
+			for r := 0; r < minHashRows; r++ {
+				fmt.Fprintf(h, "%d,", sig[b*minHashRows+r])
+			}
+			key := [2]uint64{uint64(b), h.Sum64()}
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+	dup := make([]bool, len(sg))
+	for _, members := range buckets {
+		for a := 0; a < len(members); a++ {
+			for b := a + 1; b < len(members); b++ {
+				i, j := members[a], members[b]
+				if dup[i] && dup[j] {
+					continue
+				}
+				if jaccard(sets[i], sets[j]) >= duplicateJaccard {
+					dup[i], dup[j] = true, true
+				}
+			}
+		}
+	}
+	return dup
+}
+
+// mix64 is the splitmix64 finalizer, used to derive a family of
+// independent hash functions from a single string hash.
+func mix64(x uint64) uint64 {
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	inter := 0
+	for k := range a {
+		if _, ok := b[k]; ok {
+			inter++
+		}
+	}
+	union := len(a) + len(b) - inter
+	if union == 0 {
+		return 0
+	}
+	return float64(inter) / float64(union)
+}
+
+type starTime struct {
+	i int // Index of stargazer
+	t time.Time
+}
+
+// starTimes sorts stars in chronological order.
+type starTimes []starTime
+
+func (slice starTimes) Len() int {
+	return len(slice)
+}
+
+func (slice starTimes) Less(i, j int) bool {
+	if !slice[i].t.Equal(slice[j].t) {
+		return slice[i].t.Before(slice[j].t)
+	}
+	return slice[i].i < slice[j].i
+}
+
+func (slice starTimes) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// byScore sorts by descending score, breaking ties by login.
+type byScore []*StarQuality
+
+func (slice byScore) Len() int {
+	return len(slice)
+}
+
+func (slice byScore) Less(i, j int) bool {
+	if slice[i].Score != slice[j].Score {
+		return slice[i].Score > slice[j].Score /* descending order */
+	}
+	return slice[i].Login < slice[j].Login
+}
+
+func (slice byScore) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
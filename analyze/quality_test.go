@@ -0,0 +1,114 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+func TestStarQualityScores(t *testing.T) {
+	base := time.Date(2016, 3, 1, 12, 0, 0, 0, time.UTC)
+	veteran := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+	// makeUser returns a stargazer who starred at starred and was
+	// created at created; name fills in an otherwise empty profile.
+	makeUser := func(login string, starred, created time.Time, repos, followers int, name string, list ...string) *fetch.Stargazer {
+		s := &fetch.Stargazer{StarredAt: starred.Format(time.RFC3339), Starred: list}
+		s.Login, s.Name = login, name
+		s.CreatedAt = created.Format(time.RFC3339)
+		s.PublicRepos, s.User.Followers = repos, followers
+		return s
+	}
+	hour := func(i int) time.Time { return base.Add(time.Duration(i) * time.Hour) }
+	burst := base.Add(240 * time.Hour)
+	minute := func(i int) time.Time { return burst.Add(time.Duration(i) * time.Minute) }
+
+	sg := []*fetch.Stargazer{
+		makeUser("veteran", hour(0), veteran, 10, 5, "Vet"),
+		makeUser("newborn", hour(1), hour(1).Add(-12*time.Hour), 0, 0, ""),
+		makeUser("weekling", hour(2), hour(2).AddDate(0, 0, -3), 1, 1, "Week"),
+		makeUser("boundary", hour(3), hour(3).AddDate(0, 0, -7), 1, 1, "Month"),
+		makeUser("loner", hour(4), veteran, 0, 0, "Loner"),
+		// Five stars within four minutes are each clustered; the
+		// straggler seven minutes after the last is not.
+		makeUser("burst0", minute(0), veteran, 1, 1, "B"),
+		makeUser("burst1", minute(1), veteran, 1, 1, "B"),
+		makeUser("burst2", minute(2), veteran, 1, 1, "B"),
+		makeUser("burst3", minute(3), veteran, 1, 1, "B"),
+		makeUser("burst4", minute(4), veteran, 1, 1, "B"),
+		makeUser("straggler", minute(11), veteran, 1, 1, "S"),
+		// twin1 and twin2 share 6 of 7 repos (Jaccard 0.857); cousin
+		// shares at most 4 of 8. The short lists are identical but
+		// below duplicateMinList.
+		makeUser("twin1", hour(5), hour(5).Add(-time.Hour), 0, 0, "", "a", "b", "c", "d", "e", "f"),
+		makeUser("twin2", hour(6), veteran, 1, 1, "T", "a", "b", "c", "d", "e", "f", "g"),
+		makeUser("cousin", hour(7), veteran, 1, 1, "C", "a", "b", "c", "d", "x", "y"),
+		makeUser("short1", hour(8), veteran, 1, 1, "S", "a", "b", "c", "d"),
+		makeUser("short2", hour(9), veteran, 1, 1, "S", "a", "b", "c", "d"),
+	}
+	testCases := []struct {
+		login   string
+		score   float64
+		reasons []string
+	}{
+		{"veteran", 0, []string{}},
+		{"newborn", 0.75, []string{"created within a day of starring", "no public repos", "no followers", "empty profile"}},
+		{"weekling", 0.25, []string{"created within a week of starring"}},
+		{"boundary", 0.1, []string{"created within a month of starring"}},
+		{"loner", 0.2, []string{"no public repos", "no followers"}},
+		{"burst0", 0.2, []string{"starred in a cluster"}},
+		{"burst4", 0.2, []string{"starred in a cluster"}},
+		{"straggler", 0, []string{}},
+		// 0.4 + 0.1 + 0.1 + 0.15 + 0.3, capped at one.
+		{"twin1", 1, []string{"created within a day of starring", "no public repos", "no followers",
+			"empty profile", "near-duplicate starred list"}},
+		{"twin2", 0.3, []string{"near-duplicate starred list"}},
+		{"cousin", 0, []string{}},
+		{"short1", 0, []string{}},
+	}
+
+	res, cleaned, err := StarQualityScores(sg, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byLogin := map[string]*StarQuality{}
+	for _, q := range res.Stargazers {
+		byLogin[q.Login] = q
+	}
+	for _, tc := range testCases {
+		q := byLogin[tc.login]
+		if math.Abs(q.Score-tc.score) > 1e-9 || !reflect.DeepEqual(q.Reasons, tc.reasons) {
+			t.Errorf("%s: expected %.2f %q; got %.2f %q", tc.login, tc.score, tc.reasons, q.Score, q.Reasons)
+		}
+		if suspicious := tc.score >= DefaultSuspicionThreshold; q.Suspicious != suspicious {
+			t.Errorf("%s: expected suspicious %t; got %t", tc.login, suspicious, q.Suspicious)
+		}
+	}
+	if res.Threshold != DefaultSuspicionThreshold || res.Suspicious != 2 {
+		t.Errorf("expected 2 suspicious at %.2f; got %d at %.2f", DefaultSuspicionThreshold, res.Suspicious, res.Threshold)
+	}
+	if first := res.Stargazers[0].Login; first != "twin1" {
+		t.Errorf("expected twin1 to sort first; got %s", first)
+	}
+	if !cleaned.Cleaned {
+		t.Errorf("expected cleaned cumulative stars")
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+const (
+	// nRecommendItems is the number of most frequently starred repos
+	// which form the item vocabulary for collaborative filtering.
+	nRecommendItems = 1000
+	// nRecommendations is the number of repos recommended per stargazer.
+	nRecommendations = 10
+)
+
+// SimilarRepo holds the item-item similarity of a repo to the
+// analyzed repo.
+type SimilarRepo struct {
+	Name       string  `json:"name"`
+	URL        string  `json:"url"`
+	Similarity float64 `json:"similarity"`
+	Count      int     `json:"count"`
+	Stargazers int     `json:"stargazers"`
+}
+
+// SimilarReposResult is the result of the similar repos analysis.
+type SimilarReposResult struct {
+	Repos []*SimilarRepo `json:"repos"`
+}
+
+func (r *SimilarReposResult) Name() string { return "similar_repos" }
+
+func (r *SimilarReposResult) Header() []string {
+	return []string{"Repository", "URL", "Similarity", "Count", "Stargazers"}
+}
+
+func (r *SimilarReposResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Repos))
+	for _, sr := range r.Repos {
+		rows = append(rows, []string{sr.Name, sr.URL, fmt.Sprintf("%.6f", sr.Similarity),
+			strconv.Itoa(sr.Count), strconv.Itoa(sr.Stargazers)})
+	}
+	return rows
+}
+
+func (r *SimilarReposResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Repos))
+	for i, sr := range r.Repos {
+		recs[i] = sr
+	}
+	return recs
+}
+
+// Recommendation is a repo a stargazer hasn't starred but is likely
+// to, given the repos they have starred.
+type Recommendation struct {
+	Login string  `json:"login"`
+	Rank  int     `json:"rank"`
+	Name  string  `json:"name"`
+	URL   string  `json:"url"`
+	Score float64 `json:"score"`
+}
+
+// RecommendationsResult is the result of the recommendations analysis.
+type RecommendationsResult struct {
+	Recommendations []*Recommendation `json:"recommendations"`
+}
+
+func (r *RecommendationsResult) Name() string { return "recommendations" }
+
+func (r *RecommendationsResult) Header() []string {
+	return []string{"Login", "Rank", "Repository", "URL", "Score"}
+}
+
+func (r *RecommendationsResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Recommendations))
+	for _, rec := range r.Recommendations {
+		rows = append(rows, []string{rec.Login, strconv.Itoa(rec.Rank), rec.Name, rec.URL, fmt.Sprintf("%.4f", rec.Score)})
+	}
+	return rows
+}
+
+func (r *RecommendationsResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Recommendations))
+	for i, rec := range r.Recommendations {
+		recs[i] = rec
+	}
+	return recs
+}
+
+// Recommend treats the starred lists of all stargazers as a binary
+// stargazer x repo matrix and computes item-item cosine similarities
+// between repos. It returns the repos most similar to the analyzed
+// repo and, for each stargazer, the unstarred repos scoring highest
+// by summed similarity to the repos they've starred.
+//
+// Every stargazer has starred the analyzed repo, so within the
+// matrix its column carries no information. Its similarity to other
+// repos is instead computed using each repo's total star count as
+// the column norm: count / sqrt(repo stargazers * our stargazers).
+func Recommend(sg []*fetch.Stargazer, rs map[string]*fetch.Repo, opts Options) (
+	*SimilarReposResult, *RecommendationsResult) {
+	log.Printf("running recommendations analysis")
+
+	counts := map[string]int{}
+	for _, s := range sg {
+		for _, rName := range s.Starred {
+			if rName != opts.Repo {
+				counts[rName]++
+			}
+		}
+	}
+
+	// Repos most similar to ours.
+	ourStars := len(sg)
+	if r, ok := rs[opts.Repo]; ok && r.StargazersCount > ourStars {
+		ourStars = r.StargazersCount
+	}
+	similar := &SimilarReposResult{}
+	for rName, count := range counts {
+		if count < opts.MinSupport {
+			continue
+		}
+		r := rs[rName]
+		stars := r.StargazersCount
+		if stars < count {
+			stars = count
+		}
+		similar.Repos = append(similar.Repos, &SimilarRepo{
+			Name:       rName,
+			URL:        fmt.Sprintf("https://github.com/%s", r.FullName),
+			Similarity: float64(count) / math.Sqrt(float64(stars)*float64(ourStars)),
+			Count:      count,
+			Stargazers: r.StargazersCount,
+		})
+	}
+	sort.Sort(bySimilarity(similar.Repos))
+	if len(similar.Repos) > nMostCorrelated {
+		similar.Repos = similar.Repos[:nMostCorrelated]
+	}
+
+	// Choose the item vocabulary: the most frequently starred repos,
+	// which must be starred by at least two stargazers to be similar
+	// to anything.
+	minCount := opts.MinSupport
+	if minCount < 2 {
+		minCount = 2
+	}
+	items := RepoCounts{}
+	for rName, count := range counts {
+		if count >= minCount {
+			items = append(items, &RepoCount{name: rName, count: count})
+		}
+	}
+	sort.Sort(items)
+	if len(items) > nRecommendItems {
+		items = items[:nRecommendItems]
+	}
+	index := map[string]int{}
+	for i, item := range items {
+		index[item.name] = i
+	}
+
+	// Accumulate co-occurrence counts and normalize to cosine similarity.
+	n := len(items)
+	sim := make([][]float64, n)
+	for i := range sim {
+		sim[i] = make([]float64, n)
+	}
+	rows := make([][]int, len(sg))
+	for si, s := range sg {
+		for _, rName := range s.Starred {
+			if i, ok := index[rName]; ok {
+				rows[si] = append(rows[si], i)
+			}
+		}
+		for _, i := range rows[si] {
+			for _, j := range rows[si] {
+				if i != j {
+					sim[i][j]++
+				}
+			}
+		}
+	}
+	// Keep only nonzero similarities, as most pairs of repos are never
+	// starred together.
+	type neighbor struct {
+		j   int
+		sim float64
+	}
+	neighbors := make([][]neighbor, n)
+	for i := range sim {
+		for j, v := range sim[i] {
+			if v > 0 {
+				neighbors[i] = append(neighbors[i], neighbor{j, v / math.Sqrt(float64(items[i].count)*float64(items[j].count))})
+			}
+		}
+	}
+
+	// Score unstarred items for each stargazer.
+	recs := &RecommendationsResult{}
+	scores := make([]float64, n)
+	for si, s := range sg {
+		if len(rows[si]) == 0 {
+			continue
+		}
+		starred := map[int]struct{}{}
+		for _, i := range rows[si] {
+			starred[i] = struct{}{}
+		}
+		for j := range scores {
+			scores[j] = 0
+		}
+		for _, i := range rows[si] {
+			for _, nb := range neighbors[i] {
+				scores[nb.j] += nb.sim
+			}
+		}
+		var cands []*SimilarRepo
+		for j, score := range scores {
+			if _, ok := starred[j]; ok || score == 0 {
+				continue
+			}
+			cands = append(cands, &SimilarRepo{Name: items[j].name, Similarity: score / float64(len(rows[si]))})
+		}
+		sort.Sort(bySimilarity(cands))
+		for rank, c := range cands {
+			if rank >= nRecommendations {
+				break
+			}
+			recs.Recommendations = append(recs.Recommendations, &Recommendation{
+				Login: s.Login,
+				Rank:  rank + 1,
+				Name:  c.Name,
+				URL:   fmt.Sprintf("https://github.com/%s", c.Name),
+				Score: c.Similarity,
+			})
+		}
+	}
+	return similar, recs
+}
+
+// bySimilarity sorts repos by descending similarity, breaking ties
+// by name.
+type bySimilarity []*SimilarRepo
+
+func (slice bySimilarity) Len() int {
+	return len(slice)
+}
+
+func (slice bySimilarity) Less(i, j int) bool {
+	if slice[i].Similarity != slice[j].Similarity {
+		return slice[i].Similarity > slice[j].Similarity /* descending order */
+	}
+	return slice[i].Name < slice[j].Name
+}
+
+func (slice bySimilarity) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
@@ -0,0 +1,195 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+// Context holds config information used to run analyses and write
+// their results.
+type Context struct {
+	*fetch.Context
+
+	Options  Options  // Analysis options
+	Renderer Renderer // Output renderer; CSV if nil
+	Segment  string   // Name of the analyzed stargazer segment, if any
+}
+
+// MakeOutputDir creates, if necessary, and returns the directory to
+// which output is written: the repo's cache subdirectory or, for a
+// named segment of stargazers, its segments/<name> subdirectory.
+func MakeOutputDir(c *fetch.Context, segment string) (string, error) {
+	dir := filepath.Join(c.CacheDir, c.Repo)
+	if len(segment) > 0 {
+		dir = filepath.Join(dir, "segments", segment)
+	}
+	if err := os.MkdirAll(dir, os.ModeDir|0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// A Result is the output of a single analysis. Results are computed
+// independently of how they're rendered; a Renderer turns a Result
+// into CSV, JSON, etc.
+type Result interface {
+	// Name returns the base name of the output file, without extension.
+	Name() string
+	// Header returns the column names for tabular output.
+	Header() []string
+	// Rows returns the result as rows of formatted strings, one per
+	// column in Header.
+	Rows() [][]string
+	// Records returns the result as a slice of typed records, one per
+	// row, for structured output.
+	Records() []interface{}
+}
+
+// A Renderer writes a Result in a particular output format.
+type Renderer interface {
+	// Ext returns the file extension used for this format.
+	Ext() string
+	// Render writes the result to w.
+	Render(w io.Writer, r Result) error
+}
+
+// Formats lists the names of the supported output formats.
+var Formats = []string{"csv", "json", "ndjson", "markdown"}
+
+// NewRenderer returns the renderer for the named output format.
+func NewRenderer(format string) (Renderer, error) {
+	switch format {
+	case "", "csv":
+		return csvRenderer{}, nil
+	case "json":
+		return jsonRenderer{}, nil
+	case "ndjson":
+		return ndjsonRenderer{}, nil
+	case "markdown", "md":
+		return markdownRenderer{}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q; must be one of %s", format, strings.Join(Formats, ", "))
+}
+
+// csvRenderer writes the header and rows as comma-separated values.
+type csvRenderer struct{}
+
+func (csvRenderer) Ext() string { return "csv" }
+
+func (csvRenderer) Render(w io.Writer, r Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(r.Header()); err != nil {
+		return fmt.Errorf("failed to write to CSV: %s", err)
+	}
+	if err := cw.WriteAll(r.Rows()); err != nil {
+		return fmt.Errorf("failed to write to CSV: %s", err)
+	}
+	return nil
+}
+
+// jsonRenderer writes the entire result as a single indented JSON
+// document.
+type jsonRenderer struct{}
+
+func (jsonRenderer) Ext() string { return "json" }
+
+func (jsonRenderer) Render(w io.Writer, r Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("failed to write JSON: %s", err)
+	}
+	return nil
+}
+
+// ndjsonRenderer writes one JSON object per record, newline-delimited.
+type ndjsonRenderer struct{}
+
+func (ndjsonRenderer) Ext() string { return "ndjson" }
+
+func (ndjsonRenderer) Render(w io.Writer, r Result) error {
+	enc := json.NewEncoder(w)
+	for _, rec := range r.Records() {
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("failed to write NDJSON: %s", err)
+		}
+	}
+	return nil
+}
+
+// markdownRenderer writes the header and rows as a GitHub-flavored
+// markdown table.
+type markdownRenderer struct{}
+
+func (markdownRenderer) Ext() string { return "md" }
+
+func (markdownRenderer) Render(w io.Writer, r Result) error {
+	writeRow := func(cells []string) error {
+		escaped := make([]string, len(cells))
+		for i, c := range cells {
+			escaped[i] = strings.Replace(strings.Replace(c, "|", `\|`, -1), "\n", " ", -1)
+		}
+		_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | "))
+		return err
+	}
+	header := r.Header()
+	if err := writeRow(header); err != nil {
+		return err
+	}
+	sep := make([]string, len(header))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	if err := writeRow(sep); err != nil {
+		return err
+	}
+	for _, row := range r.Rows() {
+		if err := writeRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteResult renders the result using the context's renderer to a
+// file named after the result in the repo's cache directory.
+func WriteResult(c *Context, r Result) error {
+	renderer := c.Renderer
+	if renderer == nil {
+		renderer = csvRenderer{}
+	}
+	f, err := createFile(c, fmt.Sprintf("%s.%s", r.Name(), renderer.Ext()))
+	if err != nil {
+		return fmt.Errorf("failed to create file: %s", err)
+	}
+	defer f.Close()
+	if err := renderer.Render(f, r); err != nil {
+		return err
+	}
+	log.Printf("wrote %s analysis to %s", r.Name(), f.Name())
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Granularities of resampled time series.
+const (
+	GranularityLegacy    = "legacy"
+	GranularityDaily     = "daily"
+	GranularityWeekly    = "weekly"
+	GranularityMonthly   = "monthly"
+	GranularityQuarterly = "quarterly"
+)
+
+// Granularities lists the valid time series granularities.
+var Granularities = []string{
+	GranularityLegacy, GranularityDaily, GranularityWeekly, GranularityMonthly, GranularityQuarterly,
+}
+
+// A Resampler divides time into calendar-aligned periods in a time
+// zone: days, weeks starting on Monday, months or quarters. Time
+// series are resampled to every period from the first event's through
+// the last's, with empty periods filled with zeros, and periods are
+// labeled by their ISO 8601 start date.
+//
+// The legacy granularity reproduces the original output of each
+// analysis: cumulative stars by UTC day, skipping days without stars;
+// attributes by seven day periods anchored at the first star; dates
+// formatted as MM/DD/YYYY in local time; and other tables by UTC
+// month. A nil Resampler is legacy.
+type Resampler struct {
+	Granularity string
+	Location    *time.Location
+}
+
+// NewResampler returns a resampler for the granularity in the named
+// time zone (an IANA name such as "America/New_York", or "Local");
+// UTC if empty.
+func NewResampler(granularity, timezone string) (*Resampler, error) {
+	valid := false
+	for _, g := range Granularities {
+		valid = valid || g == granularity
+	}
+	if !valid {
+		return nil, fmt.Errorf("unknown granularity %q; must be one of %s", granularity, strings.Join(Granularities, ", "))
+	}
+	loc := time.UTC
+	if len(timezone) > 0 {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("failed to load time zone %q: %s", timezone, err)
+		}
+	}
+	return &Resampler{Granularity: granularity, Location: loc}, nil
+}
+
+// Legacy returns whether the resampler is the legacy preset.
+func (r *Resampler) Legacy() bool {
+	return r == nil || r.Granularity == GranularityLegacy
+}
+
+// Start returns the start of the period containing t.
+func (r *Resampler) Start(t time.Time) time.Time {
+	t = t.In(r.Location)
+	y, m, d := t.Date()
+	switch r.Granularity {
+	case GranularityWeekly:
+		d -= (int(t.Weekday()) + 6) % 7
+	case GranularityMonthly:
+		d = 1
+	case GranularityQuarterly:
+		m, d = m-(m-1)%3, 1
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, r.Location)
+}
+
+// Next returns the start of the period following the one starting at
+// start.
+func (r *Resampler) Next(start time.Time) time.Time {
+	switch r.Granularity {
+	case GranularityWeekly:
+		return start.AddDate(0, 0, 7)
+	case GranularityMonthly:
+		return start.AddDate(0, 1, 0)
+	case GranularityQuarterly:
+		return start.AddDate(0, 3, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// Resample assigns each of the times, which must be sorted, to its
+// period. It returns the start of every period from the first time's
+// through the last's, and the index of each time's period.
+func (r *Resampler) Resample(times []time.Time) ([]time.Time, []int) {
+	if len(times) == 0 {
+		return nil, nil
+	}
+	periods := []time.Time{r.Start(times[0])}
+	index := make([]int, len(times))
+	for i, t := range times {
+		for !t.Before(r.Next(periods[len(periods)-1])) {
+			periods = append(periods, r.Next(periods[len(periods)-1]))
+		}
+		index[i] = len(periods) - 1
+	}
+	return periods, index
+}
+
+// Label formats the start of a period as an ISO 8601 date.
+func (r *Resampler) Label(start time.Time) string {
+	return start.Format("2006-01-02")
+}
+
+// Period returns the label of the period containing t, for tables
+// of counts by period. Under the legacy preset, the period is the
+// UTC month, labeled YYYY-MM.
+func (r *Resampler) Period(t time.Time) string {
+	if r.Legacy() {
+		return t.UTC().Format("2006-01")
+	}
+	return r.Label(r.Start(t))
+}
+
+// DayLocation returns the time zone whose calendar days and hours
+// bound daily and hourly analyses, such as star bursts, forecasts and
+// the hour-of-week heatmap: UTC under the legacy preset.
+func (r *Resampler) DayLocation() *time.Location {
+	if r.Legacy() || r.Location == nil {
+		return time.UTC
+	}
+	return r.Location
+}
+
+// dayNumber returns the number of the calendar day containing t in
+// loc, counting from the Unix epoch.
+func dayNumber(t time.Time, loc *time.Location) int64 {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / daySeconds
+}
+
+// dayStart returns the start in loc of the calendar day numbered day
+// (see dayNumber).
+func dayStart(day int64, loc *time.Location) time.Time {
+	y, m, d := time.Unix(day*daySeconds, 0).UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// formatDate formats the date of a time series point: as an ISO 8601
+// date or, under the legacy preset, as MM/DD/YYYY in local time.
+func (r *Resampler) formatDate(t time.Time) string {
+	if r.Legacy() {
+		return t.Format("01/02/2006")
+	}
+	return r.Label(t)
+}
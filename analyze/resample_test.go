@@ -0,0 +1,129 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResamplerPeriods(t *testing.T) {
+	// Wednesday, 2016-02-10.
+	ts := time.Date(2016, 2, 10, 15, 30, 0, 0, time.UTC)
+	testCases := []struct {
+		granularity string
+		start, next string
+	}{
+		{GranularityDaily, "2016-02-10", "2016-02-11"},
+		{GranularityWeekly, "2016-02-08", "2016-02-15"},
+		{GranularityMonthly, "2016-02-01", "2016-03-01"},
+		{GranularityQuarterly, "2016-01-01", "2016-04-01"},
+	}
+	for _, c := range testCases {
+		r, err := NewResampler(c.granularity, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := r.Start(ts)
+		if label := r.Label(start); label != c.start {
+			t.Errorf("%s: expected start %s; got %s", c.granularity, c.start, label)
+		}
+		if label := r.Label(r.Next(start)); label != c.next {
+			t.Errorf("%s: expected next %s; got %s", c.granularity, c.next, label)
+		}
+		if period := r.Period(ts); period != c.start {
+			t.Errorf("%s: expected period %s; got %s", c.granularity, c.start, period)
+		}
+	}
+}
+
+func TestResamplerTimezone(t *testing.T) {
+	r, err := NewResampler(GranularityDaily, "America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %s", err)
+	}
+	// 03:00 UTC is still the previous day in New York.
+	ts := time.Date(2016, 2, 10, 3, 0, 0, 0, time.UTC)
+	if period := r.Period(ts); period != "2016-02-09" {
+		t.Errorf("expected period 2016-02-09; got %s", period)
+	}
+}
+
+func TestResample(t *testing.T) {
+	r, err := NewResampler(GranularityWeekly, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	times := []time.Time{
+		time.Date(2016, 1, 4, 9, 0, 0, 0, time.UTC),
+		time.Date(2016, 1, 10, 23, 0, 0, 0, time.UTC),
+		time.Date(2016, 1, 26, 0, 0, 0, 0, time.UTC),
+	}
+	periods, index := r.Resample(times)
+	// Empty weeks between stars are filled in.
+	expPeriods := []string{"2016-01-04", "2016-01-11", "2016-01-18", "2016-01-25"}
+	if len(periods) != len(expPeriods) {
+		t.Fatalf("expected %d periods; got %d", len(expPeriods), len(periods))
+	}
+	for i, p := range periods {
+		if label := r.Label(p); label != expPeriods[i] {
+			t.Errorf("%d: expected period %s; got %s", i, expPeriods[i], label)
+		}
+	}
+	expIndex := []int{0, 0, 3}
+	for i, idx := range index {
+		if idx != expIndex[i] {
+			t.Errorf("%d: expected index %d; got %d", i, expIndex[i], idx)
+		}
+	}
+	if periods, index := r.Resample(nil); periods != nil || index != nil {
+		t.Errorf("expected no periods; got %v, %v", periods, index)
+	}
+}
+
+func TestResamplerLegacy(t *testing.T) {
+	var nilResampler *Resampler
+	legacy, err := NewResampler(GranularityLegacy, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Date(2016, 2, 10, 15, 30, 0, 0, time.UTC)
+	for _, r := range []*Resampler{nilResampler, legacy} {
+		if !r.Legacy() {
+			t.Errorf("expected %v to be legacy", r)
+		}
+		// Tables by period are by UTC month under the legacy preset.
+		if period := r.Period(ts); period != "2016-02" {
+			t.Errorf("expected period 2016-02; got %s", period)
+		}
+		if date := r.formatDate(ts.In(time.UTC)); date != "02/10/2016" {
+			t.Errorf("expected date 02/10/2016; got %s", date)
+		}
+	}
+	if r, _ := NewResampler(GranularityDaily, ""); r.Legacy() {
+		t.Error("expected daily resampler not to be legacy")
+	}
+}
+
+func TestNewResamplerErrors(t *testing.T) {
+	if _, err := NewResampler("hourly", ""); err == nil {
+		t.Error("expected error for unknown granularity")
+	}
+	if _, err := NewResampler(GranularityDaily, "Not/A_Zone"); err == nil {
+		t.Error("expected error for unknown time zone")
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+// RoleUnclassified is the role of stargazers whose bios match no
+// role's keywords.
+const RoleUnclassified = "unclassified"
+
+// DefaultRoles is the default taxonomy of stargazer roles, mapping
+// each role to the keywords and phrases in bios which indicate it.
+var DefaultRoles = map[string][]string{
+	"student": {"student", "phd student", "cs student", "undergrad", "undergraduate", "studying",
+		"freshman", "sophomore", "junior at", "senior at", "bootcamp"},
+	"researcher": {"researcher", "research", "research scientist", "phd", "ph d", "postdoc",
+		"professor", "scientist", "lab", "academic", "lecturer"},
+	"sre": {"sre", "site reliability", "reliability engineer", "devops", "infrastructure",
+		"platform engineer", "ops", "operations", "kubernetes", "on call"},
+	"data engineer": {"data engineer", "data engineering", "etl", "spark", "pipelines", "data platform",
+		"data infrastructure", "big data", "warehouse", "hadoop", "kafka"},
+	"founder": {"founder", "cofounder", "co founder", "ceo", "cto", "entrepreneur", "founding",
+		"my startup"},
+}
+
+// StargazerRole is a stargazer's role, as classified by the keywords
+// in their bio.
+type StargazerRole struct {
+	Login    string   `json:"login"`
+	Role     string   `json:"role"`
+	Keywords []string `json:"keywords"` // The role's keywords matched in the bio
+}
+
+// StargazerRolesResult is the role of each stargazer.
+type StargazerRolesResult struct {
+	Stargazers []*StargazerRole `json:"stargazers"`
+}
+
+func (r *StargazerRolesResult) Name() string { return "stargazer_roles" }
+
+func (r *StargazerRolesResult) Header() []string { return []string{"Login", "Role", "Keywords"} }
+
+func (r *StargazerRolesResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Stargazers))
+	for _, s := range r.Stargazers {
+		rows = append(rows, []string{s.Login, s.Role, strings.Join(s.Keywords, ";")})
+	}
+	return rows
+}
+
+func (r *StargazerRolesResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Stargazers))
+	for i, s := range r.Stargazers {
+		recs[i] = s
+	}
+	return recs
+}
+
+// RoleCount is the count of stargazers with a role, overall or in a
+// period.
+type RoleCount struct {
+	Period     string  `json:"period,omitempty"` // See Resampler.Period
+	Role       string  `json:"role"`
+	Stargazers int     `json:"stargazers"`
+	Share      float64 `json:"share"` // Percent of the period's stargazers
+}
+
+// RolesResult is the role mix of the stargazers, overall or by the
+// period in which they starred.
+type RolesResult struct {
+	ByPeriod bool         `json:"by_period"`
+	Roles    []*RoleCount `json:"roles"`
+}
+
+func (r *RolesResult) Name() string {
+	if r.ByPeriod {
+		return "roles_by_period"
+	}
+	return "roles"
+}
+
+func (r *RolesResult) Header() []string {
+	if r.ByPeriod {
+		return []string{"Period", "Role", "Stargazers", "Share %"}
+	}
+	return []string{"Role", "Stargazers", "Share %"}
+}
+
+func (r *RolesResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Roles))
+	for _, rc := range r.Roles {
+		row := []string{rc.Role, strconv.Itoa(rc.Stargazers), fmt.Sprintf("%.1f", rc.Share)}
+		if r.ByPeriod {
+			row = append([]string{rc.Period}, row...)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (r *RolesResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Roles))
+	for i, rc := range r.Roles {
+		recs[i] = rc
+	}
+	return recs
+}
+
+// Roles classifies each stargazer into a role of the opts.Roles
+// taxonomy (DefaultRoles if nil) by the keywords and phrases in their
+// bio. Each matched keyword scores its number of words, so that
+// "phd student" outweighs "phd"; the role with the highest score
+// wins, with ties broken by role name. Returns each stargazer's role
+// and the role mix overall and by the period of opts.Resampler (by
+// default, UTC month) in which they starred.
+func Roles(sg []*fetch.Stargazer, opts Options) (*StargazerRolesResult, *RolesResult, *RolesResult, error) {
+	log.Printf("running roles analysis")
+	taxonomy := opts.Roles
+	if taxonomy == nil {
+		taxonomy = DefaultRoles
+	}
+	var names []string
+	phrases := map[string][][]string{}
+	for role, keywords := range taxonomy {
+		names = append(names, role)
+		for _, k := range keywords {
+			if words := tokenize(k); len(words) > 0 {
+				phrases[role] = append(phrases[role], words)
+			}
+		}
+	}
+	sort.Strings(names)
+	names = append(names, RoleUnclassified)
+
+	res := &StargazerRolesResult{}
+	counts := map[string]int{}
+	type key struct{ period, role string }
+	periodCounts := map[key]int{}
+	periodTotals := map[string]int{}
+	var periods []string
+	for _, s := range sg {
+		t, err := time.Parse(time.RFC3339, s.StarredAt)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		sr := classifyRole(s.Login, tokenize(s.Bio), names, phrases)
+		res.Stargazers = append(res.Stargazers, sr)
+		counts[sr.Role]++
+		p := opts.Resampler.Period(t)
+		if _, ok := periodTotals[p]; !ok {
+			periods = append(periods, p)
+		}
+		periodTotals[p]++
+		periodCounts[key{p, sr.Role}]++
+	}
+	sort.Strings(periods)
+
+	overall := &RolesResult{}
+	for _, role := range names {
+		overall.Roles = append(overall.Roles, &RoleCount{Role: role, Stargazers: counts[role], Share: percent(counts[role], len(sg))})
+	}
+	sort.Stable(byRoleStargazers(overall.Roles))
+	byPeriod := &RolesResult{ByPeriod: true}
+	for _, p := range periods {
+		for _, role := range names {
+			if n := periodCounts[key{p, role}]; n > 0 {
+				byPeriod.Roles = append(byPeriod.Roles, &RoleCount{Period: p, Role: role, Stargazers: n, Share: percent(n, periodTotals[p])})
+			}
+		}
+	}
+	return res, overall, byPeriod, nil
+}
+
+// classifyRole returns the role of the bio's words among the sorted
+// role names, whose keyword phrases are supplied.
+func classifyRole(login string, words []string, names []string, phrases map[string][][]string) *StargazerRole {
+	sr := &StargazerRole{Login: login, Role: RoleUnclassified, Keywords: []string{}}
+	best := 0
+	for _, role := range names {
+		score := 0
+		var matched []string
+		for _, phrase := range phrases[role] {
+			if containsPhrase(words, phrase) {
+				score += len(phrase)
+				matched = append(matched, strings.Join(phrase, " "))
+			}
+		}
+		if score > best {
+			best, sr.Role, sr.Keywords = score, role, matched
+		}
+	}
+	return sr
+}
+
+// containsPhrase returns whether the phrase appears as consecutive
+// words.
+func containsPhrase(words, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, w := range phrase {
+			if words[i+j] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// byRoleStargazers sorts roles by descending stargazers.
+type byRoleStargazers []*RoleCount
+
+func (slice byRoleStargazers) Len() int {
+	return len(slice)
+}
+
+func (slice byRoleStargazers) Less(i, j int) bool {
+	return slice[i].Stargazers > slice[j].Stargazers /* descending order */
+}
+
+func (slice byRoleStargazers) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
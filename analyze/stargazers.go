@@ -0,0 +1,201 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+// StargazerSorts lists the keys by which the stargazer report may be
+// sorted.
+var StargazerSorts = []string{"score", "starred_at", "activity", "correlated_activity", "influence", "login"}
+
+// StargazerScore holds a stargazer's correlation score and activity
+// in the most correlated repos versus all repos.
+type StargazerScore struct {
+	Name      string `json:"name"`
+	Login     string `json:"login"`
+	Email     string `json:"email"`
+	StarredAt string `json:"starred_at"`
+	// CorrelationScore is the fraction of the most correlated repos
+	// which the stargazer has starred or subscribed to.
+	CorrelationScore float64 `json:"correlation_score"`
+	CorrelatedRepos  int     `json:"correlated_repos"`
+	// RawActivity is the count of commits to all subscribed repos.
+	RawActivity      int `json:"raw_activity"`
+	RawActivityRepos int `json:"raw_activity_repos"`
+	// CorrelatedActivity is the count of commits to the most
+	// correlated repos.
+	CorrelatedActivity      int `json:"correlated_activity"`
+	CorrelatedActivityRepos int `json:"correlated_activity_repos"`
+	// Influence is the stargazer's PageRank in the follower graph.
+	Influence float64 `json:"influence"`
+}
+
+// StargazerReportResult is the result of the stargazer report.
+type StargazerReportResult struct {
+	SortBy     string            `json:"sort_by"`
+	Stargazers []*StargazerScore `json:"stargazers"`
+}
+
+func (r *StargazerReportResult) Name() string { return "stargazers" }
+
+func (r *StargazerReportResult) Header() []string {
+	return []string{"Name", "Login", "Email", "Starred At", "Correlation Score", "Correlated Repos",
+		"Raw Activity", "Raw Activity Repos", "Correlated Activity", "Correlated Activity Repos", "Influence"}
+}
+
+func (r *StargazerReportResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Stargazers))
+	for _, s := range r.Stargazers {
+		rows = append(rows, []string{s.Name, s.Login, s.Email, s.StarredAt,
+			fmt.Sprintf("%.3f", s.CorrelationScore), strconv.Itoa(s.CorrelatedRepos),
+			strconv.Itoa(s.RawActivity), strconv.Itoa(s.RawActivityRepos),
+			strconv.Itoa(s.CorrelatedActivity), strconv.Itoa(s.CorrelatedActivityRepos),
+			fmt.Sprintf("%.4f", s.Influence)})
+	}
+	return rows
+}
+
+func (r *StargazerReportResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Stargazers))
+	for i, s := range r.Stargazers {
+		recs[i] = s
+	}
+	return recs
+}
+
+// StargazerReport scores each stargazer by how many of the most
+// correlated starred and subscribed repos they have starred or
+// subscribed to, and compares their commit activity in those repos
+// with their activity in all repos. Each stargazer's influence is
+// included from the results of the influence analysis. The report is
+// sorted by the specified key; see StargazerSorts.
+func StargazerReport(sg []*fetch.Stargazer, starred, subscribed *CorrelatedReposResult, influence *InfluenceResult,
+	sortBy string) (
+	*StargazerReportResult, error) {
+	log.Printf("running stargazer report")
+	less, err := stargazerLess(sortBy)
+	if err != nil {
+		return nil, err
+	}
+
+	correlated := map[string]struct{}{}
+	for _, res := range []*CorrelatedReposResult{starred, subscribed} {
+		for _, r := range res.Repos {
+			correlated[r.Name] = struct{}{}
+		}
+	}
+
+	res := &StargazerReportResult{SortBy: sortBy}
+	for _, s := range sg {
+		score := &StargazerScore{Name: s.Name, Login: s.Login, Email: s.Email, StarredAt: s.StarredAt,
+			Influence: influence.ByLogin[s.Login]}
+		seen := map[string]struct{}{}
+		for _, list := range [][]string{s.Starred, s.Subscribed} {
+			for _, rName := range list {
+				if _, ok := correlated[rName]; !ok {
+					continue
+				}
+				if _, ok := seen[rName]; !ok {
+					seen[rName] = struct{}{}
+					score.CorrelatedRepos++
+				}
+			}
+		}
+		if len(correlated) > 0 {
+			score.CorrelationScore = float64(score.CorrelatedRepos) / float64(len(correlated))
+		}
+		for rName, contrib := range s.Contributions {
+			if contrib.Commits == 0 {
+				continue
+			}
+			score.RawActivity += contrib.Commits
+			score.RawActivityRepos++
+			if _, ok := correlated[rName]; ok {
+				score.CorrelatedActivity += contrib.Commits
+				score.CorrelatedActivityRepos++
+			}
+		}
+		res.Stargazers = append(res.Stargazers, score)
+	}
+	sort.Sort(stargazerScores{res.Stargazers, less})
+	return res, nil
+}
+
+// ValidateStargazerSort returns an error if the sort key isn't one of
+// StargazerSorts.
+func ValidateStargazerSort(sortBy string) error {
+	_, err := stargazerLess(sortBy)
+	return err
+}
+
+// stargazerLess returns the ordering for the specified sort key.
+// Numeric keys sort in descending order; ties are broken by login.
+func stargazerLess(sortBy string) (func(a, b *StargazerScore) bool, error) {
+	var key func(s *StargazerScore) float64
+	switch sortBy {
+	case "", "score":
+		key = func(s *StargazerScore) float64 { return s.CorrelationScore }
+	case "activity":
+		key = func(s *StargazerScore) float64 { return float64(s.RawActivity) }
+	case "correlated_activity":
+		key = func(s *StargazerScore) float64 { return float64(s.CorrelatedActivity) }
+	case "influence":
+		key = func(s *StargazerScore) float64 { return s.Influence }
+	case "starred_at":
+		return func(a, b *StargazerScore) bool {
+			if a.StarredAt != b.StarredAt {
+				return a.StarredAt < b.StarredAt
+			}
+			return a.Login < b.Login
+		}, nil
+	case "login":
+		return func(a, b *StargazerScore) bool { return a.Login < b.Login }, nil
+	default:
+		return nil, fmt.Errorf("unknown stargazer sort %q; must be one of %s", sortBy, strings.Join(StargazerSorts, ", "))
+	}
+	return func(a, b *StargazerScore) bool {
+		if ka, kb := key(a), key(b); ka != kb {
+			return ka > kb /* descending order */
+		}
+		return a.Login < b.Login
+	}, nil
+}
+
+type stargazerScores struct {
+	slice []*StargazerScore
+	less  func(a, b *StargazerScore) bool
+}
+
+func (s stargazerScores) Len() int {
+	return len(s.slice)
+}
+
+func (s stargazerScores) Less(i, j int) bool {
+	return s.less(s.slice[i], s.slice[j])
+}
+
+func (s stargazerScores) Swap(i, j int) {
+	s.slice[i], s.slice[j] = s.slice[j], s.slice[i]
+}
@@ -0,0 +1,113 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+const daySeconds = 60 * 60 * 24
+
+// median returns the median of the values, or zero if there are none.
+// The values are sorted in place.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+// mean returns the arithmetic mean of the values, or zero if there
+// are none.
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// quantile returns the q quantile (0-1) of sorted values, linearly
+// interpolating between the closest ranks, or zero if there are none.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	i := int(math.Floor(pos))
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// gini returns the Gini coefficient of sorted non-negative values:
+// zero if all are equal, approaching one if one value holds the
+// total. Zero if there are none or their total is zero.
+func gini(sorted []float64) float64 {
+	n := float64(len(sorted))
+	sum, weighted := 0.0, 0.0
+	for i, v := range sorted {
+		sum += v
+		weighted += float64(i+1) * v
+	}
+	if sum == 0 {
+		return 0
+	}
+	return 2*weighted/(n*sum) - (n+1)/n
+}
+
+// percent returns n as a percentage of total, or zero if total is zero.
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
+
+// ageAtStar returns the age in days of the stargazer's account at the
+// time they starred the repo, or NaN if either timestamp is invalid.
+func ageAtStar(s *fetch.Stargazer) float64 {
+	starred, err := time.Parse(time.RFC3339, s.StarredAt)
+	if err != nil {
+		return math.NaN()
+	}
+	created, err := time.Parse(time.RFC3339, s.CreatedAt)
+	if err != nil {
+		return math.NaN()
+	}
+	return starred.Sub(created).Hours() / 24
+}
+
+// emptyProfile returns whether the stargazer has filled in none of
+// the optional profile fields.
+func emptyProfile(s *fetch.Stargazer) bool {
+	return len(s.Name) == 0 && len(s.Bio) == 0 && len(s.Company) == 0 &&
+		len(s.Location) == 0 && len(s.Blog) == 0 && len(s.Email) == 0
+}
@@ -0,0 +1,81 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGini(t *testing.T) {
+	testCases := []struct {
+		sorted   []float64
+		expected float64
+	}{
+		{nil, 0},
+		{[]float64{0, 0}, 0},
+		{[]float64{5, 5, 5, 5}, 0},
+		// One of n holding the total gives (n-1)/n.
+		{[]float64{0, 0, 0, 1}, 0.75},
+		// Mean absolute difference 2 over twice the mean 2.
+		{[]float64{1, 3}, 0.25},
+		{[]float64{1, 2, 3, 4}, 0.25},
+	}
+	for i, tc := range testCases {
+		if g := gini(tc.sorted); math.Abs(g-tc.expected) > 1e-9 {
+			t.Errorf("%d: expected gini(%v) %v; got %v", i, tc.sorted, tc.expected, g)
+		}
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40}
+	testCases := []struct {
+		sorted   []float64
+		q        float64
+		expected float64
+	}{
+		{nil, 0.5, 0},
+		{[]float64{7}, 0.5, 7},
+		{sorted, 0, 10},
+		{sorted, 0.25, 17.5},
+		{sorted, 0.5, 25},
+		{sorted, 0.9, 37},
+		{sorted, 1, 40},
+	}
+	for i, tc := range testCases {
+		if v := quantile(tc.sorted, tc.q); math.Abs(v-tc.expected) > 1e-9 {
+			t.Errorf("%d: expected quantile(%v, %v) %v; got %v", i, tc.sorted, tc.q, tc.expected, v)
+		}
+	}
+}
+
+func TestMedian(t *testing.T) {
+	testCases := []struct {
+		values   []float64
+		expected float64
+	}{
+		{nil, 0},
+		{[]float64{3, 1, 2}, 2},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	}
+	for i, tc := range testCases {
+		if v := median(tc.values); v != tc.expected {
+			t.Errorf("%d: expected median %v; got %v", i, tc.expected, v)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+// suppress enforces a minimum group size on the results of redacted
+// analyses: rows and cells which describe fewer than k stargazers are
+// dropped, and the profile URLs of stargazers, which embed their
+// logins, are blanked. Rows describing a single stargazer by their
+// hashed login are kept.
+func (r *Results) suppress(k int) {
+	for _, f := range r.Followers.Stargazers {
+		f.URL = ""
+		f.AvatarURL = ""
+	}
+	if k <= 1 {
+		return
+	}
+
+	var cohorts []*Cohort
+	for _, c := range r.Cohorts.Cohorts {
+		// Profiles describe current stargazers.
+		if c.Current >= k {
+			cohorts = append(cohorts, c)
+		}
+	}
+	r.Cohorts.Cohorts = cohorts
+	var retention []*CohortRetention
+	for _, c := range r.CohortRetention.Cohorts {
+		if c.Stargazers >= k {
+			retention = append(retention, c)
+		}
+	}
+	r.CohortRetention.Cohorts = retention
+
+	var communities []*Community
+	for _, c := range r.Communities.Communities {
+		if c.Stargazers < k {
+			continue
+		}
+		c.Companies = suppressNameCounts(c.Companies, k)
+		c.Locations = suppressNameCounts(c.Locations, k)
+		c.DistinctiveStarred = suppressNameCounts(c.DistinctiveStarred, k)
+		communities = append(communities, c)
+	}
+	r.Communities.Communities = communities
+	kept := map[int]bool{}
+	for _, c := range communities {
+		kept[c.ID] = true
+	}
+	for login, id := range r.Communities.Membership {
+		if !kept[id] {
+			delete(r.Communities.Membership, login)
+		}
+	}
+
+	var months []*GeographyMonth
+	for _, m := range r.Geography.Months {
+		if m.NewStars >= k {
+			months = append(months, m)
+		}
+	}
+	r.Geography.Months = months
+
+	var employers []*EmployerStats
+	for _, e := range r.Employers.Employers {
+		if e.Stargazers >= k {
+			employers = append(employers, e)
+		}
+	}
+	r.Employers.Employers = employers
+	var employerMonths []*EmployerMonth
+	for _, m := range r.EmployersByMonth.Months {
+		if m.NewStars >= k {
+			employerMonths = append(employerMonths, m)
+		}
+	}
+	r.EmployersByMonth.Months = employerMonths
+
+	for _, res := range []*RolesResult{r.Roles, r.RolesByPeriod} {
+		var roles []*RoleCount
+		for _, rc := range res.Roles {
+			if rc.Stargazers >= k {
+				roles = append(roles, rc)
+			}
+		}
+		res.Roles = roles
+	}
+
+	var timezones []*TimezoneCount
+	for _, tc := range r.Timezones.Timezones {
+		if tc.Stargazers >= k {
+			timezones = append(timezones, tc)
+		}
+	}
+	r.Timezones.Timezones = timezones
+
+	var dists []*Distribution
+	for _, d := range r.Distributions.Distributions {
+		if d.N >= k {
+			dists = append(dists, d)
+		}
+	}
+	r.Distributions.Distributions = dists
+	var bins []*HistogramBin
+	for _, b := range r.Histograms.Bins {
+		if b.Count >= k {
+			bins = append(bins, b)
+		}
+	}
+	r.Histograms.Bins = bins
+}
+
+// suppressNameCounts returns the name counts of at least k.
+func suppressNameCounts(ncs []*NameCount, k int) []*NameCount {
+	var kept []*NameCount
+	for _, nc := range ncs {
+		if nc.Count >= k {
+			kept = append(kept, nc)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,72 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import "testing"
+
+func TestSuppress(t *testing.T) {
+	r := &Results{
+		Followers: &FollowersResult{Stargazers: []*FollowerStats{
+			{Login: "u_0123456789ab", URL: "https://github.com/u_0123456789ab", AvatarURL: "https://avatars/1"},
+		}},
+		Cohorts:         &CohortsResult{Cohorts: []*Cohort{{Month: "2016-01", Stargazers: 9, Current: 5}, {Month: "2016-02", Stargazers: 9, Current: 4}}},
+		CohortRetention: &CohortRetentionResult{Cohorts: []*CohortRetention{{Month: "2016-01", Stargazers: 5}, {Month: "2016-02", Stargazers: 1}}},
+		Communities: &CommunitiesResult{
+			Communities: []*Community{
+				{ID: 0, Stargazers: 6, Companies: []*NameCount{{Name: "Acme", Count: 5}, {Name: "Initech", Count: 1}}},
+				{ID: 1, Stargazers: 2},
+			},
+			Membership: map[string]int{"a": 0, "b": 1},
+		},
+		Geography:        &GeographyResult{Months: []*GeographyMonth{{Country: "Germany", NewStars: 5}, {Country: "Iceland", NewStars: 1}}},
+		Employers:        &EmployersResult{Employers: []*EmployerStats{{Employer: "Acme", Stargazers: 5}, {Employer: "Initech", Stargazers: 4}}},
+		EmployersByMonth: &EmployersByMonthResult{Months: []*EmployerMonth{{Employer: "Acme", NewStars: 2}}},
+		Roles:            &RolesResult{Roles: []*RoleCount{{Role: "engineer", Stargazers: 7}}},
+		RolesByPeriod:    &RolesResult{ByPeriod: true, Roles: []*RoleCount{{Role: "engineer", Stargazers: 3}}},
+		Timezones:        &TimezonesResult{Timezones: []*TimezoneCount{{Offset: 1, Stargazers: 5}}},
+		Distributions:    &DistributionsResult{Distributions: []*Distribution{{Metric: "followers", N: 4}}},
+		Histograms:       &HistogramsResult{Bins: []*HistogramBin{{Count: 5}, {Count: 0}}},
+	}
+	r.suppress(5)
+
+	if f := r.Followers.Stargazers[0]; f.URL != "" || f.AvatarURL != "" {
+		t.Errorf("expected blank URLs; got %q, %q", f.URL, f.AvatarURL)
+	}
+	checks := []struct {
+		name      string
+		got, want int
+	}{
+		{"cohorts", len(r.Cohorts.Cohorts), 1},
+		{"cohort retention", len(r.CohortRetention.Cohorts), 1},
+		{"communities", len(r.Communities.Communities), 1},
+		{"community companies", len(r.Communities.Communities[0].Companies), 1},
+		{"membership", len(r.Communities.Membership), 1},
+		{"geography", len(r.Geography.Months), 1},
+		{"employers", len(r.Employers.Employers), 1},
+		{"employers by month", len(r.EmployersByMonth.Months), 0},
+		{"roles", len(r.Roles.Roles), 1},
+		{"roles by period", len(r.RolesByPeriod.Roles), 0},
+		{"timezones", len(r.Timezones.Timezones), 1},
+		{"distributions", len(r.Distributions.Distributions), 0},
+		{"histogram bins", len(r.Histograms.Bins), 1},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s: expected %d rows; got %d", c.name, c.want, c.got)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+const (
+	// nKeywords is the number of top keywords and of top bigrams
+	// reported for each text source.
+	nKeywords = 50
+	// minKeywordDocuments is the minimum number of documents in which
+	// a keyword must appear to be reported.
+	minKeywordDocuments = 2
+)
+
+// stopwords are common English words and web noise excluded from
+// keywords. They also break bigrams.
+var stopwords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`
+a about above after again all also am an and any are as at be because been
+before being below between both but by can could did do does doing down during
+each few for from further had has have having he her here hers him his how i
+if in into is it its just me more most my no nor not now of off on once only or
+other our ours out over own same she should so some such than that the their
+them then there these they this those through to too under until up very was we
+were what when where which while who whom why will with would you your yours
+com http https io org net www github`) {
+		stopwords[w] = true
+	}
+}
+
+// tokenize splits text into lower case words of letters, digits and
+// the '+' and '#' of names like c++ and c#.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	})
+}
+
+// terms returns the keyword terms of text: its words and bigrams of
+// adjacent words, excluding stopwords, numbers and single letters.
+func terms(text string) (words, bigrams []string) {
+	prev := ""
+	for _, t := range tokenize(text) {
+		if _, err := strconv.Atoi(t); err == nil || len(t) < 2 || stopwords[t] {
+			prev = ""
+			continue
+		}
+		words = append(words, t)
+		if len(prev) > 0 {
+			bigrams = append(bigrams, prev+" "+t)
+		}
+		prev = t
+	}
+	return words, bigrams
+}
+
+// Keyword is a word or bigram which characterizes a body of text.
+type Keyword struct {
+	Term      string  `json:"term"`
+	Kind      string  `json:"kind"`      // word or bigram
+	Documents int     `json:"documents"` // Documents containing the term
+	Score     float64 `json:"score"`     // Summed TF-IDF
+}
+
+// KeywordsResult is the result of a keyword analysis of bios or repo
+// descriptions.
+type KeywordsResult struct {
+	Source   string     `json:"source"` // bio or repo_description
+	Keywords []*Keyword `json:"keywords"`
+}
+
+func (r *KeywordsResult) Name() string { return r.Source + "_keywords" }
+
+func (r *KeywordsResult) Header() []string {
+	return []string{"Term", "Kind", "Documents", "Score"}
+}
+
+func (r *KeywordsResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Keywords))
+	for _, k := range r.Keywords {
+		rows = append(rows, []string{k.Term, k.Kind, strconv.Itoa(k.Documents), fmt.Sprintf("%.3f", k.Score)})
+	}
+	return rows
+}
+
+func (r *KeywordsResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Keywords))
+	for i, k := range r.Keywords {
+		recs[i] = k
+	}
+	return recs
+}
+
+// Keywords extracts the top keywords and bigrams of the stargazers'
+// bios and of the descriptions of correlated starred repos. Each
+// document's terms are scored by TF-IDF, with term frequency
+// normalized by the document's length and a smoothed inverse
+// document frequency, log(1 + N/df); a term's score is the sum over
+// documents. Repo descriptions are weighted by the repo's count of
+// stargazers. Terms in fewer than minKeywordDocuments documents are
+// excluded.
+func Keywords(sg []*fetch.Stargazer, rs map[string]*fetch.Repo, correlated *CorrelatedReposResult) (*KeywordsResult, *KeywordsResult) {
+	log.Printf("running keywords analysis")
+	var bios []string
+	for _, s := range sg {
+		bios = append(bios, s.Bio)
+	}
+	var descriptions []string
+	var weights []float64
+	for _, cr := range correlated.Repos {
+		if r, ok := rs[cr.Name]; ok && len(r.Description) > 0 {
+			descriptions = append(descriptions, r.Description)
+			weights = append(weights, float64(cr.Count))
+		}
+	}
+	return &KeywordsResult{Source: "bio", Keywords: tfidf(bios, nil)},
+		&KeywordsResult{Source: "repo_description", Keywords: tfidf(descriptions, weights)}
+}
+
+// tfidf returns the top nKeywords words and bigrams of the documents,
+// each document weighted by weights[i], or one if weights is nil.
+func tfidf(docs []string, weights []float64) []*Keyword {
+	type stats struct {
+		kind string
+		df   int
+		tf   float64 // Weighted sum of normalized term frequencies
+	}
+	all := map[string]*stats{}
+	n := 0
+	for i, doc := range docs {
+		words, bigrams := terms(doc)
+		if len(words) == 0 {
+			continue
+		}
+		n++
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		for _, kind := range []struct {
+			name  string
+			terms []string
+		}{{"word", words}, {"bigram", bigrams}} {
+			counts := map[string]int{}
+			for _, t := range kind.terms {
+				counts[t]++
+			}
+			for t, c := range counts {
+				st, ok := all[t]
+				if !ok {
+					st = &stats{kind: kind.name}
+					all[t] = st
+				}
+				st.df++
+				st.tf += w * float64(c) / float64(len(kind.terms))
+			}
+		}
+	}
+	byKind := map[string][]*Keyword{}
+	for t, st := range all {
+		if st.df < minKeywordDocuments {
+			continue
+		}
+		byKind[st.kind] = append(byKind[st.kind], &Keyword{
+			Term: t, Kind: st.kind, Documents: st.df, Score: st.tf * math.Log(1+float64(n)/float64(st.df)),
+		})
+	}
+	var keywords []*Keyword
+	for _, kind := range []string{"word", "bigram"} {
+		sort.Sort(byKeywordScore(byKind[kind]))
+		if len(byKind[kind]) > nKeywords {
+			byKind[kind] = byKind[kind][:nKeywords]
+		}
+		keywords = append(keywords, byKind[kind]...)
+	}
+	return keywords
+}
+
+// byKeywordScore sorts keywords by descending score, then by term.
+type byKeywordScore []*Keyword
+
+func (slice byKeywordScore) Len() int {
+	return len(slice)
+}
+
+func (slice byKeywordScore) Less(i, j int) bool {
+	if slice[i].Score != slice[j].Score {
+		return slice[i].Score > slice[j].Score /* descending order */
+	}
+	return slice[i].Term < slice[j].Term
+}
+
+func (slice byKeywordScore) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
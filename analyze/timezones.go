@@ -0,0 +1,350 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+	"github.com/spencerkimball/stargazers/geo"
+)
+
+const (
+	// minTimezoneEvents is the minimum number of star timestamps from
+	// which a stargazer's time zone is inferred.
+	minTimezoneEvents = 10
+	// minOffset and maxOffset bound the inferred UTC offsets.
+	minOffset = -12
+	maxOffset = 14
+)
+
+// Sources of a stargazer's time zone.
+const (
+	timezoneFromLocation = "location"
+	timezoneFromStars    = "stars"
+)
+
+// activityProfile is the relative GitHub activity of developers by
+// local hour of the day: lowest in the early morning, highest through
+// the working day and into the evening.
+var activityProfile = normalize([24]float64{
+	40, 28, 18, 12, 9, 9, 13, 22, 35, 48, 55, 57,
+	53, 55, 58, 58, 56, 50, 45, 46, 50, 53, 52, 47,
+})
+
+func normalize(p [24]float64) [24]float64 {
+	sum := 0.0
+	for _, v := range p {
+		sum += v
+	}
+	for i := range p {
+		p[i] /= sum
+	}
+	return p
+}
+
+// weekdays are the days of the week, from Monday.
+var weekdays = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+// HeatmapCell is the count of stars in an hour of the week.
+type HeatmapCell struct {
+	Day   string `json:"day"`
+	Hour  int    `json:"hour"` // In the heatmap's time zone
+	Stars int    `json:"stars"`
+}
+
+// StarHeatmapResult is the count of stars by hour of the week in a
+// time zone.
+type StarHeatmapResult struct {
+	Location string     `json:"location"` // Time zone name, e.g. "UTC"
+	Stars    [7][24]int `json:"stars"`    // Indexed by day from Monday, then hour
+}
+
+func (r *StarHeatmapResult) Name() string { return "star_heatmap" }
+
+func (r *StarHeatmapResult) Header() []string {
+	header := []string{"Day"}
+	for h := 0; h < 24; h++ {
+		header = append(header, fmt.Sprintf("%02d", h))
+	}
+	return header
+}
+
+func (r *StarHeatmapResult) Rows() [][]string {
+	var rows [][]string
+	for d, day := range weekdays {
+		row := []string{day}
+		for _, n := range r.Stars[d] {
+			row = append(row, strconv.Itoa(n))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (r *StarHeatmapResult) Records() []interface{} {
+	var recs []interface{}
+	for d, day := range weekdays {
+		for h, n := range r.Stars[d] {
+			recs = append(recs, &HeatmapCell{Day: day, Hour: h, Stars: n})
+		}
+	}
+	return recs
+}
+
+// StargazerTimezone is a stargazer's estimated UTC offset.
+type StargazerTimezone struct {
+	Login    string  `json:"login"`
+	Location string  `json:"location"`
+	Offset   float64 `json:"offset"` // Hours
+	Source   string  `json:"source"` // "location", "stars" or empty if unknown
+	// Events is the number of star timestamps from which the offset
+	// was inferred.
+	Events     int     `json:"events"`
+	Confidence float64 `json:"confidence"`
+}
+
+// StargazerTimezonesResult is the result of the time zone inference.
+type StargazerTimezonesResult struct {
+	Stargazers []*StargazerTimezone `json:"stargazers"`
+}
+
+func (r *StargazerTimezonesResult) Name() string { return "stargazer_timezones" }
+
+func (r *StargazerTimezonesResult) Header() []string {
+	return []string{"Login", "Location", "UTC Offset", "Source", "Events", "Confidence"}
+}
+
+func (r *StargazerTimezonesResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Stargazers))
+	for _, s := range r.Stargazers {
+		offset := ""
+		if len(s.Source) > 0 {
+			offset = formatOffset(s.Offset)
+		}
+		rows = append(rows, []string{s.Login, s.Location, offset, s.Source, strconv.Itoa(s.Events),
+			fmt.Sprintf("%.2f", s.Confidence)})
+	}
+	return rows
+}
+
+func (r *StargazerTimezonesResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Stargazers))
+	for i, s := range r.Stargazers {
+		recs[i] = s
+	}
+	return recs
+}
+
+// TimezoneCount is the count of stargazers at a UTC offset.
+type TimezoneCount struct {
+	Offset     float64 `json:"offset"` // Hours
+	Stargazers int     `json:"stargazers"`
+	Share      float64 `json:"share"` // Percent of stargazers with a known offset
+}
+
+// TimezonesResult is the distribution of stargazers by UTC offset.
+type TimezonesResult struct {
+	Timezones []*TimezoneCount `json:"timezones"`
+}
+
+func (r *TimezonesResult) Name() string { return "timezones" }
+
+func (r *TimezonesResult) Header() []string { return []string{"UTC Offset", "Stargazers", "Share %"} }
+
+func (r *TimezonesResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Timezones))
+	for _, t := range r.Timezones {
+		rows = append(rows, []string{formatOffset(t.Offset), strconv.Itoa(t.Stargazers), fmt.Sprintf("%.1f", t.Share)})
+	}
+	return rows
+}
+
+func (r *TimezonesResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Timezones))
+	for i, t := range r.Timezones {
+		recs[i] = t
+	}
+	return recs
+}
+
+// AudienceHour is the expected activity of the audience in an hour.
+type AudienceHour struct {
+	Hour int `json:"hour"` // UTC
+	// Activity is the expected share of stargazers active in the hour,
+	// as a percentage of the busiest hour.
+	Activity float64 `json:"activity"`
+}
+
+// AudienceHoursResult is the expected activity of stargazers with a
+// known time zone, by UTC hour of the day.
+type AudienceHoursResult struct {
+	Hours []*AudienceHour `json:"hours"`
+}
+
+func (r *AudienceHoursResult) Name() string { return "audience_hours" }
+
+func (r *AudienceHoursResult) Header() []string { return []string{"UTC Hour", "Activity %"} }
+
+func (r *AudienceHoursResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Hours))
+	for _, h := range r.Hours {
+		rows = append(rows, []string{fmt.Sprintf("%02d:00", h.Hour), fmt.Sprintf("%.1f", h.Activity)})
+	}
+	return rows
+}
+
+func (r *AudienceHoursResult) Records() []interface{} {
+	recs := make([]interface{}, len(r.Hours))
+	for i, h := range r.Hours {
+		recs[i] = h
+	}
+	return recs
+}
+
+// Timezones counts stars by hour of the week in the time zone of r
+// (see Resampler.DayLocation) and estimates each stargazer's UTC
+// offset. The offset of a stargazer with a recognized
+// location is that of the location (see geo.Place.UTCOffset);
+// otherwise it is inferred from the timestamps of their stars,
+// including those of their starred repos if fetched with
+// --starred-times, as the offset under which the hours of the stars
+// best fit activityProfile. The confidence of an inferred offset is
+// the posterior probability, under a uniform prior, of the offset or
+// its neighbors. The expected hourly activity of the audience follows
+// from the distribution of offsets.
+func Timezones(sg []*fetch.Stargazer, r *Resampler) (*StarHeatmapResult, *StargazerTimezonesResult, *TimezonesResult,
+	*AudienceHoursResult, error) {
+	log.Printf("running timezones analysis")
+	loc := r.DayLocation()
+	heatmap := &StarHeatmapResult{Location: loc.String()}
+	byLogin := &StargazerTimezonesResult{}
+	counts := map[float64]int{}
+	known := 0
+	for _, s := range sg {
+		t, err := time.Parse(time.RFC3339, s.StarredAt)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		local := t.In(loc)
+		heatmap.Stars[(local.Weekday()+6)%7][local.Hour()]++
+		t = t.UTC()
+
+		tz := &StargazerTimezone{Login: s.Login, Location: s.Location}
+		byLogin.Stargazers = append(byLogin.Stargazers, tz)
+		place := geo.Normalize(s.Location)
+		if offset, ok := place.UTCOffset(); ok {
+			tz.Offset, tz.Source, tz.Confidence = offset, timezoneFromLocation, place.Confidence
+		} else {
+			hours := []int{t.Hour()}
+			for _, st := range s.StarredTimes {
+				if t, err := time.Parse(time.RFC3339, st); err == nil {
+					hours = append(hours, t.UTC().Hour())
+				}
+			}
+			tz.Events = len(hours)
+			if tz.Events < minTimezoneEvents {
+				continue
+			}
+			offset, confidence := inferOffset(hours)
+			tz.Offset, tz.Source, tz.Confidence = float64(offset), timezoneFromStars, confidence
+		}
+		counts[tz.Offset]++
+		known++
+	}
+
+	dist := &TimezonesResult{}
+	for offset, n := range counts {
+		dist.Timezones = append(dist.Timezones, &TimezoneCount{Offset: offset, Stargazers: n, Share: percent(n, known)})
+	}
+	sort.Sort(byOffset(dist.Timezones))
+
+	audience := &AudienceHoursResult{}
+	var activity [24]float64
+	peak := 0.0
+	for h := range activity {
+		for _, tz := range dist.Timezones {
+			local := (h + int(math.Floor(tz.Offset+0.5)) + 48) % 24
+			activity[h] += float64(tz.Stargazers) * activityProfile[local]
+		}
+		peak = math.Max(peak, activity[h])
+	}
+	for h, a := range activity {
+		if peak > 0 {
+			a = 100 * a / peak
+		}
+		audience.Hours = append(audience.Hours, &AudienceHour{Hour: h, Activity: a})
+	}
+	return heatmap, byLogin, dist, audience, nil
+}
+
+// inferOffset returns the UTC offset in whole hours which maximizes
+// the likelihood of activity at the specified UTC hours, along with
+// the posterior probability of that offset or its neighbors.
+func inferOffset(hours []int) (int, float64) {
+	n := maxOffset - minOffset + 1
+	logLik := make([]float64, n)
+	best := 0
+	for i := range logLik {
+		offset := minOffset + i
+		for _, h := range hours {
+			logLik[i] += math.Log(activityProfile[(h+offset+48)%24])
+		}
+		if logLik[i] > logLik[best] {
+			best = i
+		}
+	}
+	total, near := 0.0, 0.0
+	for i, ll := range logLik {
+		p := math.Exp(ll - logLik[best])
+		total += p
+		if i >= best-1 && i <= best+1 {
+			near += p
+		}
+	}
+	return minOffset + best, near / total
+}
+
+// formatOffset formats a UTC offset in hours as, e.g., "UTC+05:30".
+func formatOffset(offset float64) string {
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	minutes := int(math.Floor(offset*60 + 0.5))
+	return fmt.Sprintf("UTC%c%02d:%02d", sign, minutes/60, minutes%60)
+}
+
+// byOffset sorts time zones by ascending UTC offset.
+type byOffset []*TimezoneCount
+
+func (slice byOffset) Len() int {
+	return len(slice)
+}
+
+func (slice byOffset) Less(i, j int) bool {
+	return slice[i].Offset < slice[j].Offset
+}
+
+func (slice byOffset) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
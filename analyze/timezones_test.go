@@ -0,0 +1,51 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package analyze
+
+import (
+	"testing"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+func TestStarHeatmapLocation(t *testing.T) {
+	ny, err := NewResampler(GranularityWeekly, "America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %s", err)
+	}
+	// Tuesday 03:00 UTC is Monday 22:00 in New York (UTC-5).
+	sg := []*fetch.Stargazer{{StarredAt: "2016-02-09T03:00:00Z"}}
+	testCases := []struct {
+		r        *Resampler
+		location string
+		day      int
+		hour     int
+	}{
+		{nil, "UTC", 1, 3},
+		{ny, "America/New_York", 0, 22},
+	}
+	for _, tc := range testCases {
+		heatmap, _, _, _, err := Timezones(sg, tc.r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if heatmap.Location != tc.location || heatmap.Stars[tc.day][tc.hour] != 1 {
+			t.Errorf("expected a star on day %d at %02d:00 %s; got %s %v",
+				tc.day, tc.hour, tc.location, heatmap.Location, heatmap.Stars)
+		}
+	}
+}
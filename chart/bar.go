@@ -0,0 +1,107 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package chart
+
+import "io"
+
+// A BarChart plots a value for each of a sequence of labeled bars.
+// Horizontal bar charts leave room to the left of the bars for long
+// labels, such as repository names.
+type BarChart struct {
+	Title      string
+	YLabel     string
+	Width      int // Pixels; defaults to 720
+	Height     int // Pixels; defaults to 360
+	Horizontal bool
+	LogY       bool   // Plot the value axis on a log scale
+	Color      string // Hex color; defaults to the first Palette color
+	Labels     []string
+	Values     []float64
+}
+
+// SVG writes the chart as an SVG document.
+func (bc *BarChart) SVG(w io.Writer) error {
+	width, height := dims(bc.Width, bc.Height)
+	sc := newSVGCanvas(width, height)
+	bc.draw(sc, width, height)
+	return sc.writeTo(w)
+}
+
+// PNG writes the chart as a PNG image.
+func (bc *BarChart) PNG(w io.Writer) error {
+	width, height := dims(bc.Width, bc.Height)
+	pc := newPNGCanvas(width, height)
+	bc.draw(pc, width, height)
+	return pc.writeTo(w)
+}
+
+func (bc *BarChart) draw(c canvas, width, height float64) {
+	color := bc.Color
+	if len(color) == 0 {
+		color = Palette[0]
+	}
+	maxV := 0.0
+	for _, v := range bc.Values {
+		if v > maxV {
+			maxV = v
+		}
+	}
+	drawFrame(c, bc.Title, bc.YLabel, width, height)
+	n := float64(len(bc.Values))
+	if n == 0 {
+		return
+	}
+
+	if bc.Horizontal {
+		const labelWidth = 200
+		left := float64(labelWidth)
+		xs, ticks := valueScale(maxV, bc.LogY, left, width-marginRight)
+		band := (height - marginTop - marginBottom) / n
+		for _, v := range ticks {
+			x := xs.pos(v)
+			c.line(x, marginTop, x, height-marginBottom, gridColor, 1)
+			c.text(x, height-marginBottom+16, formatNumber(v), labelSize, anchorMiddle, textColor)
+		}
+		for i, v := range bc.Values {
+			y := marginTop + band*float64(i)
+			c.rect(left, y+band*0.1, xs.pos(v)-left, band*0.8, color)
+			if i < len(bc.Labels) {
+				c.text(left-6, y+band/2+4, bc.Labels[i], labelSize, anchorEnd, textColor)
+			}
+		}
+		c.line(left, marginTop, left, height-marginBottom, axisColor, 1)
+		return
+	}
+
+	ys, ticks := valueScale(maxV, bc.LogY, height-marginBottom, marginTop)
+	band := (width - marginLeft - marginRight) / n
+	for _, v := range ticks {
+		y := ys.pos(v)
+		c.line(marginLeft, y, width-marginRight, y, gridColor, 1)
+		c.text(marginLeft-6, y+4, formatNumber(v), labelSize, anchorEnd, textColor)
+	}
+	// Label at most ~12 bars to avoid overlapping text.
+	every := int(n/12) + 1
+	for i, v := range bc.Values {
+		x := marginLeft + band*float64(i)
+		c.rect(x+band*0.1, ys.pos(v), band*0.8, height-marginBottom-ys.pos(v), color)
+		if i < len(bc.Labels) && i%every == 0 {
+			c.text(x+band/2, height-marginBottom+16, bc.Labels[i], labelSize, anchorMiddle, textColor)
+		}
+	}
+	c.line(marginLeft, height-marginBottom, width-marginRight, height-marginBottom, axisColor, 1)
+}
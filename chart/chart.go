@@ -0,0 +1,297 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+// Package chart renders line and bar charts of analysis results as
+// SVG or PNG. Charts are drawn onto a canvas abstraction so the same
+// layout code targets both output formats; PNG rendering is done in
+// pure Go.
+package chart
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	defaultWidth  = 720
+	defaultHeight = 360
+
+	marginLeft   = 64
+	marginRight  = 24
+	marginTop    = 40
+	marginBottom = 40
+
+	titleSize = 14
+	labelSize = 11
+)
+
+// Palette is the sequence of colors assigned to series which don't
+// specify their own.
+var Palette = []string{"#4c78a8", "#f58518", "#54a24b", "#e45756", "#72b7b2", "#b279a2"}
+
+const (
+	axisColor = "#444444"
+	gridColor = "#e5e5e5"
+	textColor = "#222222"
+
+	annotationColor = "#d62728"
+)
+
+// A Chart can be rendered as SVG or PNG.
+type Chart interface {
+	SVG(w io.Writer) error
+	PNG(w io.Writer) error
+}
+
+// A Point is a single (x, y) sample.
+type Point struct {
+	X, Y float64
+}
+
+// A Series is a named sequence of points drawn as a single line.
+type Series struct {
+	Name   string
+	Color  string // Hex color; chosen from Palette if empty
+	Points []Point
+}
+
+// TimePoint returns a point with the x coordinate set to the Unix
+// time of t, for use with charts whose x axis is time.
+func TimePoint(t time.Time, y float64) Point {
+	return Point{X: float64(t.Unix()), Y: y}
+}
+
+// An Annotation marks a point on the x axis of a line chart with a
+// labeled vertical line, e.g. to mark a launch date.
+type Annotation struct {
+	X     float64
+	Label string
+}
+
+// TimeAnnotation returns an annotation at time t, for use with charts
+// whose x axis is time.
+func TimeAnnotation(t time.Time, label string) Annotation {
+	return Annotation{X: float64(t.Unix()), Label: label}
+}
+
+// anchor specifies the horizontal alignment of text.
+type anchor int
+
+const (
+	anchorStart anchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// canvas is implemented by each output format. Coordinates are in
+// pixels with the origin at the top left.
+type canvas interface {
+	line(x1, y1, x2, y2 float64, stroke string, width float64)
+	polyline(pts []Point, stroke string, width float64)
+	rect(x, y, w, h float64, fill string)
+	text(x, y float64, s string, size float64, a anchor, fill string)
+	circle(x, y, r float64, fill string)
+}
+
+// scale maps data values onto pixel positions.
+type scale interface {
+	pos(v float64) float64
+}
+
+// linearScale maps values in [min, max] onto pixels in [lo, hi].
+type linearScale struct {
+	min, max float64
+	lo, hi   float64
+}
+
+func (s linearScale) pos(v float64) float64 {
+	if s.max == s.min {
+		return s.lo
+	}
+	return s.lo + (v-s.min)/(s.max-s.min)*(s.hi-s.lo)
+}
+
+// logScale maps values in [min, max] onto pixels in [lo, hi] using a
+// base 10 logarithm. Values below min are clamped to min.
+type logScale struct {
+	min, max float64
+	lo, hi   float64
+}
+
+func (s logScale) pos(v float64) float64 {
+	if v < s.min {
+		v = s.min
+	}
+	return linearScale{min: math.Log10(s.min), max: math.Log10(s.max), lo: s.lo, hi: s.hi}.pos(math.Log10(v))
+}
+
+// valueScale returns a scale and tick values for the value axis of a
+// chart with maximum value maxV, mapped onto pixels in [lo, hi].
+func valueScale(maxV float64, log bool, lo, hi float64) (scale, []float64) {
+	if log {
+		ticks := logTicks(maxV)
+		return logScale{min: ticks[0], max: ticks[len(ticks)-1], lo: lo, hi: hi}, ticks
+	}
+	ticks := niceTicks(0, maxV, 5)
+	return linearScale{min: ticks[0], max: ticks[len(ticks)-1], lo: lo, hi: hi}, ticks
+}
+
+// logTicks returns powers of ten from 1 through the first power of
+// ten greater than or equal to max.
+func logTicks(max float64) []float64 {
+	ticks := []float64{1}
+	for v := 10.0; ; v *= 10 {
+		ticks = append(ticks, v)
+		if v >= max {
+			return ticks
+		}
+	}
+}
+
+// niceTicks returns roughly n evenly spaced, round-numbered tick
+// values spanning [min, max]. The returned range may be slightly
+// wider than the input range.
+func niceTicks(min, max float64, n int) []float64 {
+	if max <= min {
+		max = min + 1
+	}
+	step := niceNum((max-min)/float64(n), true)
+	lo := math.Floor(min/step) * step
+	hi := math.Ceil(max/step) * step
+	var ticks []float64
+	for v := lo; v <= hi+step/2; v += step {
+		ticks = append(ticks, v)
+	}
+	return ticks
+}
+
+// niceNum rounds x to a "nice" number: 1, 2, 5 or 10 times a power
+// of ten.
+func niceNum(x float64, round bool) float64 {
+	exp := math.Floor(math.Log10(x))
+	f := x / math.Pow(10, exp)
+	var nf float64
+	switch {
+	case round && f < 1.5, !round && f <= 1:
+		nf = 1
+	case round && f < 3, !round && f <= 2:
+		nf = 2
+	case round && f < 7, !round && f <= 5:
+		nf = 5
+	default:
+		nf = 10
+	}
+	return nf * math.Pow(10, exp)
+}
+
+// dateSteps lists candidate intervals between date ticks, from
+// finest to coarsest. Each is specified as a number of years, months
+// and days to advance, along with the tick label format.
+var dateSteps = []struct {
+	years, months, days int
+	layout              string
+}{
+	{0, 0, 1, "Jan 2"},
+	{0, 0, 7, "Jan 2"},
+	{0, 1, 0, "Jan 2006"},
+	{0, 3, 0, "Jan 2006"},
+	{0, 6, 0, "Jan 2006"},
+	{1, 0, 0, "2006"},
+	{2, 0, 0, "2006"},
+	{5, 0, 0, "2006"},
+	{10, 0, 0, "2006"},
+}
+
+// maxDateTicks is the maximum number of date ticks on a time axis.
+const maxDateTicks = 8
+
+// dateTicks returns calendar-aligned tick values (as Unix seconds)
+// and labels spanning [min, max], choosing the finest interval which
+// yields no more than maxDateTicks ticks. Ticks are computed in UTC.
+func dateTicks(min, max float64) ([]float64, []string) {
+	start := time.Unix(int64(min), 0).UTC()
+	end := time.Unix(int64(max), 0).UTC()
+	for _, step := range dateSteps {
+		// Align the first tick to the step's calendar boundary.
+		t := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+		switch {
+		case step.years > 0:
+			t = time.Date(t.Year()-t.Year()%step.years, 1, 1, 0, 0, 0, 0, time.UTC)
+		case step.months > 0:
+			m := int(t.Month()) - 1
+			t = time.Date(t.Year(), time.Month(m-m%step.months+1), 1, 0, 0, 0, 0, time.UTC)
+		case step.days == 7:
+			t = t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7)) // Monday
+		}
+		var ticks []float64
+		var labels []string
+		for ; !t.After(end); t = t.AddDate(step.years, step.months, step.days) {
+			if t.Before(start) {
+				continue
+			}
+			ticks = append(ticks, float64(t.Unix()))
+			labels = append(labels, t.Format(step.layout))
+		}
+		if len(ticks) <= maxDateTicks {
+			return ticks, labels
+		}
+	}
+	return nil, nil
+}
+
+// formatNumber formats tick values compactly (e.g. 1.5k, 2M).
+func formatNumber(v float64) string {
+	abs := math.Abs(v)
+	switch {
+	case abs >= 1e6:
+		return trimZeros(fmt.Sprintf("%.1f", v/1e6)) + "M"
+	case abs >= 1e3:
+		return trimZeros(fmt.Sprintf("%.1f", v/1e3)) + "k"
+	case abs == math.Trunc(abs):
+		return fmt.Sprintf("%.0f", v)
+	}
+	return trimZeros(fmt.Sprintf("%.2f", v))
+}
+
+func trimZeros(s string) string {
+	for len(s) > 1 && s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+	if len(s) > 1 && s[len(s)-1] == '.' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+func seriesColor(s *Series, i int) string {
+	if len(s.Color) > 0 {
+		return s.Color
+	}
+	return Palette[i%len(Palette)]
+}
+
+func dims(width, height int) (float64, float64) {
+	w, h := float64(width), float64(height)
+	if w == 0 {
+		w = defaultWidth
+	}
+	if h == 0 {
+		h = defaultHeight
+	}
+	return w, h
+}
@@ -0,0 +1,141 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package chart
+
+import (
+	"bytes"
+	"flag"
+	"image/png"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update the golden files of testdata/golden")
+
+// testCharts returns one chart of each kind, exercising time and log
+// axes, annotations, horizontal bars and text requiring escaping.
+func testCharts() map[string]Chart {
+	start := time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC)
+	stars := &Series{Name: "Stars"}
+	forecast := &Series{Name: "Forecast <est>", Color: Palette[1]}
+	for i := 0; i <= 60; i++ {
+		stars.Points = append(stars.Points, TimePoint(start.AddDate(0, 0, i), float64(i*i)))
+	}
+	for i := 60; i <= 90; i++ {
+		forecast.Points = append(forecast.Points, TimePoint(start.AddDate(0, 0, i), float64(3600+120*(i-60))))
+	}
+	return map[string]Chart{
+		"line": &LineChart{
+			Title:       "Stars & forecast",
+			YLabel:      "stars",
+			TimeX:       true,
+			Series:      []*Series{stars, forecast},
+			Annotations: []Annotation{TimeAnnotation(start.AddDate(0, 0, 30), "<b>launch</b> & HN")},
+		},
+		"line_log": &LineChart{Title: "Log stars", YLabel: "stars", TimeX: true, LogY: true, Series: []*Series{stars}},
+		"bar": &BarChart{
+			Title:  "Correlation histogram",
+			YLabel: "repos",
+			Labels: []string{"1", "2-3", "4-7", "8+"},
+			Values: []float64{120, 45, 12, 3},
+		},
+		"bar_horizontal": &BarChart{
+			Title:      "Top correlated repos",
+			Horizontal: true,
+			Labels:     []string{"golang/go", "kubernetes/kubernetes", "evil/<script>"},
+			Values:     []float64{30, 20, 10},
+		},
+		"heatmap": &HeatMap{
+			Title:     "Stars by hour",
+			RowLabels: []string{"Mon", "Tue"},
+			ColLabels: []string{"00", "01", "02"},
+			Values:    [][]float64{{0, 1, 2}, {3, 4, 5}},
+		},
+	}
+}
+
+func TestSVGGolden(t *testing.T) {
+	for name, c := range testCharts() {
+		var buf bytes.Buffer
+		if err := c.SVG(&buf); err != nil {
+			t.Fatal(err)
+		}
+		golden := filepath.Join("testdata", "golden", name+".svg")
+		if *update {
+			if err := ioutil.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("%s differs from its golden file; got:\n%s", name, buf.Bytes())
+		}
+	}
+}
+
+func TestSVGEscapesText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testCharts()["line"].SVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	for _, s := range []string{"<b>", "& HN", "<est>"} {
+		if strings.Contains(svg, s) {
+			t.Errorf("expected %q to be escaped", s)
+		}
+	}
+	for _, s := range []string{"&lt;b&gt;launch&lt;/b&gt; &amp; HN", "Stars &amp; forecast", "Forecast &lt;est&gt;"} {
+		if !strings.Contains(svg, s) {
+			t.Errorf("expected %q in SVG", s)
+		}
+	}
+}
+
+func TestPNGDecodes(t *testing.T) {
+	for name, c := range testCharts() {
+		var buf bytes.Buffer
+		if err := c.PNG(&buf); err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		b := img.Bounds()
+		if b.Dx() != defaultWidth || b.Dy() != defaultHeight {
+			t.Errorf("%s: expected %dx%d image; got %dx%d", name, defaultWidth, defaultHeight, b.Dx(), b.Dy())
+		}
+		// Something must have been drawn.
+		first := img.At(0, 0)
+		drawn := false
+		for y := b.Min.Y; y < b.Max.Y && !drawn; y++ {
+			for x := b.Min.X; x < b.Max.X && !drawn; x++ {
+				drawn = img.At(x, y) != first
+			}
+		}
+		if !drawn {
+			t.Errorf("%s: image is blank", name)
+		}
+	}
+}
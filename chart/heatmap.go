@@ -0,0 +1,96 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package chart
+
+import (
+	"fmt"
+	"io"
+)
+
+// A HeatMap shades each cell of a grid by its value, from white for
+// zero to a dark blue for the maximum value.
+type HeatMap struct {
+	Title     string
+	Width     int // Pixels; defaults to 720
+	Height    int // Pixels; defaults to 360
+	RowLabels []string
+	ColLabels []string
+	Values    [][]float64 // Indexed by row, then column
+}
+
+// SVG writes the chart as an SVG document.
+func (hm *HeatMap) SVG(w io.Writer) error {
+	width, height := dims(hm.Width, hm.Height)
+	sc := newSVGCanvas(width, height)
+	hm.draw(sc, width, height)
+	return sc.writeTo(w)
+}
+
+// PNG writes the chart as a PNG image.
+func (hm *HeatMap) PNG(w io.Writer) error {
+	width, height := dims(hm.Width, hm.Height)
+	pc := newPNGCanvas(width, height)
+	hm.draw(pc, width, height)
+	return pc.writeTo(w)
+}
+
+func (hm *HeatMap) draw(c canvas, width, height float64) {
+	drawFrame(c, hm.Title, "", width, height)
+	rows := len(hm.Values)
+	if rows == 0 || len(hm.Values[0]) == 0 {
+		return
+	}
+	cols := len(hm.Values[0])
+	maxV := 0.0
+	for _, row := range hm.Values {
+		for _, v := range row {
+			if v > maxV {
+				maxV = v
+			}
+		}
+	}
+	cw := (width - marginLeft - marginRight) / float64(cols)
+	ch := (height - marginTop - marginBottom) / float64(rows)
+	for i, row := range hm.Values {
+		y := marginTop + ch*float64(i)
+		for j, v := range row {
+			frac := 0.0
+			if maxV > 0 {
+				frac = v / maxV
+			}
+			c.rect(marginLeft+cw*float64(j)+0.5, y+0.5, cw-1, ch-1, shade(frac))
+		}
+		if i < len(hm.RowLabels) {
+			c.text(marginLeft-6, y+ch/2+4, hm.RowLabels[i], labelSize, anchorEnd, textColor)
+		}
+	}
+	// Label at most ~12 columns to avoid overlapping text.
+	every := cols/12 + 1
+	for j := 0; j < cols && j < len(hm.ColLabels); j += every {
+		c.text(marginLeft+cw*(float64(j)+0.5), height-marginBottom+16, hm.ColLabels[j], labelSize, anchorMiddle, textColor)
+	}
+}
+
+// shade interpolates between white and dark blue.
+func shade(frac float64) string {
+	from, to := [3]float64{0xf7, 0xfb, 0xff}, [3]float64{0x08, 0x30, 0x6b}
+	var rgb [3]int
+	for i := range rgb {
+		rgb[i] = int(from[i] + (to[i]-from[i])*frac + 0.5)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+}
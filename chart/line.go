@@ -0,0 +1,152 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package chart
+
+import (
+	"io"
+	"math"
+)
+
+// A LineChart plots one or more series as lines against shared axes.
+type LineChart struct {
+	Title       string
+	YLabel      string
+	Width       int  // Pixels; defaults to 720
+	Height      int  // Pixels; defaults to 360
+	TimeX       bool // X values are Unix seconds, labeled as dates
+	LogY        bool // Plot the y axis on a log scale
+	Series      []*Series
+	Annotations []Annotation
+}
+
+// SVG writes the chart as an SVG document.
+func (lc *LineChart) SVG(w io.Writer) error {
+	width, height := dims(lc.Width, lc.Height)
+	sc := newSVGCanvas(width, height)
+	lc.draw(sc, width, height)
+	return sc.writeTo(w)
+}
+
+// PNG writes the chart as a PNG image.
+func (lc *LineChart) PNG(w io.Writer) error {
+	width, height := dims(lc.Width, lc.Height)
+	pc := newPNGCanvas(width, height)
+	lc.draw(pc, width, height)
+	return pc.writeTo(w)
+}
+
+func (lc *LineChart) draw(c canvas, width, height float64) {
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	maxY := 0.0
+	for _, s := range lc.Series {
+		for _, p := range s.Points {
+			minX = math.Min(minX, p.X)
+			maxX = math.Max(maxX, p.X)
+			maxY = math.Max(maxY, p.Y)
+		}
+	}
+	if math.IsInf(minX, 1) {
+		minX, maxX = 0, 1
+	}
+	xs := linearScale{min: minX, max: maxX, lo: marginLeft, hi: width - marginRight}
+	ys, yTicks := valueScale(maxY, lc.LogY, height-marginBottom, marginTop)
+
+	drawFrame(c, lc.Title, lc.YLabel, width, height)
+	for _, v := range yTicks {
+		y := ys.pos(v)
+		c.line(marginLeft, y, width-marginRight, y, gridColor, 1)
+		c.text(marginLeft-6, y+4, formatNumber(v), labelSize, anchorEnd, textColor)
+	}
+	xTicks, xLabels := lc.xTicks(minX, maxX)
+	for i, v := range xTicks {
+		x := xs.pos(v)
+		c.line(x, height-marginBottom, x, height-marginBottom+4, axisColor, 1)
+		c.text(x, height-marginBottom+16, xLabels[i], labelSize, anchorMiddle, textColor)
+	}
+	c.line(marginLeft, height-marginBottom, width-marginRight, height-marginBottom, axisColor, 1)
+	c.line(marginLeft, marginTop, marginLeft, height-marginBottom, axisColor, 1)
+
+	for i, s := range lc.Series {
+		pts := make([]Point, len(s.Points))
+		for j, p := range s.Points {
+			pts[j] = Point{X: xs.pos(p.X), Y: ys.pos(p.Y)}
+		}
+		c.polyline(pts, seriesColor(s, i), 1.5)
+	}
+	for _, a := range lc.Annotations {
+		if a.X < minX || a.X > maxX {
+			continue
+		}
+		drawAnnotation(c, xs.pos(a.X), a.Label, height)
+	}
+	drawLegend(c, lc.Series, width)
+}
+
+// xTicks returns tick values and labels for the x axis.
+func (lc *LineChart) xTicks(min, max float64) ([]float64, []string) {
+	if lc.TimeX {
+		return dateTicks(min, max)
+	}
+	var ticks []float64
+	var labels []string
+	for _, v := range niceTicks(min, max, 6) {
+		if v >= min && v <= max {
+			ticks = append(ticks, v)
+			labels = append(labels, formatNumber(v))
+		}
+	}
+	return ticks, labels
+}
+
+// drawAnnotation draws a dashed vertical marker at x with its label
+// at the top of the plot area.
+func drawAnnotation(c canvas, x float64, label string, height float64) {
+	const dash = 4
+	for y := float64(marginTop); y < height-marginBottom; y += 2 * dash {
+		c.line(x, y, x, math.Min(y+dash, height-marginBottom), annotationColor, 1)
+	}
+	c.circle(x, marginTop, 3, annotationColor)
+	c.text(x+5, marginTop+10, label, labelSize, anchorStart, annotationColor)
+}
+
+// drawFrame draws the chart background, title and y axis label.
+func drawFrame(c canvas, title, yLabel string, width, height float64) {
+	c.rect(0, 0, width, height, "#ffffff")
+	if len(title) > 0 {
+		c.text(width/2, marginTop/2+4, title, titleSize, anchorMiddle, textColor)
+	}
+	if len(yLabel) > 0 {
+		c.text(8, marginTop-8, yLabel, labelSize, anchorStart, textColor)
+	}
+}
+
+// drawLegend draws a legend in the top right corner when there is
+// more than one series. Unnamed series are omitted.
+func drawLegend(c canvas, series []*Series, width float64) {
+	if len(series) < 2 {
+		return
+	}
+	y := float64(marginTop + 8)
+	for i, s := range series {
+		if len(s.Name) == 0 {
+			continue
+		}
+		c.rect(width-marginRight-120, y-8, 10, 10, seriesColor(s, i))
+		c.text(width-marginRight-104, y+1, s.Name, labelSize, anchorStart, textColor)
+		y += 16
+	}
+}
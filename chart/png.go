@@ -0,0 +1,178 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// pngCanvas rasterizes onto an RGBA image. Lines and shapes are
+// anti-aliased; text is drawn using a fixed-size bitmap font, so the
+// requested text size is ignored.
+type pngCanvas struct {
+	img *image.RGBA
+	r   *vector.Rasterizer
+	// path holds the closed subpaths to be filled.
+	path [][]Point
+}
+
+func newPNGCanvas(width, height float64) *pngCanvas {
+	w, h := int(width), int(height)
+	return &pngCanvas{
+		img: image.NewRGBA(image.Rect(0, 0, w, h)),
+		r:   vector.NewRasterizer(w, h),
+	}
+}
+
+// moveTo starts a new subpath at x, y.
+func (pc *pngCanvas) moveTo(x, y float64) {
+	pc.path = append(pc.path, []Point{{X: x, Y: y}})
+}
+
+// lineTo extends the current subpath to x, y.
+func (pc *pngCanvas) lineTo(x, y float64) {
+	last := len(pc.path) - 1
+	pc.path[last] = append(pc.path[last], Point{X: x, Y: y})
+}
+
+// fill draws the accumulated path using the specified color and
+// clears it. Only the path's bounding box, clipped to the image, is
+// rasterized, as the cost of rasterizing is proportional to its area
+// and most shapes are small.
+func (pc *pngCanvas) fill(hex string) {
+	defer func() { pc.path = pc.path[:0] }()
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, sub := range pc.path {
+		for _, p := range sub {
+			minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+			maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+		}
+	}
+	if minX > maxX {
+		return
+	}
+	b := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+	b = b.Intersect(pc.img.Bounds())
+	if b.Empty() {
+		return
+	}
+	// The rasterizer's origin is drawn at the bounding box's origin.
+	pc.r.Reset(b.Dx(), b.Dy())
+	ox, oy := float64(b.Min.X), float64(b.Min.Y)
+	for _, sub := range pc.path {
+		pc.r.MoveTo(float32(sub[0].X-ox), float32(sub[0].Y-oy))
+		for _, p := range sub[1:] {
+			pc.r.LineTo(float32(p.X-ox), float32(p.Y-oy))
+		}
+		pc.r.ClosePath()
+	}
+	pc.r.Draw(pc.img, b, image.NewUniform(parseColor(hex)), image.Point{})
+}
+
+func (pc *pngCanvas) segment(x1, y1, x2, y2, width float64) {
+	dx, dy := x2-x1, y2-y1
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return
+	}
+	// Offset by half the width along the segment's normal.
+	nx, ny := -dy/l*width/2, dx/l*width/2
+	pc.moveTo(x1+nx, y1+ny)
+	pc.lineTo(x2+nx, y2+ny)
+	pc.lineTo(x2-nx, y2-ny)
+	pc.lineTo(x1-nx, y1-ny)
+}
+
+func (pc *pngCanvas) line(x1, y1, x2, y2 float64, stroke string, width float64) {
+	pc.segment(x1, y1, x2, y2, width)
+	pc.fill(stroke)
+}
+
+func (pc *pngCanvas) polyline(pts []Point, stroke string, width float64) {
+	// Each segment is filled separately; filling them as one path
+	// would leave gaps where overlapping segments cancel out.
+	for i := 1; i < len(pts); i++ {
+		pc.segment(pts[i-1].X, pts[i-1].Y, pts[i].X, pts[i].Y, width)
+		pc.fill(stroke)
+	}
+}
+
+func (pc *pngCanvas) rect(x, y, w, h float64, fill string) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+	pc.moveTo(x, y)
+	pc.lineTo(x+w, y)
+	pc.lineTo(x+w, y+h)
+	pc.lineTo(x, y+h)
+	pc.fill(fill)
+}
+
+func (pc *pngCanvas) circle(x, y, r float64, fill string) {
+	const n = 16
+	pc.moveTo(x+r, y)
+	for i := 1; i < n; i++ {
+		a := 2 * math.Pi * float64(i) / n
+		pc.lineTo(x+r*math.Cos(a), y+r*math.Sin(a))
+	}
+	pc.fill(fill)
+}
+
+func (pc *pngCanvas) text(x, y float64, s string, size float64, a anchor, fill string) {
+	d := &font.Drawer{
+		Dst:  pc.img,
+		Src:  image.NewUniform(parseColor(fill)),
+		Face: basicfont.Face7x13,
+	}
+	w := float64(d.MeasureString(s).Round())
+	switch a {
+	case anchorMiddle:
+		x -= w / 2
+	case anchorEnd:
+		x -= w
+	}
+	d.Dot = fixed.P(int(x), int(y))
+	d.DrawString(s)
+}
+
+func (pc *pngCanvas) writeTo(w io.Writer) error {
+	return png.Encode(w, pc.img)
+}
+
+// parseColor parses a "#rrggbb" hex color, returning black if the
+// color is malformed.
+func parseColor(hex string) color.Color {
+	if len(hex) != 7 || hex[0] != '#' {
+		return color.Black
+	}
+	v, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return color.Black
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
@@ -0,0 +1,171 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package chart
+
+import (
+	"strconv"
+
+	"github.com/spencerkimball/stargazers/analyze"
+)
+
+// Options control how charts of analysis results are drawn.
+type Options struct {
+	LogY        bool         // Plot values on a log scale
+	Annotations []Annotation // Markers for time series charts
+}
+
+// A NamedChart pairs a chart with the base name of its output file.
+type NamedChart struct {
+	Name  string
+	Chart Chart
+}
+
+// FromResults returns charts of the time series analyses and the
+// correlation histograms.
+func FromResults(res *analyze.Results, opts Options) []NamedChart {
+	cs := CumulativeStars(res.CumulativeStars)
+	cs.LogY, cs.Annotations = opts.LogY, opts.Annotations
+	charts := []NamedChart{{Name: res.CumulativeStars.Name(), Chart: cs}}
+	if len(res.Forecast.Path) > 0 {
+		fc := Forecast(res.CumulativeStars, res.Forecast)
+		fc.LogY, fc.Annotations = opts.LogY, opts.Annotations
+		charts = append(charts, NamedChart{Name: res.Forecast.Name(), Chart: fc})
+	}
+	for _, a := range AttributesByTime(res.AttributesByTime) {
+		a.LogY, a.Annotations = opts.LogY, opts.Annotations
+		charts = append(charts, NamedChart{Name: res.AttributesByTime.Name() + "_" + a.Name, Chart: a.LineChart})
+	}
+	charts = append(charts, NamedChart{Name: res.StarHeatmap.Name(), Chart: StarHeatmap(res.StarHeatmap)})
+	for _, h := range []*analyze.CorrelationHistogramResult{res.StarredHistogram, res.SubscribedHistogram} {
+		bc := CorrelationHistogram(h)
+		bc.LogY = opts.LogY
+		charts = append(charts, NamedChart{Name: h.Name(), Chart: bc})
+	}
+	return charts
+}
+
+// CumulativeStars returns a line chart of cumulative stars by day.
+func CumulativeStars(res *analyze.CumulativeStarsResult) *LineChart {
+	s := &Series{Name: "Stars"}
+	for _, d := range res.Days {
+		s.Points = append(s.Points, TimePoint(d.Date, float64(d.Cumulative)))
+	}
+	return &LineChart{Title: "Cumulative stars", YLabel: "stars", TimeX: true, Series: []*Series{s}}
+}
+
+// Forecast returns a line chart of cumulative stars followed by the
+// forecast and its 95% prediction interval, starting at the as-of
+// day.
+func Forecast(cum *analyze.CumulativeStarsResult, res *analyze.ForecastResult) *LineChart {
+	lc := CumulativeStars(cum)
+	lc.Title = "Star forecast (" + res.Model + ")"
+	f := &Series{Name: "Forecast", Color: Palette[1]}
+	lo := &Series{Name: "95% interval", Color: Palette[2]}
+	hi := &Series{Color: Palette[2]}
+	if len(res.Path) > 0 {
+		start := TimePoint(res.Start, res.Current)
+		f.Points, lo.Points, hi.Points = []Point{start}, []Point{start}, []Point{start}
+	}
+	for _, p := range res.Path {
+		f.Points = append(f.Points, TimePoint(p.Date, p.Forecast))
+		lo.Points = append(lo.Points, TimePoint(p.Date, p.Lower95))
+		hi.Points = append(hi.Points, TimePoint(p.Date, p.Upper95))
+	}
+	lc.Series = append(lc.Series, f, lo, hi)
+	return lc
+}
+
+// StarHeatmap returns a heat map of stars by day of the week and
+// hour of the day.
+func StarHeatmap(res *analyze.StarHeatmapResult) *HeatMap {
+	hm := &HeatMap{Title: "Stars by hour of week (" + res.Location + ")", ColLabels: res.Header()[1:]}
+	for _, row := range res.Rows() {
+		hm.RowLabels = append(hm.RowLabels, row[0][:3])
+	}
+	for _, day := range res.Stars {
+		var values []float64
+		for _, n := range day {
+			values = append(values, float64(n))
+		}
+		hm.Values = append(hm.Values, values)
+	}
+	return hm
+}
+
+// An AttributeChart is a line chart of a single averaged stargazer
+// attribute over time.
+type AttributeChart struct {
+	*LineChart
+	Name string // e.g. "avg_followers"
+}
+
+// AttributesByTime returns a line chart of new stars and of each
+// averaged attribute, by sample period.
+func AttributesByTime(res *analyze.AttributesByTimeResult) []AttributeChart {
+	attrs := []struct {
+		name, title string
+		value       func(*analyze.AttributesSample) float64
+	}{
+		{"new_stars", "New stars", func(s *analyze.AttributesSample) float64 { return float64(s.NewStars) }},
+		{"avg_age", "Avg age (days)", func(s *analyze.AttributesSample) float64 { return s.AvgAge }},
+		{"avg_followers", "Avg followers", func(s *analyze.AttributesSample) float64 { return s.AvgFollowers }},
+		{"avg_commits", "Avg commits", func(s *analyze.AttributesSample) float64 { return s.AvgCommits }},
+	}
+	var charts []AttributeChart
+	for i, a := range attrs {
+		s := &Series{Name: a.title, Color: Palette[i%len(Palette)]}
+		for _, sample := range res.Samples {
+			s.Points = append(s.Points, TimePoint(sample.Date, a.value(sample)))
+		}
+		charts = append(charts, AttributeChart{
+			LineChart: &LineChart{Title: a.title, TimeX: true, Series: []*Series{s}},
+			Name:      a.name,
+		})
+	}
+	return charts
+}
+
+// CorrelationHistogram returns a bar chart of the number of repos
+// at each correlation count, in ascending order of correlation.
+func CorrelationHistogram(res *analyze.CorrelationHistogramResult) *BarChart {
+	bc := &BarChart{Title: "Correlation histogram (" + res.ListType + ")", YLabel: "repos"}
+	// Histogram bins are ordered by descending correlation.
+	for i := len(res.Bins) - 1; i >= 0; i-- {
+		b := res.Bins[i]
+		bc.Labels = append(bc.Labels, strconv.Itoa(b.Correlation))
+		bc.Values = append(bc.Values, float64(b.Count))
+	}
+	return bc
+}
+
+// CorrelatedRepos returns a horizontal bar chart of the n most
+// correlated repos.
+func CorrelatedRepos(res *analyze.CorrelatedReposResult, n int) *BarChart {
+	verb := "star"
+	if res.ListType == "subscribed" {
+		verb = "watch"
+	}
+	bc := &BarChart{Title: "Stargazers who also " + verb, Horizontal: true, Height: 480}
+	for i, r := range res.Repos {
+		if i >= n {
+			break
+		}
+		bc.Labels = append(bc.Labels, r.Name)
+		bc.Values = append(bc.Values, float64(r.Count))
+	}
+	return bc
+}
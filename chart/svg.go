@@ -0,0 +1,77 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// svgCanvas accumulates SVG elements in a buffer.
+type svgCanvas struct {
+	width, height float64
+	buf           bytes.Buffer
+}
+
+func newSVGCanvas(width, height float64) *svgCanvas {
+	return &svgCanvas{width: width, height: height}
+}
+
+func (sc *svgCanvas) line(x1, y1, x2, y2 float64, stroke string, width float64) {
+	fmt.Fprintf(&sc.buf, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="%g"/>`+"\n",
+		x1, y1, x2, y2, stroke, width)
+}
+
+func (sc *svgCanvas) polyline(pts []Point, stroke string, width float64) {
+	coords := make([]string, len(pts))
+	for i, p := range pts {
+		coords[i] = fmt.Sprintf("%.1f,%.1f", p.X, p.Y)
+	}
+	fmt.Fprintf(&sc.buf, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%g" stroke-linejoin="round"/>`+"\n",
+		strings.Join(coords, " "), stroke, width)
+}
+
+func (sc *svgCanvas) rect(x, y, w, h float64, fill string) {
+	fmt.Fprintf(&sc.buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n", x, y, w, h, fill)
+}
+
+func (sc *svgCanvas) text(x, y float64, s string, size float64, a anchor, fill string) {
+	anchors := [...]string{"start", "middle", "end"}
+	fmt.Fprintf(&sc.buf, `<text x="%.1f" y="%.1f" font-size="%g" text-anchor="%s" fill="%s">%s</text>`+"\n",
+		x, y, size, anchors[a], fill, html.EscapeString(s))
+}
+
+func (sc *svgCanvas) circle(x, y, r float64, fill string) {
+	fmt.Fprintf(&sc.buf, `<circle cx="%.1f" cy="%.1f" r="%g" fill="%s"/>`+"\n", x, y, r, fill)
+}
+
+// writeTo writes the complete SVG document. The document has no XML
+// prolog so that it may also be inlined into HTML.
+func (sc *svgCanvas) writeTo(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g" font-family="sans-serif">`+"\n",
+		sc.width, sc.height, sc.width, sc.height); err != nil {
+		return err
+	}
+	if _, err := sc.buf.WriteTo(w); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "</svg>\n")
+	return err
+}
@@ -32,37 +32,157 @@ var AnalyzeCmd = &cobra.Command{
 	Long: `
 
 Analyzes the previously fetched and saved GitHub stargazer data. The
-following analyses are run:
+following analyses are run, with results written in the --format
+selected (csv, json, ndjson or markdown):
 
     - Cumulative stars (week timestamp and star count)
-    - Correlated repos (count of occurrences of other starred & subscribed repos)
+    - Correlated repos (count of occurrences of other starred & subscribed repos,
+      with lift, Jaccard, PMI and z-score normalized by each repo's stargazers),
+      ranked by --correlation-metric and filtered by --min-support
     - Correlation histogram (50 bins of occurrence counts)
-    - Stargazer report (name, email(?), date starred, correlation score,
+    - Correlated owners (correlated starred & subscribed repos rolled up by
+      owner, with occurrences, unique stargazers, stargazers' commits to the
+      owner's repos and top repos per owner)
+    - Influence (PageRank of stargazers over the follower graph, with random
+      jumps favoring stargazers by --stargazer-jump-weight)
+    - Followers (follower counts, shared followers and influence per stargazer)
+    - Committers (commits, additions & deletions per stargazer, with top repos,
+      commits to this repo, and the share of other commits to the most
+      correlated repos versus elsewhere)
+    - Committer matrix (commits by repo and committer)
+    - Attributes by time (weekly average age, followers & commits)
+    - Attribute distributions (median, 10th, 90th and 99th percentiles and
+      Gini coefficient of account age, followers, public repos, commits and
+      starred repos, with 95% bootstrap confidence intervals, and log-binned
+      histograms; overall and by period of stars)
+    - Stargazer report (name, email, date starred, correlation score,
       correlated repos, raw activity, raw activity repos, correlated activity,
-      correlated activity repos
+      correlated activity repos), sorted by --stargazer-sort
+    - Similar repos (item-item cosine similarity of starred repos to this repo)
+    - Recommendations (repos each stargazer is likely to star next, by
+      item-item collaborative filtering over all stargazers' starred repos)
+    - Communities (Louvain clusters of the stargazer follower graph, with
+      members, dominant employers & locations, and distinctive starred repos)
+    - Star bursts (episodes of anomalous daily stars against a seasonal
+      baseline, profiling each burst's stargazers against the baseline's)
+    - Star change points (sustained shifts in the daily star rate, by CUSUM)
+    - Star quality (per-account suspicion score with reasons, e.g. new or
+      empty accounts, clustered stars and near-duplicate starred lists), and
+      cumulative stars excluding stars scoring at least --suspicion-threshold
+    - Star forecast (cumulative stars 30, 90 and 365 days after --as-of,
+      with 80% and 95% prediction intervals, from the linear, log-linear or
+      Holt-Winters model with the lowest backtest error)
+    - Locations (each stargazer's location normalized to city, region and
+      country with a confidence score, using an offline gazetteer)
+    - Geography (new and cumulative stars by country and month, and a
+      GeoJSON map of stargazers by place in geography.geojson)
+    - Employers (each stargazer's company normalized by alias rules,
+      --company-overrides and email, organization and blog domains; and
+      stargazers, committers and top contributors by employer, with the
+      monthly trend of the top employers)
+    - Star heatmap (stars by hour of the week in the --timezone)
+    - Timezones (each stargazer's UTC offset from their location or, failing
+      that, inferred from their star timestamps, including those fetched
+      with --starred-times; the distribution of offsets; and the expected
+      activity of the audience by UTC hour, for timing announcements)
+    - Star cohorts (stargazers grouped by month of first star, with each
+      cohort's retention across fetches, engagement with the repo, activity
+      recency and profile; and a retention table by fetch snapshot)
+    - Keywords (top TF-IDF words and bigrams of stargazer bios and of the
+      descriptions of correlated starred repos)
+    - Roles (each stargazer's role, e.g. student, researcher, SRE, data
+      engineer or founder, classified by keywords in their bio using the
+      "roles" taxonomy of the --config file if specified; and the role mix
+      overall and by period of stars)
+    - Leads (stargazers ranked by a weighted score of whether they're
+      hireable, their commits and influence, and whether their location,
+      role and language profile match preferences, with each factor's
+      contribution; and the top leads on shortlists of segment expressions,
+      with weights, preferences and shortlists from "lead_scoring" in the
+      --config file)
+
+Analyses are as of the --as-of reference time, which defaults to when the
+saved state was fetched; stars after it are excluded, and ages and
+recency are measured to it. Ties are broken consistently, so rerunning an
+analysis of the same saved state gives byte-identical results.
+
+Time series (cumulative stars, attributes by time) and tables by period
+(geography, employer trend, star cohorts, attribute distributions, roles)
+use calendar periods selected by --granularity (daily, weekly starting
+Monday, monthly or quarterly) in the --timezone, labeled by ISO 8601 start
+date; time series include periods without stars, filled with zeros. Star
+bursts, change points, the forecast and the star heatmap likewise use
+days and hours in the --timezone. The default legacy granularity keeps
+the original output: cumulative stars by UTC day, skipping days without
+stars, attributes by seven day periods from the first star, MM/DD/YYYY
+dates in local time, tables by UTC month (YYYY-MM), and UTC days and
+hours elsewhere.
+
+With --where, the analyses run over only the stargazers matching an
+expression of stargazer fields, such as:
+
+    followers > 100 && company ~ "google" && starred_at >= 2024-01-01
+
+Strings compare without regard to case, with ~ and !~ matching a regular
+expression; numbers and dates (YYYY-MM-DD) compare with ==, !=, <, <=, >
+and >=. Comparisons combine with &&, || and !, and group in parentheses.
+Fields are login, name, company, employer, location, country,
+country_code, email, blog, bio, type, followers, following, public_repos,
+public_gists, starred, subscribed, orgs, contributed, commits, additions,
+deletions, age_at_star (days), starred_at, created_at, updated_at,
+hireable and site_admin. Named segments are defined in the "segments" map
+of the --config file and selected or referenced as @name. Results for a
+segment are written to its segments/<name> subdirectory; other expressions
+are named by their letters and digits and a short hash of the expression.
+
+Users listed in the --exclude file, one login per line, have opted out:
+they're excluded from all analyses, and from future fetches.
+
+With --redact, results may be shared outside the team: logins are
+replaced by hashes salted with --redact-salt; names, emails, bios, blogs
+and avatar and profile URLs are dropped; locations are generalized to
+countries and companies to normalized employers. Countries, employers,
+organizations and starred, subscribed and contributed repos shared by
+fewer than --min-group-size stargazers are dropped, as are result rows,
+such as cohorts, communities, monthly countries and employers, roles,
+timezones and histogram bins, which describe fewer stargazers. Segments
+selected with --where still see unredacted fields.
 `,
-	Example: `  stargazers analyze --repo=cockroachdb/cockroach`,
+	Example: `  stargazers analyze --repo=cockroachdb/cockroach --format=json --where='followers > 100'`,
 	RunE:    RunAnalyze,
 }
 
+func init() {
+	AnalyzeCmd.Flags().StringVarP(&Format, "format", "f", "csv", FormatDesc)
+	AddAnalysisFlags(AnalyzeCmd)
+}
+
 // RunAnalyze fetches saved stargazer info for the specified repo and
 // runs the analysis reports.
 func RunAnalyze(cmd *cobra.Command, args []string) error {
 	if len(Repo) == 0 {
 		return errors.New("repository not specified; use --repo=:owner/:repo")
 	}
-	log.Printf("fetching saved GitHub stargazer data for repository %s", Repo)
 	fetchCtx := &fetch.Context{
 		Repo:     Repo,
 		CacheDir: CacheDir,
 	}
-	sg, rs, err := fetch.LoadState(fetchCtx)
+	renderer, err := analyze.NewRenderer(Format)
 	if err != nil {
-		log.Printf("failed to load saved stargazer data: %s", err)
-		return nil
+		return err
+	}
+	sg, rs, opts, segmentName, err := loadAnalysis(fetchCtx)
+	if err != nil {
+		return err
 	}
 	log.Printf("analyzing GitHub data for repository %s", Repo)
-	if err := analyze.RunAll(fetchCtx, sg, rs); err != nil {
+	analyzeCtx := &analyze.Context{
+		Context:  fetchCtx,
+		Options:  opts,
+		Renderer: renderer,
+		Segment:  segmentName,
+	}
+	if err := analyze.RunAll(analyzeCtx, sg, rs); err != nil {
 		log.Printf("failed to query stargazer data: %s", err)
 		return nil
 	}
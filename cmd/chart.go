@@ -0,0 +1,140 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spencerkimball/stargazers/analyze"
+	"github.com/spencerkimball/stargazers/chart"
+	"github.com/spencerkimball/stargazers/fetch"
+	"github.com/spf13/cobra"
+)
+
+// ChartCmd renders charts from previously fetched GitHub stargazer data.
+var ChartCmd = &cobra.Command{
+	Use:   "chart --repo=:owner/:repo",
+	Short: "render charts of previously fetched stargazer data",
+	Long: `
+Renders charts of the time series analyses (cumulative stars and
+stargazer attributes by time) and of the correlation histograms. Each
+chart is written to the repo-specific --cache subdirectory as SVG or
+PNG, according to --image-format.
+
+Time series charts may be annotated with markers for notable dates
+using --annotate=YYYY-MM-DD=label, which may be repeated.
+`,
+	Example: `  stargazers chart --repo=cockroachdb/cockroach --image-format=png --annotate="2015-07-27=HN launch"`,
+	RunE:    RunChart,
+}
+
+// ImageFormat specifies the image format for charts.
+var ImageFormat string
+
+// ImageFormatDesc describes usage.
+const ImageFormatDesc = "image format for charts: svg or png"
+
+// LogScale specifies that chart values should use a log scale.
+var LogScale bool
+
+// LogScaleDesc describes usage.
+const LogScaleDesc = "plot chart values on a log scale"
+
+// Annotations specifies date markers for time series charts.
+var Annotations []string
+
+// AnnotationsDesc describes usage.
+const AnnotationsDesc = "mark a date on time series charts, formatted as YYYY-MM-DD=label; may be repeated"
+
+func init() {
+	ChartCmd.Flags().StringVar(&ImageFormat, "image-format", "svg", ImageFormatDesc)
+	ChartCmd.Flags().BoolVar(&LogScale, "log-scale", false, LogScaleDesc)
+	ChartCmd.Flags().StringArrayVar(&Annotations, "annotate", nil, AnnotationsDesc)
+	AddAnalysisFlags(ChartCmd)
+}
+
+// RunChart loads saved stargazer info for the specified repo, runs
+// the analyses and renders charts of the results.
+func RunChart(cmd *cobra.Command, args []string) error {
+	if len(Repo) == 0 {
+		return errors.New("repository not specified; use --repo=:owner/:repo")
+	}
+	if ImageFormat != "svg" && ImageFormat != "png" {
+		return fmt.Errorf("unknown image format %q; must be svg or png", ImageFormat)
+	}
+	opts := chart.Options{LogY: LogScale}
+	for _, a := range Annotations {
+		parts := strings.SplitN(a, "=", 2)
+		t, err := time.Parse("2006-01-02", parts[0])
+		if err != nil {
+			return fmt.Errorf("invalid annotation %q: %s", a, err)
+		}
+		label := ""
+		if len(parts) == 2 {
+			label = parts[1]
+		}
+		opts.Annotations = append(opts.Annotations, chart.TimeAnnotation(t, label))
+	}
+	fetchCtx := &fetch.Context{
+		Repo:     Repo,
+		CacheDir: CacheDir,
+	}
+	sg, rs, analyzeOpts, segmentName, err := loadAnalysis(fetchCtx)
+	if err != nil {
+		return err
+	}
+	res, err := analyze.ComputeAll(sg, rs, analyzeOpts)
+	if err != nil {
+		log.Printf("failed to analyze stargazer data: %s", err)
+		return nil
+	}
+	dir, err := analyze.MakeOutputDir(fetchCtx, segmentName)
+	if err != nil {
+		return err
+	}
+	for _, nc := range chart.FromResults(res, opts) {
+		if err := writeChart(dir, nc, ImageFormat); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeChart(dir string, nc chart.NamedChart, format string) error {
+	filename := filepath.Join(dir, fmt.Sprintf("%s.%s", nc.Name, format))
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if format == "png" {
+		err = nc.Chart.PNG(f)
+	} else {
+		err = nc.Chart.SVG(f)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render chart %s: %s", nc.Name, err)
+	}
+	log.Printf("wrote %s chart to %s", nc.Name, filename)
+	return nil
+}
@@ -30,7 +30,8 @@ var ClearCmd = &cobra.Command{
 	Short: "clear cached GitHub API responses",
 	Long: `
 Clears all GitHub API responses which have been cached in the repo-specific
---cache subdirectory.
+--cache subdirectory. Snapshots of the stargazers as of each fetch, which
+record star retention history, are kept.
 `,
 	Example: `  stargazers clear --repo=cockroachdb/cockroach`,
 	RunE:    RunClear,
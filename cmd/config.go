@@ -0,0 +1,111 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/kennygrant/sanitize"
+	"github.com/spencerkimball/stargazers/analyze"
+	"github.com/spencerkimball/stargazers/fetch"
+	"github.com/spencerkimball/stargazers/segment"
+)
+
+// Config holds settings read from the --config file.
+type Config struct {
+	// Segments are named stargazer segment expressions, which may be
+	// selected with --where=@name or referenced from other expressions.
+	Segments map[string]string `json:"segments"`
+	// Roles is the taxonomy of stargazer roles, mapping each role to
+	// the keywords in bios which indicate it, replacing the default.
+	Roles map[string][]string `json:"roles"`
+	// LeadScoring configures the weights, preferences and shortlists
+	// of the lead scoring analysis.
+	LeadScoring *analyze.LeadScoring `json:"lead_scoring"`
+}
+
+// loadConfig reads the --config file, if specified.
+func loadConfig() (*Config, error) {
+	config := &Config{}
+	if len(ConfigFile) == 0 {
+		return config, nil
+	}
+	f, err := os.Open(ConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %s", err)
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(config); err != nil {
+		return nil, fmt.Errorf("failed to decode config file %s: %s", ConfigFile, err)
+	}
+	return config, nil
+}
+
+// maxSegmentName is the maximum length of a segment name derived from
+// a --where expression, including its hash.
+const maxSegmentName = 64
+
+// segmentHashLen is the number of hex digits of the hash which
+// distinguishes segment names derived from --where expressions.
+const segmentHashLen = 8
+
+// selectSegment filters the stargazers by the --where expression, if
+// specified, returning the matching stargazers and the segment's
+// name, under which output is written. A named segment keeps its
+// name; other expressions are named by their sanitized text, which
+// drops operators, followed by a short hash of the normalized
+// expression, so that e.g. "followers > 100" and "followers < 100"
+// are named apart. The snapshots are restricted to the matching
+// stargazers, so that cohort retention describes the segment rather
+// than the whole audience; stargazers who have since removed their
+// star can't be matched, having no profile, and are dropped.
+func selectSegment(sg []*fetch.Stargazer, opts *analyze.Options) ([]*fetch.Stargazer, string, error) {
+	if len(Where) == 0 {
+		return sg, "", nil
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	expr, err := segment.Parse(Where, config.Segments)
+	if err != nil {
+		return nil, "", err
+	}
+	name := strings.TrimPrefix(Where, "@")
+	if _, ok := config.Segments[name]; !ok || !strings.HasPrefix(Where, "@") {
+		name = sanitize.BaseName(Where)
+		if len(name) > maxSegmentName-segmentHashLen-1 {
+			name = name[:maxSegmentName-segmentHashLen-1]
+		}
+		sum := sha256.Sum256([]byte(expr.Normalized()))
+		name += "-" + hex.EncodeToString(sum[:])[:segmentHashLen]
+	}
+	matched := segment.Filter(sg, expr, opts.CompanyOverrides)
+	logins := map[string]bool{}
+	for _, s := range matched {
+		logins[strings.ToLower(s.Login)] = true
+	}
+	fetch.RestrictSnapshots(opts.Snapshots, logins)
+	log.Printf("segment %q matched %d of %d stargazers", expr, len(matched), len(sg))
+	return matched, name, nil
+}
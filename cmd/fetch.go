@@ -34,12 +34,18 @@ stargazers for the specified :owner/:repo and then descending into
 each stargazer's followers, other starred repos, and subscribed
 repos. Each subscribed repo is further queried for that stargazer's
 contributions in terms of additions, deletions, and commits. All
-fetched data is cached by URL.
+fetched data is cached by URL. Users listed in the --exclude file are
+neither queried nor saved.
 `,
 	Example: `  stargazers fetch --repo=cockroachdb/cockroach --token=f87456b1112dadb2d831a5792bf2ca9a6afca7bc`,
 	RunE:    RunFetch,
 }
 
+func init() {
+	AddFetchFlags(FetchCmd)
+	FetchCmd.Flags().StringVar(&Exclude, "exclude", "", ExcludeDesc)
+}
+
 // RunFetch recursively queries all relevant github data for
 // the specified owner and repo.
 func RunFetch(cmd *cobra.Command, args []string) error {
@@ -50,11 +56,20 @@ func RunFetch(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	exclude, err := excludedLogins()
+	if err != nil {
+		return err
+	}
 	log.Printf("fetching GitHub data for repository %s", Repo)
 	fetchCtx := &fetch.Context{
-		Repo:     Repo,
-		Token:    token,
-		CacheDir: CacheDir,
+		Repo:      Repo,
+		Token:     token,
+		CacheDir:  CacheDir,
+		Following: Following,
+		Orgs:      Orgs,
+
+		StarredTimes: StarredTimes,
+		Exclude:      exclude,
 	}
 	if err := fetch.QueryAll(fetchCtx); err != nil {
 		log.Printf("failed to query stargazer data: %s", err)
@@ -18,7 +18,14 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"time"
 
+	"github.com/spencerkimball/stargazers/analyze"
+	"github.com/spencerkimball/stargazers/company"
+	"github.com/spencerkimball/stargazers/fetch"
+	"github.com/spencerkimball/stargazers/redact"
 	"github.com/spf13/cobra"
 )
 
@@ -50,12 +57,332 @@ var CacheDir string
 // CacheDirDesc describes usage.
 const CacheDirDesc = "directory for storing cached GitHub API responses"
 
+// Format specifies the output format for analysis results.
+var Format string
+
+// FormatDesc describes usage.
+const FormatDesc = "output format for analysis results: csv, json, ndjson or markdown"
+
+// CorrelationMetric specifies the ranking metric for correlated repos.
+var CorrelationMetric string
+
+// CorrelationMetricDesc describes usage.
+const CorrelationMetricDesc = "ranking metric for correlated repos: count, lift, jaccard, pmi or z"
+
+// MinSupport specifies the minimum co-occurrence count for a
+// correlated repo to be ranked.
+var MinSupport int
+
+// MinSupportDesc describes usage.
+const MinSupportDesc = "minimum count of stargazers for a correlated repo to be ranked"
+
+// Population specifies the estimated number of GitHub users.
+var Population float64
+
+// PopulationDesc describes usage.
+const PopulationDesc = "estimated number of GitHub users, used as the base population for lift and PMI"
+
+// StargazerJumpWeight specifies the weight of stargazers relative to
+// other users as PageRank random jump targets.
+var StargazerJumpWeight float64
+
+// StargazerJumpWeightDesc describes usage.
+const StargazerJumpWeightDesc = "probability of a PageRank random jump landing on a stargazer, relative to any other user; follower edges themselves are unweighted, but higher values favor users followed by and close to stargazers when computing influence"
+
+// StargazerSort specifies the sort key for the stargazer report.
+var StargazerSort string
+
+// StargazerSortDesc describes usage.
+const StargazerSortDesc = "sort key for the stargazer report: score, starred_at, activity, correlated_activity, influence or login"
+
+// analyzeOptions returns the analysis options specified by flags.
+func analyzeOptions() (analyze.Options, error) {
+	opts := analyze.Options{
+		Repo:              Repo,
+		CorrelationMetric: CorrelationMetric,
+		MinSupport:        MinSupport,
+		Population:        Population,
+		StargazerSort:     StargazerSort,
+
+		StargazerJumpWeight: StargazerJumpWeight,
+		SuspicionThreshold:  SuspicionThreshold,
+	}
+	if err := analyze.ValidateCorrelationMetric(CorrelationMetric); err != nil {
+		return analyze.Options{}, err
+	}
+	if err := analyze.ValidateStargazerSort(StargazerSort); err != nil {
+		return analyze.Options{}, err
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return analyze.Options{}, err
+	}
+	opts.Roles = config.Roles
+	opts.LeadScoring = config.LeadScoring
+	if len(CompanyOverrides) > 0 {
+		overrides, err := company.LoadOverrides(CompanyOverrides)
+		if err != nil {
+			return analyze.Options{}, err
+		}
+		opts.CompanyOverrides = overrides
+	}
+	if Granularity == analyze.GranularityLegacy && len(Timezone) > 0 {
+		return analyze.Options{}, errors.New("--timezone requires a --granularity other than legacy")
+	}
+	if opts.Resampler, err = analyze.NewResampler(Granularity, Timezone); err != nil {
+		return analyze.Options{}, err
+	}
+	return opts, nil
+}
+
+// applyAsOf sets the reference time of the analysis options from
+// --as-of or, by default, the time at which the saved state was
+// fetched. A date without a time is the last second of that UTC day,
+// so that the day's stars are included. Stargazers who starred, and
+// snapshots fetched, after the reference time are excluded.
+func applyAsOf(c *fetch.Context, sg []*fetch.Stargazer, opts *analyze.Options) ([]*fetch.Stargazer, error) {
+	if len(AsOf) > 0 {
+		t, err := time.Parse("2006-01-02", AsOf)
+		if err == nil {
+			t = t.AddDate(0, 0, 1).Add(-time.Second)
+		} else if t, err = time.Parse(time.RFC3339, AsOf); err != nil {
+			return nil, fmt.Errorf("invalid --as-of %q; use YYYY-MM-DD or RFC 3339", AsOf)
+		}
+		opts.AsOf = t.UTC()
+	} else {
+		t, err := fetch.StateTime(c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine fetch time of saved state: %s", err)
+		}
+		opts.AsOf = t
+	}
+	log.Printf("analyzing as of %s", opts.AsOf.Format(time.RFC3339))
+
+	var snaps []*fetch.Snapshot
+	for _, snap := range opts.Snapshots {
+		if !snap.FetchedAt.After(opts.AsOf) {
+			snaps = append(snaps, snap)
+		}
+	}
+	opts.Snapshots = snaps
+	var filtered []*fetch.Stargazer
+	for _, s := range sg {
+		if t, err := time.Parse(time.RFC3339, s.StarredAt); err != nil || !t.After(opts.AsOf) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered, nil
+}
+
+// excludedLogins returns the logins listed in the --exclude file, if
+// specified.
+func excludedLogins() (map[string]bool, error) {
+	if len(Exclude) == 0 {
+		return nil, nil
+	}
+	logins, err := fetch.LoadLogins(Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load excluded logins: %s", err)
+	}
+	return logins, nil
+}
+
+// applyExclude removes the users listed in the --exclude file from
+// saved stargazer data and snapshots.
+func applyExclude(sg []*fetch.Stargazer, rs map[string]*fetch.Repo, opts *analyze.Options) ([]*fetch.Stargazer, error) {
+	logins, err := excludedLogins()
+	if err != nil || len(logins) == 0 {
+		return sg, err
+	}
+	kept := fetch.Scrub(sg, rs, logins)
+	fetch.ScrubSnapshots(opts.Snapshots, logins)
+	log.Printf("excluded %d opted out stargazers", len(sg)-len(kept))
+	return kept, nil
+}
+
+// applyRedact redacts personally identifying information from the
+// stargazer data and snapshots if --redact is specified. It's applied
+// after the --where segment is selected, so that segments may still
+// refer to the redacted fields.
+func applyRedact(sg []*fetch.Stargazer, rs map[string]*fetch.Repo, opts *analyze.Options) error {
+	if !Redact {
+		return nil
+	}
+	r, err := redact.New(redact.Options{
+		Salt:             RedactSalt,
+		MinGroupSize:     MinGroupSize,
+		CompanyOverrides: opts.CompanyOverrides,
+	})
+	if err != nil {
+		return err
+	}
+	r.Redact(sg, rs, opts.Snapshots)
+	opts.Redact = true
+	opts.MinGroupSize = MinGroupSize
+	log.Printf("redacted personal information of %d stargazers", len(sg))
+	return nil
+}
+
+// loadAnalysis loads the saved stargazer data and snapshots of the
+// repo and prepares them for analysis as specified by flags: opted
+// out stargazers are excluded, stars after --as-of dropped, the
+// --where segment selected and, with --redact, personal information
+// redacted. Returns the stargazers, repos, analysis options and the
+// segment's name.
+func loadAnalysis(c *fetch.Context) ([]*fetch.Stargazer, map[string]*fetch.Repo, analyze.Options, string, error) {
+	log.Printf("fetching saved GitHub stargazer data for repository %s", c.Repo)
+	sg, rs, err := fetch.LoadState(c)
+	if err != nil {
+		return nil, nil, analyze.Options{}, "", fmt.Errorf("failed to load saved stargazer data: %s", err)
+	}
+	opts, err := analyzeOptions()
+	if err != nil {
+		return nil, nil, opts, "", err
+	}
+	if opts.Snapshots, err = fetch.LoadSnapshots(c); err != nil {
+		return nil, nil, opts, "", fmt.Errorf("failed to load stargazer snapshots: %s", err)
+	}
+	if sg, err = applyExclude(sg, rs, &opts); err != nil {
+		return nil, nil, opts, "", err
+	}
+	if sg, err = applyAsOf(c, sg, &opts); err != nil {
+		return nil, nil, opts, "", err
+	}
+	sg, segmentName, err := selectSegment(sg, &opts)
+	if err != nil {
+		return nil, nil, opts, "", err
+	}
+	if err := applyRedact(sg, rs, &opts); err != nil {
+		return nil, nil, opts, "", err
+	}
+	return sg, rs, opts, segmentName, nil
+}
+
+// Exclude specifies the path of a file of opted out logins.
+var Exclude string
+
+// ExcludeDesc describes usage.
+const ExcludeDesc = "file of GitHub logins, one per line, who have opted out; they're excluded from fetches and all analyses"
+
+// Redact specifies that personally identifying information is
+// redacted from analysis results.
+var Redact bool
+
+// RedactDesc describes usage.
+const RedactDesc = "redact personal information from results for sharing: hash logins, drop names, emails, bios and URLs, generalize locations to countries and companies to employers"
+
+// RedactSalt specifies the salt of hashed logins.
+var RedactSalt string
+
+// RedactSaltDesc describes usage.
+const RedactSaltDesc = "secret salt of logins hashed by --redact; the same salt gives the same hashes"
+
+// MinGroupSize specifies the minimum number of stargazers sharing a
+// value for it to be kept when redacting.
+var MinGroupSize int
+
+// MinGroupSizeDesc describes usage.
+const MinGroupSizeDesc = "with --redact, the minimum number of stargazers who must share a country, employer, organization or repo for it to be kept, and who a result row must describe for it to be output"
+
+// AsOf specifies the reference time of analyses.
+var AsOf string
+
+// AsOfDesc describes usage.
+const AsOfDesc = "reference time of analyses, as RFC 3339 or YYYY-MM-DD for the end of that UTC day, excluding later stars (default the fetch time of the saved state)"
+
+// SuspicionThreshold specifies the star quality score at or above
+// which a star is considered suspicious.
+var SuspicionThreshold float64
+
+// SuspicionThresholdDesc describes usage.
+const SuspicionThresholdDesc = "star quality score (0-1) at or above which a star is considered suspicious"
+
+// CompanyOverrides specifies the path of a JSON file of company
+// normalization rules.
+var CompanyOverrides string
+
+// CompanyOverridesDesc describes usage.
+const CompanyOverridesDesc = "JSON file of company normalization overrides, with \"aliases\", \"domains\", \"orgs\" and \"logins\" maps to employer names"
+
+// Granularity specifies the period of time series and of tables of
+// counts by period.
+var Granularity string
+
+// GranularityDesc describes usage.
+const GranularityDesc = "period of time series: legacy, daily, weekly, monthly or quarterly; legacy keeps the original daily and weekly series and monthly tables"
+
+// Timezone specifies the time zone of time series periods.
+var Timezone string
+
+// TimezoneDesc describes usage.
+const TimezoneDesc = "time zone of time series periods and of the days and hours of star bursts, forecasts and the star heatmap, as an IANA name such as America/New_York (default UTC)"
+
+// ConfigFile specifies the path of a JSON configuration file.
+var ConfigFile string
+
+// ConfigFileDesc describes usage.
+const ConfigFileDesc = "JSON configuration file, with named stargazer segments in a \"segments\" map of names to --where expressions, a taxonomy of stargazer roles in a \"roles\" map of names to bio keywords, and \"lead_scoring\" weights, preferences and shortlists"
+
+// Where specifies an expression selecting the segment of stargazers
+// to analyze.
+var Where string
+
+// WhereDesc describes usage.
+const WhereDesc = "analyze only the stargazers matching an expression, e.g. 'followers > 100 && company ~ \"google\"', or a named segment as @name"
+
+// Orgs specifies whether to fetch each stargazer's organizations.
+var Orgs bool
+
+// OrgsDesc describes usage.
+const OrgsDesc = "also fetch each stargazer's public organization memberships, for use in employer analysis"
+
+// StarredTimes specifies whether to fetch the time at which each
+// stargazer starred each of their starred repos.
+var StarredTimes bool
+
+// StarredTimesDesc describes usage.
+const StarredTimesDesc = "also fetch when each stargazer starred each of their starred repos, for use in timezone inference"
+
+// Following specifies whether to fetch the users each stargazer follows.
+var Following bool
+
+// FollowingDesc describes usage.
+const FollowingDesc = "also fetch the users each stargazer follows, for use in influence analysis"
+
 // Repo specifies the the owner and repository in :owner/:repo format.
 var Repo string
 
 // RepoDesc describes usage.
 const RepoDesc = "GitHub owner and repository, formatted as :owner/:repo"
 
+// AddFetchFlags registers the flags read when fetching on the command.
+func AddFetchFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&Following, "following", false, FollowingDesc)
+	cmd.Flags().BoolVar(&Orgs, "orgs", false, OrgsDesc)
+	cmd.Flags().BoolVar(&StarredTimes, "starred-times", false, StarredTimesDesc)
+}
+
+// AddAnalysisFlags registers the flags read when running analyses on
+// the command.
+func AddAnalysisFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&AsOf, "as-of", "", AsOfDesc)
+	cmd.Flags().StringVar(&CompanyOverrides, "company-overrides", "", CompanyOverridesDesc)
+	cmd.Flags().StringVar(&ConfigFile, "config", "", ConfigFileDesc)
+	cmd.Flags().StringVar(&CorrelationMetric, "correlation-metric", "count", CorrelationMetricDesc)
+	cmd.Flags().StringVar(&Exclude, "exclude", "", ExcludeDesc)
+	cmd.Flags().StringVar(&Granularity, "granularity", analyze.GranularityLegacy, GranularityDesc)
+	cmd.Flags().IntVar(&MinGroupSize, "min-group-size", redact.DefaultMinGroupSize, MinGroupSizeDesc)
+	cmd.Flags().IntVar(&MinSupport, "min-support", 1, MinSupportDesc)
+	cmd.Flags().Float64Var(&Population, "population", analyze.DefaultPopulation, PopulationDesc)
+	cmd.Flags().BoolVar(&Redact, "redact", false, RedactDesc)
+	cmd.Flags().StringVar(&RedactSalt, "redact-salt", "", RedactSaltDesc)
+	cmd.Flags().Float64Var(&StargazerJumpWeight, "stargazer-jump-weight", 1, StargazerJumpWeightDesc)
+	cmd.Flags().StringVar(&StargazerSort, "stargazer-sort", "score", StargazerSortDesc)
+	cmd.Flags().Float64Var(&SuspicionThreshold, "suspicion-threshold", analyze.DefaultSuspicionThreshold, SuspicionThresholdDesc)
+	cmd.Flags().StringVar(&Timezone, "timezone", "", TimezoneDesc)
+	cmd.Flags().StringVar(&Where, "where", "", WhereDesc)
+}
+
 func mustUsage(cmd *cobra.Command) {
 	if err := cmd.Usage(); err != nil {
 		panic(err)
@@ -0,0 +1,103 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package cmd
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/spencerkimball/stargazers/fetch"
+	"github.com/spf13/cobra"
+)
+
+// ForgetCmd purges a user from cached and saved stargazer data.
+var ForgetCmd = &cobra.Command{
+	Use:   "forget :login... [--repo=:owner/:repo]",
+	Short: "purge a user from cached and saved stargazer data",
+	Long: `
+Purges a GitHub user from the repo-specific --cache subdirectory, or
+from those of all repos if --repo isn't specified. The cached responses
+for the user's profile and their followers, following, organizations,
+starred and subscribed lists are removed, and the user is scrubbed from
+the saved state and snapshots, including from other stargazers' follower
+lists and repos' contributor statistics. A tombstone is recorded in the
+--cache directory, which outlives clear, so that future fetches of any
+repo skip the user.
+
+Analysis results already written aren't modified; rerun analyze, chart
+and report to regenerate them.
+`,
+	Example: `  stargazers forget octocat --repo=cockroachdb/cockroach`,
+	RunE:    RunForget,
+}
+
+// RunForget purges the specified users from the cache directories of
+// the specified repo or, by default, of all repos.
+func RunForget(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("login not specified; use forget :login")
+	}
+	repos := []string{Repo}
+	if len(Repo) == 0 {
+		var err error
+		if repos, err = cachedRepos(); err != nil {
+			return err
+		}
+	}
+	// Record tombstones first, even if no repos have been cached yet.
+	for _, login := range args {
+		if err := fetch.AddForgotten(&fetch.Context{CacheDir: CacheDir}, login); err != nil {
+			return err
+		}
+	}
+	for _, repo := range repos {
+		fetchCtx := &fetch.Context{
+			Repo:     repo,
+			CacheDir: CacheDir,
+		}
+		for _, login := range args {
+			log.Printf("forgetting GitHub user %s from repository %s", login, repo)
+			if err := fetch.Forget(fetchCtx, login); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cachedRepos returns the repos, as :owner/:repo, which have cache
+// subdirectories.
+func cachedRepos() ([]string, error) {
+	dirs, err := filepath.Glob(filepath.Join(CacheDir, "*", "*"))
+	if err != nil {
+		return nil, err
+	}
+	var repos []string
+	for _, dir := range dirs {
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			continue
+		}
+		rel, err := filepath.Rel(CacheDir, dir)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, filepath.ToSlash(rel))
+	}
+	return repos, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package cmd
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/spencerkimball/stargazers/analyze"
+	"github.com/spencerkimball/stargazers/fetch"
+	"github.com/spencerkimball/stargazers/report"
+	"github.com/spf13/cobra"
+)
+
+// ReportCmd generates a report from previously fetched GitHub
+// stargazer data.
+var ReportCmd = &cobra.Command{
+	Use:   "report --repo=:owner/:repo --html",
+	Short: "generate a shareable report from previously fetched stargazer data",
+	Long: `
+Generates a report of the analyses run by the analyze command. With
+--html, the report is a single self-contained HTML file with charts
+rendered as inline SVG, suitable for viewing offline. With --where, the
+report covers only the matching segment of stargazers, and with --redact
+it's stripped of personal information for sharing; see analyze.
+`,
+	Example: `  stargazers report --repo=cockroachdb/cockroach --html`,
+	RunE:    RunReport,
+}
+
+// HTML specifies that the report should be written as HTML.
+var HTML bool
+
+// HTMLDesc describes usage.
+const HTMLDesc = "write a self-contained HTML report"
+
+func init() {
+	ReportCmd.Flags().BoolVar(&HTML, "html", false, HTMLDesc)
+	AddAnalysisFlags(ReportCmd)
+}
+
+// RunReport loads saved stargazer info for the specified repo, runs
+// all analyses and writes the report.
+func RunReport(cmd *cobra.Command, args []string) error {
+	if len(Repo) == 0 {
+		return errors.New("repository not specified; use --repo=:owner/:repo")
+	}
+	if !HTML {
+		return errors.New("report format not specified; use --html")
+	}
+	fetchCtx := &fetch.Context{
+		Repo:     Repo,
+		CacheDir: CacheDir,
+	}
+	sg, rs, opts, segmentName, err := loadAnalysis(fetchCtx)
+	if err != nil {
+		return err
+	}
+	res, err := analyze.ComputeAll(sg, rs, opts)
+	if err != nil {
+		log.Printf("failed to analyze stargazer data: %s", err)
+		return nil
+	}
+	dir, err := analyze.MakeOutputDir(fetchCtx, segmentName)
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(dir, "report.html")
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := report.WriteHTML(f, Repo, res); err != nil {
+		return err
+	}
+	log.Printf("wrote HTML report to %s", filename)
+	return nil
+}
@@ -0,0 +1,452 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+// Package company normalizes the free-text company field of GitHub
+// profiles to canonical employer names, falling back to email, blog
+// and organization membership when the company field is empty.
+package company
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+)
+
+// Sources of an employer, in decreasing order of precedence.
+const (
+	SourceOverride = "override" // Login listed in the override file
+	SourceCompany  = "company"  // Profile company field
+	SourceEmail    = "email"    // Domain of the profile email
+	SourceOrg      = "org"      // Organization membership
+	SourceBlog     = "blog"     // Domain of the profile blog URL
+)
+
+// Overrides are user-supplied rules which take precedence over the
+// built-in rules. Keys of Aliases are matched against company names
+// after cleaning (lower case, without a leading "@" or a trailing
+// legal suffix such as "Inc."); keys of Domains are registrable
+// domains such as "example.co.uk". All values are canonical
+// employer names.
+type Overrides struct {
+	Aliases map[string]string `json:"aliases"`
+	Domains map[string]string `json:"domains"`
+	Orgs    map[string]string `json:"orgs"`
+	Logins  map[string]string `json:"logins"`
+}
+
+// LoadOverrides reads overrides from the JSON file at path.
+func LoadOverrides(path string) (*Overrides, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read company overrides: %s", err)
+	}
+	o := &Overrides{}
+	if err := json.Unmarshal(data, o); err != nil {
+		return nil, fmt.Errorf("failed to parse company overrides %s: %s", path, err)
+	}
+	return o, nil
+}
+
+// A Profile holds the fields of a GitHub user which identify their
+// employer.
+type Profile struct {
+	Login   string
+	Company string
+	Email   string
+	Blog    string
+	Orgs    []string // Organization logins
+}
+
+// An Employer is the normalized employer of a profile. Key is empty
+// if no employer could be determined.
+type Employer struct {
+	Key    string `json:"key"`  // Lower case canonical key
+	Name   string `json:"name"` // Display name
+	Source string `json:"source,omitempty"`
+	// Former is set when the company field names a previous employer,
+	// as in "ex-Google"; Key and Name then refer to that employer.
+	Former bool `json:"former,omitempty"`
+}
+
+// legalSuffixes are stripped from the end of company names.
+var legalSuffixes = []string{
+	"inc", "incorporated", "llc", "ltd", "limited", "corp", "corporation", "co", "company",
+	"gmbh", "ag", "sa", "s.a", "bv", "b.v", "nv", "plc", "pty", "srl", "s.r.l", "oy", "ab",
+	"as", "kk", "sas", "spa", "s.p.a", "sarl", "llp", "lp", "pvt", "private",
+}
+
+// aliases maps cleaned company names to canonical keys.
+var aliases = map[string]string{
+	"alphabet":                        "google",
+	"google cloud":                    "google",
+	"google cloud platform":           "google",
+	"deepmind":                        "google",
+	"youtube":                         "google",
+	"msft":                            "microsoft",
+	"microsoft research":              "microsoft",
+	"github":                          "github",
+	"aws":                             "amazon",
+	"amazon web services":             "amazon",
+	"amazon.com":                      "amazon",
+	"fb":                              "meta",
+	"facebook":                        "meta",
+	"meta platforms":                  "meta",
+	"instagram":                       "meta",
+	"apple computer":                  "apple",
+	"ibm research":                    "ibm",
+	"international business machines": "ibm",
+	"redhat":                          "red hat",
+	"red hat software":                "red hat",
+	"hashicorp":                       "hashicorp",
+	"hashi corp":                      "hashicorp",
+	"cockroach labs":                  "cockroach labs",
+	"cockroachdb":                     "cockroach labs",
+	"uber technologies":               "uber",
+	"airbnb":                          "airbnb",
+	"mozilla foundation":              "mozilla",
+	"mozilla corporation":             "mozilla",
+	"alibaba group":                   "alibaba",
+	"alibaba cloud":                   "alibaba",
+	"aliyun":                          "alibaba",
+	"tencent holdings":                "tencent",
+	"bytedance":                       "bytedance",
+	"byte dance":                      "bytedance",
+	"self employed":                   independent,
+	"self-employed":                   independent,
+	"self":                            independent,
+	"freelance":                       independent,
+	"freelancer":                      independent,
+	"independent":                     independent,
+	"independent consultant":          independent,
+	"consultant":                      independent,
+}
+
+// independent is the key of self-employed users.
+const independent = "independent"
+
+// names maps canonical keys to display names, where they differ from
+// the most common spelling in the data.
+var names = map[string]string{
+	"google":         "Google",
+	"microsoft":      "Microsoft",
+	"github":         "GitHub",
+	"amazon":         "Amazon",
+	"meta":           "Meta",
+	"apple":          "Apple",
+	"ibm":            "IBM",
+	"red hat":        "Red Hat",
+	"hashicorp":      "HashiCorp",
+	"cockroach labs": "Cockroach Labs",
+	"uber":           "Uber",
+	"airbnb":         "Airbnb",
+	"mozilla":        "Mozilla",
+	"alibaba":        "Alibaba",
+	"tencent":        "Tencent",
+	"bytedance":      "ByteDance",
+	independent:      "Independent",
+}
+
+// ignored are company field values which name no employer.
+var ignored = map[string]bool{
+	"none": true, "n/a": true, "na": true, "-": true, "no": true, "nothing": true,
+	"student": true, "home": true, "personal": true, "unemployed": true, "private": true,
+	"earth": true, "world": true, "internet": true, "github user": true,
+}
+
+// freeMail are email domains which don't identify an employer.
+var freeMail = map[string]bool{
+	"gmail.com": true, "googlemail.com": true, "yahoo.com": true, "hotmail.com": true,
+	"outlook.com": true, "live.com": true, "msn.com": true, "icloud.com": true, "me.com": true,
+	"mac.com": true, "aol.com": true, "protonmail.com": true, "proton.me": true, "gmx.com": true,
+	"gmx.de": true, "gmx.net": true, "web.de": true, "mail.ru": true, "yandex.ru": true,
+	"yandex.com": true, "qq.com": true, "163.com": true, "126.com": true, "foxmail.com": true,
+	"naver.com": true, "fastmail.com": true, "fastmail.fm": true, "zoho.com": true,
+	"hey.com": true, "tutanota.com": true, "users.noreply.github.com": true,
+}
+
+// hosts are blog domains which host personal pages and so don't
+// identify an employer.
+var hosts = map[string]bool{
+	"github.io": true, "github.com": true, "gitlab.io": true, "blogspot.com": true,
+	"wordpress.com": true, "medium.com": true, "twitter.com": true, "x.com": true,
+	"linkedin.com": true, "tumblr.com": true, "about.me": true, "netlify.app": true,
+	"netlify.com": true, "herokuapp.com": true, "vercel.app": true, "substack.com": true,
+	"dev.to": true, "facebook.com": true, "google.com": true, "youtube.com": true,
+	"stackoverflow.com": true, "keybase.io": true, "gravatar.com": true,
+}
+
+// A Normalizer maps profiles to employers.
+type Normalizer struct {
+	overrides Overrides
+}
+
+// NewNormalizer returns a normalizer applying the overrides, if not
+// nil, in preference to the built-in rules.
+func NewNormalizer(overrides *Overrides) *Normalizer {
+	n := &Normalizer{}
+	if overrides != nil {
+		n.overrides = *overrides
+	}
+	return n
+}
+
+// Normalize returns the employer of each profile. Profiles are
+// matched in order of precedence against the login overrides, the
+// company field, the email domain, organization memberships and the
+// blog domain. Since personal domains and open source organizations
+// are common, organizations and blog domains are only used if they
+// name an employer found in the company field of another profile or
+// in the overrides; unknown email domains are used if shared by more
+// than one profile.
+func (n *Normalizer) Normalize(profiles []Profile) []Employer {
+	employers := make([]Employer, len(profiles))
+	known := map[string]string{} // Key to display name
+	for _, v := range n.overrides.Aliases {
+		known[Key(v)] = v
+	}
+	for _, m := range []map[string]string{n.overrides.Domains, n.overrides.Orgs, n.overrides.Logins} {
+		for _, v := range m {
+			known[Key(v)] = v
+		}
+	}
+
+	emailDomains := map[string]int{}
+	for i, p := range profiles {
+		if v, ok := n.overrides.Logins[p.Login]; ok {
+			employers[i] = Employer{Key: Key(v), Name: v, Source: SourceOverride}
+			continue
+		}
+		if e := n.fromCompany(p.Company); len(e.Key) > 0 {
+			employers[i] = e
+			if _, ok := known[e.Key]; !ok {
+				known[e.Key] = e.Name
+			}
+			continue
+		}
+		if d := emailDomain(p.Email); len(d) > 0 {
+			emailDomains[d]++
+		}
+	}
+
+	for i, p := range profiles {
+		if len(employers[i].Key) > 0 {
+			continue
+		}
+		if d := emailDomain(p.Email); len(d) > 0 {
+			if e, ok := n.fromDomain(d, known); ok || emailDomains[d] > 1 {
+				e.Source = SourceEmail
+				employers[i] = e
+				continue
+			}
+		}
+		for _, org := range p.Orgs {
+			org = strings.ToLower(org)
+			if v, ok := n.overrides.Orgs[org]; ok {
+				employers[i] = Employer{Key: Key(v), Name: v, Source: SourceOrg}
+				break
+			}
+			if name, ok := known[canonical(org)]; ok {
+				employers[i] = Employer{Key: canonical(org), Name: name, Source: SourceOrg}
+				break
+			}
+		}
+		if len(employers[i].Key) > 0 {
+			continue
+		}
+		if d := blogDomain(p.Blog); len(d) > 0 {
+			if e, ok := n.fromDomain(d, known); ok {
+				e.Source = SourceBlog
+				employers[i] = e
+			}
+		}
+	}
+	return employers
+}
+
+// fromCompany normalizes a company field.
+func (n *Normalizer) fromCompany(company string) Employer {
+	name, former := clean(company)
+	if len(name) == 0 || ignored[strings.ToLower(name)] {
+		return Employer{}
+	}
+	lower := strings.ToLower(name)
+	if v, ok := n.overrides.Aliases[lower]; ok {
+		return Employer{Key: Key(v), Name: v, Source: SourceCompany, Former: former}
+	}
+	key := canonical(lower)
+	if v, ok := names[key]; ok {
+		name = v
+	}
+	return Employer{Key: key, Name: name, Source: SourceCompany, Former: former}
+}
+
+// fromDomain returns the employer named by a registrable domain, and
+// whether the employer is known from the overrides or other profiles.
+func (n *Normalizer) fromDomain(domain string, known map[string]string) (Employer, bool) {
+	if v, ok := n.overrides.Domains[domain]; ok {
+		return Employer{Key: Key(v), Name: v}, true
+	}
+	key := canonical(strings.SplitN(domain, ".", 2)[0])
+	if name, ok := known[key]; ok {
+		return Employer{Key: key, Name: name}, true
+	}
+	name := key
+	if v, ok := names[key]; ok {
+		name = v
+	}
+	return Employer{Key: key, Name: name}, false
+}
+
+// Key returns the canonical key of a company name.
+func Key(name string) string {
+	cleaned, _ := clean(name)
+	return canonical(strings.ToLower(cleaned))
+}
+
+// canonical maps a cleaned, lower case company name to its key.
+func canonical(lower string) string {
+	if v, ok := aliases[lower]; ok {
+		return v
+	}
+	return lower
+}
+
+// clean strips the decoration from a company field. A field naming no
+// employer, such as "N/A", is ignored as a whole. Otherwise the field
+// is split into its companies, and the first which isn't marked by a
+// "formerly" or "ex-" prefix is returned, so that "Formerly @google,
+// now @stripe" gives "stripe". If every company is a previous
+// employer, the first is returned and reported as former.
+func clean(company string) (string, bool) {
+	s := strings.TrimSpace(company)
+	if ignored[strings.ToLower(s)] {
+		return "", false
+	}
+	var first string
+	parts := strings.FieldsFunc(s, func(r rune) bool { return strings.ContainsRune(",;/|&\n", r) })
+	for _, part := range parts {
+		name, former := cleanPart(part)
+		if len(name) == 0 || ignored[strings.ToLower(name)] {
+			continue
+		}
+		if !former {
+			return name, false
+		}
+		if len(first) == 0 {
+			first = name
+		}
+	}
+	return first, len(first) > 0
+}
+
+// cleanPart strips the decoration from a single company: a leading
+// "@", a "formerly" or "ex-" prefix (reported as former) or a "now"
+// prefix, a trailing web domain and trailing legal suffixes.
+func cleanPart(part string) (string, bool) {
+	s := strings.TrimSpace(strings.Replace(part, " and ", " ", -1))
+	former := false
+	for _, prefix := range []string{"ex-", "ex ", "formerly ", "former ", "previously "} {
+		if len(s) > len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+			s, former = strings.TrimSpace(s[len(prefix):]), true
+		}
+	}
+	for _, prefix := range []string{"now ", "currently "} {
+		if len(s) > len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+			s = strings.TrimSpace(s[len(prefix):])
+		}
+	}
+	s = strings.TrimLeft(s, "@ ")
+	for _, tld := range []string{".com", ".io", ".net", ".org", ".co", ".ai", ".dev"} {
+		if strings.HasSuffix(strings.ToLower(s), tld) && !strings.Contains(s, " ") {
+			s = s[:len(s)-len(tld)]
+		}
+	}
+	for {
+		words := strings.Fields(strings.TrimRight(s, ".,"))
+		if len(words) < 2 {
+			break
+		}
+		last := strings.ToLower(strings.TrimRight(words[len(words)-1], ".,()"))
+		if !isLegalSuffix(last) {
+			break
+		}
+		s = strings.Join(words[:len(words)-1], " ")
+	}
+	return strings.TrimRight(strings.TrimSpace(s), ".,"), former
+}
+
+func isLegalSuffix(word string) bool {
+	for _, suffix := range legalSuffixes {
+		if word == suffix {
+			return true
+		}
+	}
+	return false
+}
+
+// emailDomain returns the registrable domain of an email address, or
+// the empty string if it is invalid or a free mail provider.
+func emailDomain(email string) string {
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return ""
+	}
+	d := registrable(email[i+1:])
+	if freeMail[d] || freeMail[strings.ToLower(email[i+1:])] {
+		return ""
+	}
+	return d
+}
+
+// blogDomain returns the registrable domain of a blog URL, or the
+// empty string if it is invalid or a personal page host.
+func blogDomain(blog string) string {
+	if len(blog) == 0 {
+		return ""
+	}
+	if !strings.Contains(blog, "://") {
+		blog = "http://" + blog
+	}
+	u, err := url.Parse(blog)
+	if err != nil {
+		return ""
+	}
+	d := registrable(u.Hostname())
+	if hosts[d] {
+		return ""
+	}
+	return d
+}
+
+// registrable returns the registrable part of a host name: the last
+// two labels, or three for country domains with a generic second
+// level such as "co.uk".
+func registrable(host string) string {
+	labels := strings.Split(strings.Trim(strings.ToLower(host), "."), ".")
+	if len(labels) < 2 {
+		return ""
+	}
+	n := 2
+	if len(labels) > 2 && len(labels[len(labels)-1]) == 2 {
+		switch labels[len(labels)-2] {
+		case "co", "com", "ac", "org", "net", "gov", "edu", "ne", "or":
+			n = 3
+		}
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
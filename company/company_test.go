@@ -0,0 +1,87 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package company
+
+import "testing"
+
+func TestClean(t *testing.T) {
+	testCases := []struct {
+		company string
+		name    string
+		former  bool
+	}{
+		{"", "", false},
+		{"N/A", "", false},
+		{" none ", "", false},
+		{"@google", "google", false},
+		{"Acme, Inc.", "Acme", false},
+		{"Acme Corp", "Acme", false},
+		{"cockroachlabs.com", "cockroachlabs", false},
+		{"ex-Google", "Google", true},
+		{"Formerly @google, now @stripe", "stripe", false},
+		{"ex-Google / ex-Facebook", "Google", true},
+		{"Google / Stanford", "Google", false},
+	}
+	for i, c := range testCases {
+		name, former := clean(c.company)
+		if name != c.name || former != c.former {
+			t.Errorf("%d: clean(%q) = %q, %t; want %q, %t", i, c.company, name, former, c.name, c.former)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	overrides := &Overrides{
+		Logins: map[string]string{"boss": "Initech"},
+	}
+	profiles := []Profile{
+		{Login: "a", Company: "Google Cloud"},
+		{Login: "b", Company: "N/A", Email: "b@gmail.com"},
+		{Login: "c", Email: "c@acme.com"},
+		{Login: "d", Email: "d@mail.acme.com"},
+		{Login: "e", Email: "e@solo.dev"},
+		{Login: "f", Orgs: []string{"Google"}},
+		{Login: "g", Orgs: []string{"kubernetes"}},
+		{Login: "h", Blog: "https://blog.google.com"},
+		{Login: "i", Blog: "example.github.io"},
+		{Login: "boss", Company: "Acme"},
+	}
+	expected := []struct {
+		key, source string
+	}{
+		{"google", SourceCompany},
+		{"", ""},
+		{"acme", SourceEmail},
+		{"acme", SourceEmail},
+		{"", ""},
+		{"google", SourceOrg},
+		{"", ""},
+		{"", ""},
+		{"", ""},
+		{"initech", SourceOverride},
+	}
+	employers := NewNormalizer(overrides).Normalize(profiles)
+	for i, e := range expected {
+		if employers[i].Key != e.key || employers[i].Source != e.source {
+			t.Errorf("%s: expected %q from %q; got %q from %q",
+				profiles[i].Login, e.key, e.source, employers[i].Key, employers[i].Source)
+		}
+	}
+	if name := employers[0].Name; name != "Google" {
+		t.Errorf("expected display name Google; got %q", name)
+	}
+}
@@ -88,8 +88,12 @@ func putCache(c *Context, req *http.Request, resp *http.Response) error {
 
 // cacheEntryFilename creates a filename-safe name in a subdirectory
 // of the configured cache dir, with any access token stripped out.
+// The context's cache suffix, if any, is appended.
 func cacheEntryFilename(c *Context, url string) string {
 	newUrl := strings.Replace(url, fmt.Sprintf("access_token=%s", c.Token), "", 1)
+	if len(c.cacheSuffix) > 0 {
+		newUrl += "-" + c.cacheSuffix
+	}
 	return filepath.Join(c.CacheDir, c.Repo, sanitize.BaseName(newUrl))
 }
 
@@ -99,9 +103,58 @@ func clearEntry(c *Context, url string) error {
 	return os.Remove(filename)
 }
 
+// clearPages clears the cache entries of a paged URL, following the
+// next links of the cached responses. Returns the number of entries
+// cleared.
+func clearPages(c *Context, url string) (int, error) {
+	cleared := 0
+	for len(url) > 0 {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return cleared, err
+		}
+		filename := cacheEntryFilename(c, url)
+		url = ""
+		resp, err := readCachedResponse(filename, req)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return cleared, nil
+			}
+			// A corrupted entry can't be followed to the next page.
+			log.Printf("failed reading cached response: %s", err)
+		} else {
+			resp.Body.Close()
+			if urls := linkRE.FindStringSubmatch(resp.Header.Get("Link")); urls != nil {
+				url = urls[1]
+			}
+		}
+		if err := os.Remove(filename); err != nil {
+			return cleared, err
+		}
+		cleared++
+	}
+	return cleared, nil
+}
+
 // Clear clears all cache entries for the repository specified in the
-// fetch context.
+// fetch context. Snapshots aren't cached responses and can't be
+// refetched, so they're kept.
 func Clear(c *Context) error {
-	filename := filepath.Join(c.CacheDir, c.Repo)
-	return os.RemoveAll(filename)
+	dir := filepath.Join(c.CacheDir, c.Repo)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, fi := range files {
+		if fi.Name() == snapshotDir {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, fi.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
 }
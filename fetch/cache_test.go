@@ -0,0 +1,53 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package fetch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClearKeepsSnapshots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clear")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := &Context{Repo: "owner/repo", CacheDir: dir}
+	if err := os.MkdirAll(filepath.Join(dir, c.Repo), 0755); err != nil {
+		t.Fatal(err)
+	}
+	putCachedResponse(t, c, "https://api.github.com/users/Alice", "")
+	if err := SaveSnapshot(c, []*Stargazer{{User: User{Login: "Alice"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Clear(c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cacheEntryFilename(c, "https://api.github.com/users/Alice")); !os.IsNotExist(err) {
+		t.Errorf("expected cache entry to be cleared; got %v", err)
+	}
+	snaps, err := LoadSnapshots(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 1 {
+		t.Errorf("expected snapshot to be kept; got %d", len(snaps))
+	}
+}
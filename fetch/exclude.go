@@ -0,0 +1,109 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package fetch
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// LoadLogins reads a file of GitHub logins, one per line. Blank lines
+// and lines starting with '#' are ignored. Logins are returned in
+// lower case, as GitHub logins are case insensitive.
+func LoadLogins(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	logins := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		logins[strings.ToLower(line)] = true
+	}
+	return logins, scanner.Err()
+}
+
+// Scrub removes the users with the specified (lower case) logins from
+// the stargazers, from their follower and following lists, and from
+// the contributor statistics of the repos, which may be nil. Returns
+// the remaining stargazers.
+func Scrub(sg []*Stargazer, rs map[string]*Repo, logins map[string]bool) []*Stargazer {
+	if len(logins) == 0 {
+		return sg
+	}
+	excluded := func(login string) bool { return logins[strings.ToLower(login)] }
+	scrubUsers := func(users []*User) []*User {
+		var kept []*User
+		for _, u := range users {
+			if !excluded(u.Login) {
+				kept = append(kept, u)
+			}
+		}
+		return kept
+	}
+	var kept []*Stargazer
+	for _, s := range sg {
+		if excluded(s.Login) {
+			continue
+		}
+		s.Followers = scrubUsers(s.Followers)
+		s.Following = scrubUsers(s.Following)
+		kept = append(kept, s)
+	}
+	for _, r := range rs {
+		for login := range r.Statistics {
+			if excluded(login) {
+				delete(r.Statistics, login)
+			}
+		}
+	}
+	return kept
+}
+
+// ScrubSnapshots removes the users with the specified (lower case)
+// logins from the snapshots.
+func ScrubSnapshots(snaps []*Snapshot, logins map[string]bool) {
+	for _, snap := range snaps {
+		var kept []*SnapshotEntry
+		for _, e := range snap.Stargazers {
+			if !logins[strings.ToLower(e.Login)] {
+				kept = append(kept, e)
+			}
+		}
+		snap.Stargazers = kept
+	}
+}
+
+// RestrictSnapshots keeps only the users with the specified (lower
+// case) logins in the snapshots.
+func RestrictSnapshots(snaps []*Snapshot, logins map[string]bool) {
+	for _, snap := range snaps {
+		var kept []*SnapshotEntry
+		for _, e := range snap.Stargazers {
+			if logins[strings.ToLower(e.Login)] {
+				kept = append(kept, e)
+			}
+		}
+		snap.Stargazers = kept
+	}
+}
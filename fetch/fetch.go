@@ -55,7 +55,7 @@ type httpError struct {
 
 // Error implements the error interface.
 func (e *httpError) Error() string {
-	return fmt.Sprintf("failed to fetch (req: %s): %s", e.req, e.resp)
+	return fmt.Sprintf("failed to fetch (req: %s): %s", e.req.URL, e.resp.Status)
 }
 
 // linkRE provides parsing of the "Link" HTTP header directive.
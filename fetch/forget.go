@@ -0,0 +1,132 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package fetch
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// forgottenFile is the file of the cache directory listing the
+// logins of forgotten users, which are skipped by future fetches of
+// any repo. It's kept outside the repo-specific subdirectories, so
+// that clearing a repo's cache doesn't resurrect them.
+const forgottenFile = "forgotten"
+
+// LoadForgotten returns the (lower case) logins of the users who
+// have been forgotten.
+func LoadForgotten(c *Context) (map[string]bool, error) {
+	logins, err := LoadLogins(filepath.Join(c.CacheDir, forgottenFile))
+	if os.IsNotExist(err) {
+		return map[string]bool{}, nil
+	}
+	return logins, err
+}
+
+// AddForgotten records a tombstone for the login in the list of
+// forgotten users, so that future fetches skip them.
+func AddForgotten(c *Context, login string) error {
+	forgotten, err := LoadForgotten(c)
+	if err != nil {
+		return err
+	}
+	if forgotten[strings.ToLower(login)] {
+		return nil
+	}
+	if err := os.MkdirAll(c.CacheDir, os.ModeDir|0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(c.CacheDir, forgottenFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintln(f, strings.ToLower(login))
+	return err
+}
+
+// Forget purges a user from the repo's cache directory: the cached
+// responses for their profile and their followers, following,
+// organizations, starred and subscribed lists are removed, and they're
+// scrubbed from the saved state and snapshots, including from the
+// follower lists of other stargazers and the contributor statistics
+// of repos. Record a tombstone with AddForgotten first, so that a
+// partial purge is completed by the next fetch.
+func Forget(c *Context, login string) error {
+	logins := map[string]bool{strings.ToLower(login): true}
+	sg, rs, err := LoadState(c)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	// Cache entries are named by URL, which uses the login as
+	// returned by GitHub.
+	for _, s := range sg {
+		if strings.EqualFold(s.Login, login) {
+			login = s.Login
+			break
+		}
+	}
+
+	starCtx := *c
+	starCtx.cacheSuffix = "star"
+	userURL := fmt.Sprintf("%susers/%s", githubAPI, login)
+	entries := 0
+	for _, u := range []struct {
+		c   *Context
+		url string
+	}{
+		{c, userURL},
+		{c, userURL + "/followers"},
+		{c, userURL + "/following"},
+		{c, userURL + "/orgs"},
+		{c, userURL + "/starred"},
+		{&starCtx, userURL + "/starred"},
+		{c, userURL + "/subscriptions"},
+	} {
+		n, err := clearPages(u.c, u.url)
+		if err != nil {
+			return err
+		}
+		entries += n
+	}
+	log.Printf("removed %d cached responses for %s", entries, login)
+
+	if sg != nil {
+		kept := Scrub(sg, rs, logins)
+		if err := SaveState(c, kept, rs); err != nil {
+			return err
+		}
+	}
+	snaps, err := LoadSnapshots(c)
+	if err != nil {
+		return err
+	}
+	for _, snap := range snaps {
+		before := len(snap.Stargazers)
+		ScrubSnapshots([]*Snapshot{snap}, logins)
+		if len(snap.Stargazers) == before {
+			continue
+		}
+		if _, err := writeSnapshot(c, snap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
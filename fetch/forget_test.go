@@ -0,0 +1,111 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package fetch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// putCachedResponse writes a cached response for the URL with the
+// specified Link header.
+func putCachedResponse(t *testing.T, c *Context, url, link string) {
+	body := "HTTP/1.1 200 OK\r\nLink: " + link + "\r\nContent-Length: 2\r\n\r\n[]"
+	if err := ioutil.WriteFile(cacheEntryFilename(c, url), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestForget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "forget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := &Context{Repo: "owner/repo", CacheDir: dir}
+	if err := os.MkdirAll(filepath.Join(dir, c.Repo), 0755); err != nil {
+		t.Fatal(err)
+	}
+	putCachedResponse(t, c, "https://api.github.com/users/Bob/starred",
+		`<https://api.github.com/user/1/starred?page=2>; rel="next", <https://api.github.com/user/1/starred?page=2>; rel="last"`)
+	putCachedResponse(t, c, "https://api.github.com/user/1/starred?page=2", "")
+	putCachedResponse(t, c, "https://api.github.com/users/Bob", "")
+	putCachedResponse(t, c, "https://api.github.com/users/Alice", "")
+
+	sg := []*Stargazer{
+		{User: User{Login: "Bob"}},
+		{User: User{Login: "Alice"}, Followers: []*User{{Login: "bob"}}},
+	}
+	rs := map[string]*Repo{"x/y": {Statistics: map[string]*Contribution{"Bob": {Commits: 1}}}}
+	if err := SaveState(c, sg, rs); err != nil {
+		t.Fatal(err)
+	}
+	snap := &Snapshot{FetchedAt: time.Unix(0, 0).UTC(), Stargazers: []*SnapshotEntry{{Login: "Bob"}, {Login: "Alice"}}}
+	if _, err := writeSnapshot(c, snap); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddForgotten(c, "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Forget(c, "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	for url, exists := range map[string]bool{
+		"https://api.github.com/users/Bob":             false,
+		"https://api.github.com/users/Bob/starred":     false,
+		"https://api.github.com/user/1/starred?page=2": false,
+		"https://api.github.com/users/Alice":           true,
+	} {
+		if _, err := os.Stat(cacheEntryFilename(c, url)); (err == nil) != exists {
+			t.Errorf("expected cache entry for %s to exist: %t", url, exists)
+		}
+	}
+	sg, rs, err = LoadState(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sg) != 1 || sg[0].Login != "Alice" || len(sg[0].Followers) != 0 {
+		t.Errorf("expected only Alice without followers in saved state; got %+v", sg)
+	}
+	if len(rs["x/y"].Statistics) != 0 {
+		t.Errorf("expected contributor statistics to be scrubbed; got %+v", rs["x/y"].Statistics)
+	}
+	snaps, err := LoadSnapshots(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 1 || len(snaps[0].Stargazers) != 1 {
+		t.Errorf("expected snapshot to be scrubbed; got %+v", snaps)
+	}
+
+	// The tombstone survives clearing the repo's cache.
+	if err := Clear(c); err != nil {
+		t.Fatal(err)
+	}
+	forgotten, err := LoadForgotten(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !forgotten["bob"] {
+		t.Errorf("expected bob to be forgotten; got %v", forgotten)
+	}
+}
@@ -46,11 +46,20 @@ const (
 
 // Context holds config information used to query GitHub.
 type Context struct {
-	Repo     string // Repository (:owner/:repo)
-	Token    string // Access token
-	CacheDir string // Cache directory
+	Repo      string // Repository (:owner/:repo)
+	Token     string // Access token
+	CacheDir  string // Cache directory
+	Following bool   // Query the list of users each stargazer follows
+	Orgs      bool   // Query each stargazer's public organization memberships
+	// StarredTimes queries the time at which each starred repo was
+	// starred, using the starred lists' alternate media type.
+	StarredTimes bool
+	// Exclude holds the (lower case) logins of users who have opted
+	// out; they're neither queried nor saved.
+	Exclude map[string]bool
 
 	acceptHeader string // Optional Accept: header value
+	cacheSuffix  string // Distinguishes cache entries of alternate media types
 }
 
 type User struct {
@@ -79,9 +88,9 @@ type User struct {
 	Following        int    `json:"following"`
 	CreatedAt        string `json:"created_at"`
 	UpdatedAt        string `json:"updated_at"`
+	OrganizationsURL string `json:"organizations_url"`
 
 	//GistsURL          string `json:"gists_url"`
-	//OrganizationsURL  string `json:"organizations_url"`
 	//ReposURL          string `json:"repos_url"`
 	//EventsURL         string `json:"events_url"`
 	//ReceivedEventsURL string `json:"received_events_url"`
@@ -146,9 +155,9 @@ type Repo struct {
 	OpenIssues      int    `json:"open_issues"`
 	Watchers        int    `json:"watchers"`
 	DefaultBranch   string `json:"default_branch"`
+	Description     string `json:"description"`
 
 	//Owner           User   `json:"owner"`
-	//Description     string `json:"description"`
 	//GitURL          string `json:"git_url"`
 	//SshHURL         string `json:"ssh_url"`
 	//CloneURL        string `json:"clone_url"`
@@ -183,17 +192,23 @@ type Stargazer struct {
 	StarredAt string `json:"starred_at"`
 
 	Followers  []*User  `json:"follower_list"`
+	Following  []*User  `json:"following_list,omitempty"`
 	Starred    []string `json:"starred"`    // Slice of repos by full name
 	Subscribed []string `json:"subscribed"` // Slice of repos by full name
 
+	// Public organization memberships (by organization login).
+	Orgs []string `json:"orgs,omitempty"`
+	// Times at which each of Starred was starred, if queried.
+	StarredTimes []string `json:"starred_times,omitempty"`
+
 	// Contributions to subscribed repos (by repo FullName).
 	Contributions map[string]*Contribution `json:"contributions"`
 }
 
-// Age returns the age (time from current time to created at
-// timestamp) of this stargazer in seconds.
-func (s *Stargazer) Age() int64 {
-	curDay := time.Now().Unix()
+// Age returns the age (time from the asOf reference time to created
+// at timestamp) of this stargazer in seconds.
+func (s *Stargazer) Age(asOf time.Time) int64 {
+	curDay := asOf.Unix()
 	createT, err := time.Parse(time.RFC3339, s.CreatedAt)
 	if err != nil {
 		log.Printf("failed to parse created at timestamp (%s): %s", s.CreatedAt, err)
@@ -217,11 +232,20 @@ func (s *Stargazer) TotalCommits() (int, int, int) {
 // QueryAll recursively descends into GitHub API endpoints, starting
 // with the list of stargazers for the repo.
 func QueryAll(c *Context) error {
+	// Skip users who have opted out or been forgotten.
+	exclude, err := LoadForgotten(c)
+	if err != nil {
+		return err
+	}
+	for login := range c.Exclude {
+		exclude[login] = true
+	}
 	// Query all stargazers for the repo.
 	sg, err := QueryStargazers(c)
 	if err != nil {
 		return err
 	}
+	sg = Scrub(sg, nil, exclude)
 	// Query stargazer user info for all stargazers.
 	if err = QueryUserInfo(c, sg); err != nil {
 		return err
@@ -230,6 +254,19 @@ func QueryAll(c *Context) error {
 	if err = QueryFollowers(c, sg); err != nil {
 		return err
 	}
+	// Optionally query followed users for all stargazers.
+	if c.Following {
+		if err = QueryFollowing(c, sg); err != nil {
+			return err
+		}
+	}
+
+	// Optionally query organization memberships for all stargazers.
+	if c.Orgs {
+		if err = QueryOrgs(c, sg); err != nil {
+			return err
+		}
+	}
 
 	// Unique map of repos by repo full name.
 	rs := map[string]*Repo{}
@@ -246,6 +283,11 @@ func QueryAll(c *Context) error {
 	if err = QueryContributions(c, sg, rs); err != nil {
 		return err
 	}
+	// Remove excluded users found in follower lists.
+	sg = Scrub(sg, rs, exclude)
+	if err = SaveSnapshot(c, sg); err != nil {
+		return err
+	}
 	return SaveState(c, sg, rs)
 }
 
@@ -314,28 +356,110 @@ func QueryFollowers(c *Context, sg []*Stargazer) error {
 	return nil
 }
 
-// QueryStarred queries all starred repos for each stargazer.
+// QueryFollowing queries the list of users each stargazer follows.
+func QueryFollowing(c *Context, sg []*Stargazer) error {
+	log.Printf("querying followed users for each of %s stargazers...", format(len(sg)))
+	total := 0
+	fmt.Printf("*** 0 followed users for 0 stargazers")
+	uniqueFollowing := map[int]struct{}{}
+	for i, s := range sg {
+		var err error
+		url := strings.Replace(s.FollowingURL, "{/other_user}", "", 1)
+		for len(url) > 0 {
+			fetched := []*User{}
+			url, err = fetchURL(c, url, &fetched, false /* don't refresh following */)
+			if err != nil {
+				return err
+			}
+			for _, u := range fetched {
+				uniqueFollowing[u.ID] = struct{}{}
+			}
+			s.Following = append(s.Following, fetched...)
+			total += len(fetched)
+			fmt.Printf("\r*** %s followed users (%s unique) for %s stargazers",
+				format(total), format(len(uniqueFollowing)), format(i+1))
+		}
+	}
+	fmt.Printf("\n")
+	return nil
+}
+
+// QueryOrgs queries the public organization memberships of each
+// stargazer.
+func QueryOrgs(c *Context, sg []*Stargazer) error {
+	log.Printf("querying organizations for each of %s stargazers...", format(len(sg)))
+	total := 0
+	fmt.Printf("*** 0 organizations for 0 stargazers")
+	for i, s := range sg {
+		var err error
+		url := s.OrganizationsURL
+		for len(url) > 0 {
+			fetched := []*User{}
+			url, err = fetchURL(c, url, &fetched, false /* don't refresh organizations */)
+			if err != nil {
+				return err
+			}
+			for _, o := range fetched {
+				s.Orgs = append(s.Orgs, o.Login)
+			}
+			total += len(fetched)
+			fmt.Printf("\r*** %s organizations for %s stargazers", format(total), format(i+1))
+		}
+	}
+	fmt.Printf("\n")
+	return nil
+}
+
+// starredRepo is an entry of a starred list fetched with the star
+// media type.
+type starredRepo struct {
+	StarredAt string `json:"starred_at"`
+	Repo      *Repo  `json:"repo"`
+}
+
+// QueryStarred queries all starred repos for each stargazer. If
+// StarredTimes is set, the time each repo was starred is queried too.
 func QueryStarred(c *Context, sg []*Stargazer, rs map[string]*Repo) error {
 	log.Printf("querying starred repos for each of %s stargazers...", format(len(sg)))
 	starred := 0
 	fmt.Printf("*** 0 starred repos for 0 stargazers")
 	uniqueStarred := map[int]struct{}{}
+	cCopy := *c
+	if c.StarredTimes {
+		cCopy.acceptHeader = "application/vnd.github.v3.star+json"
+		cCopy.cacheSuffix = "star"
+	}
 	for i, s := range sg {
 		var err error
 		url := s.StarredURL
 		url = strings.Replace(url, "{/owner}{/repo}", "", 1)
 		for len(url) > 0 && len(s.Starred) < maxStarred {
-			fetched := []*Repo{}
-			url, err = fetchURL(c, url, &fetched, false /* don't refresh starred repos */)
+			fetched := []*starredRepo{}
+			if c.StarredTimes {
+				url, err = fetchURL(&cCopy, url, &fetched, false /* don't refresh starred repos */)
+			} else {
+				repos := []*Repo{}
+				url, err = fetchURL(c, url, &repos, false /* don't refresh starred repos */)
+				for _, r := range repos {
+					fetched = append(fetched, &starredRepo{Repo: r})
+				}
+			}
 			if err != nil {
 				return err
 			}
-			for _, r := range fetched {
+			for _, f := range fetched {
+				r := f.Repo
+				if r == nil {
+					continue
+				}
 				if _, ok := rs[r.FullName]; !ok {
 					rs[r.FullName] = r
 				}
 				uniqueStarred[r.ID] = struct{}{}
 				s.Starred = append(s.Starred, r.FullName)
+				if c.StarredTimes {
+					s.StarredTimes = append(s.StarredTimes, f.StarredAt)
+				}
 			}
 			starred += len(fetched)
 			fmt.Printf("\r*** %s starred repos (%s unique) for %s stargazers",
@@ -488,6 +612,25 @@ func LoadState(c *Context) ([]*Stargazer, map[string]*Repo, error) {
 	return sg, rs, nil
 }
 
+// StateTime returns the time at which the saved state was fetched:
+// that of the latest snapshot or, if the repo was last fetched before
+// snapshots were introduced, the modification time of the saved
+// state.
+func StateTime(c *Context) (time.Time, error) {
+	snaps, err := LoadSnapshots(c)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(snaps) > 0 {
+		return snaps[len(snaps)-1].FetchedAt, nil
+	}
+	fi, err := os.Stat(filepath.Join(c.CacheDir, c.Repo, "saved_state"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return fi.ModTime().UTC().Truncate(time.Second), nil
+}
+
 func format(n int) string {
 	in := strconv.FormatInt(int64(n), 10)
 	out := make([]byte, len(in)+(len(in)-2+int(in[0]/'0'))/3)
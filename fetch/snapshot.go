@@ -0,0 +1,127 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package fetch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshotDir is the subdirectory of a repo's cache directory which
+// holds stargazer snapshots.
+const snapshotDir = "snapshots"
+
+// SnapshotEntry is a stargazer listed in a snapshot.
+type SnapshotEntry struct {
+	Login     string `json:"login"`
+	StarredAt string `json:"starred_at"`
+}
+
+// A Snapshot records the stargazers of the repo as of a fetch, so
+// that stargazers who later remove their star can be identified.
+type Snapshot struct {
+	FetchedAt  time.Time        `json:"fetched_at"`
+	Stargazers []*SnapshotEntry `json:"stargazers"`
+}
+
+// SaveSnapshot writes a snapshot of the stargazers to a new file in
+// the snapshots subdirectory of the repo's cache directory.
+func SaveSnapshot(c *Context, sg []*Stargazer) error {
+	snap := &Snapshot{FetchedAt: time.Now().UTC().Truncate(time.Second)}
+	for _, s := range sg {
+		snap.Stargazers = append(snap.Stargazers, &SnapshotEntry{Login: s.Login, StarredAt: s.StarredAt})
+	}
+	filename, err := writeSnapshot(c, snap)
+	if err != nil {
+		return err
+	}
+	log.Printf("saved snapshot of %s stargazers to %s", format(len(sg)), filename)
+	return nil
+}
+
+// writeSnapshot writes the snapshot to the file named by its fetch
+// time, replacing any existing file, and returns the file's name.
+func writeSnapshot(c *Context, snap *Snapshot) (string, error) {
+	dir := filepath.Join(c.CacheDir, c.Repo, snapshotDir)
+	if err := os.MkdirAll(dir, os.ModeDir|0755); err != nil {
+		return "", err
+	}
+	filename := filepath.Join(dir, snap.FetchedAt.Format("20060102T150405Z")+".json")
+	f, err := os.Create(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(snap); err != nil {
+		return "", errors.New(fmt.Sprintf("failed to encode snapshot: %s", err))
+	}
+	return filename, nil
+}
+
+// LoadSnapshots reads all stargazer snapshots of the repo, from
+// oldest to newest. There are none if the repo was last fetched
+// before snapshots were introduced.
+func LoadSnapshots(c *Context) ([]*Snapshot, error) {
+	dir := filepath.Join(c.CacheDir, c.Repo, snapshotDir)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snaps []*Snapshot
+	for _, fi := range files {
+		if !strings.HasSuffix(fi.Name(), ".json") {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		snap := &Snapshot{}
+		if err := json.Unmarshal(data, snap); err != nil {
+			return nil, errors.New(fmt.Sprintf("failed to decode snapshot %s: %s", fi.Name(), err))
+		}
+		snaps = append(snaps, snap)
+	}
+	sort.Sort(snapshots(snaps))
+	return snaps, nil
+}
+
+// snapshots sorts snapshots from oldest to newest.
+type snapshots []*Snapshot
+
+func (slice snapshots) Len() int {
+	return len(slice)
+}
+
+func (slice snapshots) Less(i, j int) bool {
+	return slice[i].FetchedAt.Before(slice[j].FetchedAt)
+}
+
+func (slice snapshots) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
@@ -0,0 +1,410 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package geo
+
+// The gazetteer covers the countries, regions and cities in which
+// most GitHub users live. Names and aliases are lower case; the
+// first alias of a region is its abbreviation if it has one. Cities
+// sharing a name are listed in descending order of population, so
+// the first is preferred when a location is otherwise ambiguous.
+
+type country struct {
+	code     string // ISO 3166-1 alpha-2
+	name     string
+	lat, lon float64
+	aliases  []string
+}
+
+type region struct {
+	country  string // ISO 3166-1 alpha-2
+	name     string
+	abbrev   string // Matched only following another part, e.g. "Austin, TX"
+	lat, lon float64
+	aliases  []string
+}
+
+type city struct {
+	name     string
+	region   string // Region name, if in the gazetteer
+	country  string // ISO 3166-1 alpha-2
+	lat, lon float64
+	aliases  []string
+}
+
+var countries = []country{
+	{"US", "United States", 39.8, -98.6, []string{"usa", "us", "u.s.", "u.s.a.", "united states of america", "america"}},
+	{"CA", "Canada", 56.1, -106.3, nil},
+	{"MX", "Mexico", 23.6, -102.6, []string{"méxico"}},
+	{"BR", "Brazil", -14.2, -51.9, []string{"brasil"}},
+	{"AR", "Argentina", -38.4, -63.6, nil},
+	{"CL", "Chile", -35.7, -71.5, nil},
+	{"CO", "Colombia", 4.6, -74.3, nil},
+	{"PE", "Peru", -9.2, -75.0, []string{"perú"}},
+	{"GB", "United Kingdom", 55.4, -3.4, []string{"uk", "u.k.", "great britain", "britain", "gb"}},
+	{"IE", "Ireland", 53.4, -8.2, []string{"éire"}},
+	{"FR", "France", 46.2, 2.2, nil},
+	{"DE", "Germany", 51.2, 10.5, []string{"deutschland"}},
+	{"NL", "Netherlands", 52.1, 5.3, []string{"the netherlands", "nederland", "holland"}},
+	{"BE", "Belgium", 50.5, 4.5, []string{"belgië", "belgique"}},
+	{"LU", "Luxembourg", 49.8, 6.1, nil},
+	{"CH", "Switzerland", 46.8, 8.2, []string{"schweiz", "suisse", "svizzera"}},
+	{"AT", "Austria", 47.5, 14.6, []string{"österreich", "osterreich"}},
+	{"ES", "Spain", 40.5, -3.7, []string{"españa", "espana"}},
+	{"PT", "Portugal", 39.4, -8.2, nil},
+	{"IT", "Italy", 41.9, 12.6, []string{"italia"}},
+	{"SE", "Sweden", 60.1, 18.6, []string{"sverige"}},
+	{"NO", "Norway", 60.5, 8.5, []string{"norge"}},
+	{"DK", "Denmark", 56.3, 9.5, []string{"danmark"}},
+	{"FI", "Finland", 61.9, 25.7, []string{"suomi"}},
+	{"IS", "Iceland", 64.9, -19.0, []string{"ísland"}},
+	{"PL", "Poland", 51.9, 19.1, []string{"polska"}},
+	{"CZ", "Czech Republic", 49.8, 15.5, []string{"czechia", "česká republika", "ceska republika"}},
+	{"SK", "Slovakia", 48.7, 19.7, []string{"slovensko"}},
+	{"HU", "Hungary", 47.2, 19.5, []string{"magyarország"}},
+	{"RO", "Romania", 45.9, 25.0, []string{"românia"}},
+	{"BG", "Bulgaria", 42.7, 25.5, nil},
+	{"GR", "Greece", 39.1, 21.8, []string{"hellas"}},
+	{"TR", "Turkey", 39.0, 35.2, []string{"türkiye", "turkiye"}},
+	{"UA", "Ukraine", 48.4, 31.2, []string{"україна"}},
+	{"RU", "Russia", 61.5, 105.3, []string{"russian federation", "россия"}},
+	{"BY", "Belarus", 53.7, 28.0, nil},
+	{"LT", "Lithuania", 55.2, 23.9, []string{"lietuva"}},
+	{"LV", "Latvia", 56.9, 24.6, []string{"latvija"}},
+	{"EE", "Estonia", 58.6, 25.0, []string{"eesti"}},
+	{"RS", "Serbia", 44.0, 21.0, []string{"srbija"}},
+	{"HR", "Croatia", 45.1, 15.2, []string{"hrvatska"}},
+	{"SI", "Slovenia", 46.2, 15.0, []string{"slovenija"}},
+	{"IL", "Israel", 31.0, 34.9, nil},
+	{"AE", "United Arab Emirates", 23.4, 53.8, []string{"uae"}},
+	{"SA", "Saudi Arabia", 23.9, 45.1, nil},
+	{"EG", "Egypt", 26.8, 30.8, nil},
+	{"NG", "Nigeria", 9.1, 8.7, nil},
+	{"KE", "Kenya", 0.0, 37.9, nil},
+	{"ZA", "South Africa", -30.6, 22.9, nil},
+	{"MA", "Morocco", 31.8, -7.1, nil},
+	{"IN", "India", 20.6, 79.0, nil},
+	{"PK", "Pakistan", 30.4, 69.3, nil},
+	{"BD", "Bangladesh", 23.7, 90.4, nil},
+	{"LK", "Sri Lanka", 7.9, 80.8, nil},
+	{"NP", "Nepal", 28.4, 84.1, nil},
+	{"IR", "Iran", 32.4, 53.7, nil},
+	{"CN", "China", 35.9, 104.2, []string{"prc", "people's republic of china", "中国"}},
+	{"HK", "Hong Kong", 22.3, 114.2, nil},
+	{"TW", "Taiwan", 23.7, 121.0, nil},
+	{"JP", "Japan", 36.2, 138.3, []string{"日本"}},
+	{"KR", "South Korea", 35.9, 127.8, []string{"korea", "republic of korea"}},
+	{"SG", "Singapore", 1.35, 103.8, nil},
+	{"MY", "Malaysia", 4.2, 102.0, nil},
+	{"ID", "Indonesia", -0.8, 113.9, nil},
+	{"TH", "Thailand", 15.9, 101.0, nil},
+	{"VN", "Vietnam", 14.1, 108.3, []string{"viet nam"}},
+	{"PH", "Philippines", 12.9, 121.8, nil},
+	{"AU", "Australia", -25.3, 133.8, nil},
+	{"NZ", "New Zealand", -40.9, 174.9, nil},
+}
+
+var regions = []region{
+	{"US", "Alabama", "al", 32.8, -86.8, nil},
+	{"US", "Alaska", "ak", 64.2, -149.5, nil},
+	{"US", "Arizona", "az", 34.0, -111.1, nil},
+	{"US", "Arkansas", "ar", 34.8, -92.2, nil},
+	{"US", "California", "ca", 36.8, -119.4, []string{"calif", "socal", "norcal"}},
+	{"US", "Colorado", "co", 39.1, -105.4, nil},
+	{"US", "Connecticut", "ct", 41.6, -72.7, nil},
+	{"US", "Delaware", "de", 39.0, -75.5, nil},
+	{"US", "District of Columbia", "dc", 38.9, -77.0, []string{"d.c."}},
+	{"US", "Florida", "fl", 27.8, -81.7, nil},
+	{"US", "Georgia", "ga", 32.2, -83.4, nil},
+	{"US", "Hawaii", "hi", 19.9, -155.6, nil},
+	{"US", "Idaho", "id", 44.1, -114.7, nil},
+	{"US", "Illinois", "il", 40.0, -89.2, nil},
+	{"US", "Indiana", "in", 40.3, -86.1, nil},
+	{"US", "Iowa", "ia", 42.0, -93.2, nil},
+	{"US", "Kansas", "ks", 38.5, -98.4, nil},
+	{"US", "Kentucky", "ky", 37.8, -84.3, nil},
+	{"US", "Louisiana", "la", 31.2, -91.9, nil},
+	{"US", "Maine", "me", 45.3, -69.4, nil},
+	{"US", "Maryland", "md", 39.0, -76.6, nil},
+	{"US", "Massachusetts", "ma", 42.4, -71.4, nil},
+	{"US", "Michigan", "mi", 44.3, -85.6, nil},
+	{"US", "Minnesota", "mn", 46.7, -94.7, nil},
+	{"US", "Mississippi", "ms", 32.4, -89.4, nil},
+	{"US", "Missouri", "mo", 38.5, -92.3, nil},
+	{"US", "Montana", "mt", 46.9, -110.4, nil},
+	{"US", "Nebraska", "ne", 41.5, -99.9, nil},
+	{"US", "Nevada", "nv", 38.8, -116.4, nil},
+	{"US", "New Hampshire", "nh", 43.2, -71.6, nil},
+	{"US", "New Jersey", "nj", 40.1, -74.4, nil},
+	{"US", "New Mexico", "nm", 34.5, -106.0, nil},
+	{"US", "New York", "ny", 42.9, -75.5, nil},
+	{"US", "North Carolina", "nc", 35.6, -79.0, nil},
+	{"US", "North Dakota", "nd", 47.5, -100.5, nil},
+	{"US", "Ohio", "oh", 40.4, -82.9, nil},
+	{"US", "Oklahoma", "ok", 35.0, -97.1, nil},
+	{"US", "Oregon", "or", 43.8, -120.6, nil},
+	{"US", "Pennsylvania", "pa", 41.2, -77.2, nil},
+	{"US", "Rhode Island", "ri", 41.7, -71.5, nil},
+	{"US", "South Carolina", "sc", 33.8, -81.2, nil},
+	{"US", "South Dakota", "sd", 43.9, -99.4, nil},
+	{"US", "Tennessee", "tn", 35.5, -86.6, nil},
+	{"US", "Texas", "tx", 31.0, -99.9, nil},
+	{"US", "Utah", "ut", 39.3, -111.1, nil},
+	{"US", "Vermont", "vt", 44.6, -72.6, nil},
+	{"US", "Virginia", "va", 37.4, -78.7, nil},
+	{"US", "Washington", "wa", 47.4, -120.7, nil},
+	{"US", "West Virginia", "wv", 38.6, -80.6, nil},
+	{"US", "Wisconsin", "wi", 43.8, -88.8, nil},
+	{"US", "Wyoming", "wy", 43.1, -107.6, nil},
+	{"CA", "Ontario", "on", 50.0, -85.0, nil},
+	{"CA", "Quebec", "qc", 52.9, -73.5, []string{"québec"}},
+	{"CA", "British Columbia", "bc", 53.7, -127.6, nil},
+	{"CA", "Alberta", "ab", 53.9, -116.6, nil},
+	{"CA", "Manitoba", "mb", 53.8, -98.8, nil},
+	{"CA", "Saskatchewan", "sk", 52.9, -106.5, nil},
+	{"CA", "Nova Scotia", "ns", 44.7, -63.7, nil},
+	{"CA", "New Brunswick", "nb", 46.6, -66.5, nil},
+	{"AU", "New South Wales", "nsw", -31.8, 147.0, nil},
+	{"AU", "Victoria", "vic", -37.0, 144.5, nil},
+	{"AU", "Queensland", "qld", -20.9, 142.7, nil},
+	{"AU", "Western Australia", "", -27.7, 121.6, nil},
+	{"AU", "South Australia", "", -30.0, 136.2, nil},
+	{"AU", "Australian Capital Territory", "act", -35.5, 149.0, nil},
+	{"GB", "England", "", 52.4, -1.5, nil},
+	{"GB", "Scotland", "", 56.5, -4.2, nil},
+	{"GB", "Wales", "", 52.1, -3.8, nil},
+	{"GB", "Northern Ireland", "", 54.8, -6.5, nil},
+	{"DE", "Bavaria", "", 48.8, 11.5, []string{"bayern"}},
+	{"DE", "Baden-Württemberg", "", 48.7, 9.2, []string{"baden-wurttemberg", "baden-wuerttemberg"}},
+	{"DE", "North Rhine-Westphalia", "nrw", 51.4, 7.7, []string{"nordrhein-westfalen"}},
+	{"FR", "Île-de-France", "", 48.7, 2.5, []string{"ile-de-france", "ile de france"}},
+	{"IN", "Karnataka", "", 15.3, 75.7, nil},
+	{"IN", "Maharashtra", "", 19.8, 75.7, nil},
+	{"IN", "Telangana", "", 18.1, 79.0, nil},
+	{"IN", "Tamil Nadu", "", 11.1, 78.7, nil},
+	{"IN", "West Bengal", "", 22.9, 87.9, nil},
+	{"IN", "Uttar Pradesh", "", 26.8, 80.9, nil},
+	{"IN", "Haryana", "", 29.1, 76.1, nil},
+	{"IN", "Gujarat", "", 22.3, 71.2, nil},
+	{"IN", "Kerala", "", 10.9, 76.3, nil},
+	{"CN", "Guangdong", "", 23.4, 113.5, nil},
+	{"CN", "Zhejiang", "", 29.1, 120.1, nil},
+	{"CN", "Sichuan", "", 30.3, 102.8, nil},
+}
+
+var cities = []city{
+	// United States.
+	{"San Francisco", "California", "US", 37.77, -122.42, []string{"sf", "san fran", "bay area", "sf bay area",
+		"san francisco bay area", "silicon valley"}},
+	{"New York", "New York", "US", 40.71, -74.01, []string{"nyc", "new york city", "brooklyn", "manhattan", "queens"}},
+	{"Seattle", "Washington", "US", 47.61, -122.33, nil},
+	{"Los Angeles", "California", "US", 34.05, -118.24, []string{"la", "l.a."}},
+	{"San Jose", "California", "US", 37.34, -121.89, nil},
+	{"Palo Alto", "California", "US", 37.44, -122.14, nil},
+	{"Mountain View", "California", "US", 37.39, -122.08, nil},
+	{"Sunnyvale", "California", "US", 37.37, -122.04, nil},
+	{"Oakland", "California", "US", 37.80, -122.27, nil},
+	{"Berkeley", "California", "US", 37.87, -122.27, nil},
+	{"San Mateo", "California", "US", 37.56, -122.32, nil},
+	{"Santa Clara", "California", "US", 37.35, -121.96, nil},
+	{"Redwood City", "California", "US", 37.49, -122.24, nil},
+	{"Menlo Park", "California", "US", 37.45, -122.18, nil},
+	{"Cupertino", "California", "US", 37.32, -122.03, nil},
+	{"San Diego", "California", "US", 32.72, -117.16, nil},
+	{"Sacramento", "California", "US", 38.58, -121.49, nil},
+	{"Irvine", "California", "US", 33.68, -117.83, nil},
+	{"Portland", "Oregon", "US", 45.52, -122.68, []string{"pdx"}},
+	{"Portland", "Maine", "US", 43.66, -70.26, nil},
+	{"Boston", "Massachusetts", "US", 42.36, -71.06, nil},
+	{"Cambridge", "Massachusetts", "US", 42.37, -71.11, nil},
+	{"Chicago", "Illinois", "US", 41.88, -87.63, nil},
+	{"Austin", "Texas", "US", 30.27, -97.74, nil},
+	{"Dallas", "Texas", "US", 32.78, -96.80, nil},
+	{"Houston", "Texas", "US", 29.76, -95.37, nil},
+	{"San Antonio", "Texas", "US", 29.42, -98.49, nil},
+	{"Denver", "Colorado", "US", 39.74, -104.99, nil},
+	{"Boulder", "Colorado", "US", 40.01, -105.27, nil},
+	{"Atlanta", "Georgia", "US", 33.75, -84.39, nil},
+	{"Miami", "Florida", "US", 25.76, -80.19, nil},
+	{"Orlando", "Florida", "US", 28.54, -81.38, nil},
+	{"Tampa", "Florida", "US", 27.95, -82.46, nil},
+	{"Washington", "District of Columbia", "US", 38.91, -77.04, []string{"washington dc", "washington d.c."}},
+	{"Philadelphia", "Pennsylvania", "US", 39.95, -75.17, []string{"philly"}},
+	{"Pittsburgh", "Pennsylvania", "US", 40.44, -79.99, nil},
+	{"Baltimore", "Maryland", "US", 39.29, -76.61, nil},
+	{"Minneapolis", "Minnesota", "US", 44.98, -93.27, nil},
+	{"Detroit", "Michigan", "US", 42.33, -83.05, nil},
+	{"Ann Arbor", "Michigan", "US", 42.28, -83.74, nil},
+	{"Salt Lake City", "Utah", "US", 40.76, -111.89, []string{"slc"}},
+	{"Phoenix", "Arizona", "US", 33.45, -112.07, nil},
+	{"Las Vegas", "Nevada", "US", 36.17, -115.14, nil},
+	{"Raleigh", "North Carolina", "US", 35.78, -78.64, nil},
+	{"Durham", "North Carolina", "US", 35.99, -78.90, nil},
+	{"Nashville", "Tennessee", "US", 36.16, -86.78, nil},
+	{"Columbus", "Ohio", "US", 39.96, -83.00, nil},
+	{"Cleveland", "Ohio", "US", 41.50, -81.69, nil},
+	{"St. Louis", "Missouri", "US", 38.63, -90.20, []string{"st louis", "saint louis"}},
+	{"Kansas City", "Missouri", "US", 39.10, -94.58, nil},
+	{"Madison", "Wisconsin", "US", 43.07, -89.40, nil},
+	{"Indianapolis", "Indiana", "US", 39.77, -86.16, nil},
+	{"New Orleans", "Louisiana", "US", 29.95, -90.07, nil},
+	{"Honolulu", "Hawaii", "US", 21.31, -157.86, nil},
+
+	// Canada and Latin America.
+	{"Toronto", "Ontario", "CA", 43.65, -79.38, nil},
+	{"Montreal", "Quebec", "CA", 45.50, -73.57, []string{"montréal"}},
+	{"Vancouver", "British Columbia", "CA", 49.28, -123.12, nil},
+	{"Ottawa", "Ontario", "CA", 45.42, -75.70, nil},
+	{"Calgary", "Alberta", "CA", 51.05, -114.07, nil},
+	{"Edmonton", "Alberta", "CA", 53.55, -113.49, nil},
+	{"Waterloo", "Ontario", "CA", 43.46, -80.52, nil},
+	{"Mexico City", "", "MX", 19.43, -99.13, []string{"cdmx", "ciudad de méxico", "ciudad de mexico"}},
+	{"Guadalajara", "", "MX", 20.67, -103.35, nil},
+	{"São Paulo", "", "BR", -23.55, -46.63, []string{"sao paulo"}},
+	{"Rio de Janeiro", "", "BR", -22.91, -43.17, nil},
+	{"Belo Horizonte", "", "BR", -19.92, -43.94, nil},
+	{"Porto Alegre", "", "BR", -30.03, -51.23, nil},
+	{"Curitiba", "", "BR", -25.43, -49.27, nil},
+	{"Buenos Aires", "", "AR", -34.60, -58.38, nil},
+	{"Santiago", "", "CL", -33.45, -70.67, nil},
+	{"Bogotá", "", "CO", 4.71, -74.07, []string{"bogota"}},
+	{"Medellín", "", "CO", 6.24, -75.58, []string{"medellin"}},
+	{"Lima", "", "PE", -12.05, -77.04, nil},
+
+	// Europe.
+	{"London", "England", "GB", 51.51, -0.13, nil},
+	{"Manchester", "England", "GB", 53.48, -2.24, nil},
+	{"Bristol", "England", "GB", 51.45, -2.59, nil},
+	{"Oxford", "England", "GB", 51.75, -1.26, nil},
+	{"Cambridge", "England", "GB", 52.21, 0.12, nil},
+	{"Edinburgh", "Scotland", "GB", 55.95, -3.19, nil},
+	{"Glasgow", "Scotland", "GB", 55.86, -4.25, nil},
+	{"Dublin", "", "IE", 53.35, -6.26, nil},
+	{"Paris", "Île-de-France", "FR", 48.86, 2.35, nil},
+	{"Lyon", "", "FR", 45.76, 4.84, nil},
+	{"Toulouse", "", "FR", 43.60, 1.44, nil},
+	{"Berlin", "", "DE", 52.52, 13.40, nil},
+	{"Munich", "Bavaria", "DE", 48.14, 11.58, []string{"münchen", "muenchen"}},
+	{"Hamburg", "", "DE", 53.55, 9.99, nil},
+	{"Frankfurt", "", "DE", 50.11, 8.68, []string{"frankfurt am main"}},
+	{"Cologne", "North Rhine-Westphalia", "DE", 50.94, 6.96, []string{"köln", "koln"}},
+	{"Stuttgart", "Baden-Württemberg", "DE", 48.78, 9.18, nil},
+	{"Karlsruhe", "Baden-Württemberg", "DE", 49.01, 8.40, nil},
+	{"Dresden", "", "DE", 51.05, 13.74, nil},
+	{"Amsterdam", "", "NL", 52.37, 4.90, nil},
+	{"Rotterdam", "", "NL", 51.92, 4.48, nil},
+	{"Utrecht", "", "NL", 52.09, 5.12, nil},
+	{"The Hague", "", "NL", 52.08, 4.30, []string{"den haag"}},
+	{"Eindhoven", "", "NL", 51.44, 5.47, nil},
+	{"Brussels", "", "BE", 50.85, 4.35, []string{"bruxelles", "brussel"}},
+	{"Zurich", "", "CH", 47.38, 8.54, []string{"zürich"}},
+	{"Geneva", "", "CH", 46.20, 6.14, []string{"genève", "geneve"}},
+	{"Vienna", "", "AT", 48.21, 16.37, []string{"wien"}},
+	{"Madrid", "", "ES", 40.42, -3.70, nil},
+	{"Barcelona", "", "ES", 41.39, 2.17, nil},
+	{"Valencia", "", "ES", 39.47, -0.38, nil},
+	{"Lisbon", "", "PT", 38.72, -9.14, []string{"lisboa"}},
+	{"Porto", "", "PT", 41.15, -8.61, nil},
+	{"Rome", "", "IT", 41.90, 12.50, []string{"roma"}},
+	{"Milan", "", "IT", 45.46, 9.19, []string{"milano"}},
+	{"Turin", "", "IT", 45.07, 7.69, []string{"torino"}},
+	{"Stockholm", "", "SE", 59.33, 18.07, nil},
+	{"Gothenburg", "", "SE", 57.71, 11.97, []string{"göteborg", "goteborg"}},
+	{"Oslo", "", "NO", 59.91, 10.75, nil},
+	{"Copenhagen", "", "DK", 55.68, 12.57, []string{"københavn", "kobenhavn"}},
+	{"Helsinki", "", "FI", 60.17, 24.94, nil},
+	{"Warsaw", "", "PL", 52.23, 21.01, []string{"warszawa"}},
+	{"Kraków", "", "PL", 50.06, 19.94, []string{"krakow", "cracow"}},
+	{"Wrocław", "", "PL", 51.11, 17.04, []string{"wroclaw"}},
+	{"Prague", "", "CZ", 50.08, 14.44, []string{"praha"}},
+	{"Brno", "", "CZ", 49.20, 16.61, nil},
+	{"Budapest", "", "HU", 47.50, 19.04, nil},
+	{"Bucharest", "", "RO", 44.43, 26.10, []string{"bucurești", "bucuresti"}},
+	{"Cluj-Napoca", "", "RO", 46.77, 23.62, []string{"cluj"}},
+	{"Sofia", "", "BG", 42.70, 23.32, nil},
+	{"Athens", "", "GR", 37.98, 23.73, nil},
+	{"Istanbul", "", "TR", 41.01, 28.98, []string{"i̇stanbul"}},
+	{"Ankara", "", "TR", 39.93, 32.86, nil},
+	{"Kyiv", "", "UA", 50.45, 30.52, []string{"kiev"}},
+	{"Kharkiv", "", "UA", 49.99, 36.23, []string{"kharkov"}},
+	{"Lviv", "", "UA", 49.84, 24.03, nil},
+	{"Moscow", "", "RU", 55.76, 37.62, []string{"moskva", "москва"}},
+	{"Saint Petersburg", "", "RU", 59.93, 30.34, []string{"st. petersburg", "st petersburg", "spb"}},
+	{"Novosibirsk", "", "RU", 55.01, 82.93, nil},
+	{"Minsk", "", "BY", 53.90, 27.56, nil},
+	{"Vilnius", "", "LT", 54.69, 25.28, nil},
+	{"Riga", "", "LV", 56.95, 24.11, nil},
+	{"Tallinn", "", "EE", 59.44, 24.75, nil},
+	{"Belgrade", "", "RS", 44.79, 20.45, []string{"beograd"}},
+	{"Zagreb", "", "HR", 45.81, 15.98, nil},
+	{"Ljubljana", "", "SI", 46.06, 14.51, nil},
+
+	// Middle East and Africa.
+	{"Tel Aviv", "", "IL", 32.09, 34.78, []string{"tel-aviv", "tel aviv-yafo"}},
+	{"Jerusalem", "", "IL", 31.77, 35.21, nil},
+	{"Dubai", "", "AE", 25.20, 55.27, nil},
+	{"Tehran", "", "IR", 35.69, 51.39, nil},
+	{"Cairo", "", "EG", 30.04, 31.24, nil},
+	{"Lagos", "", "NG", 6.52, 3.38, nil},
+	{"Nairobi", "", "KE", -1.29, 36.82, nil},
+	{"Cape Town", "", "ZA", -33.92, 18.42, nil},
+	{"Johannesburg", "", "ZA", -26.20, 28.05, nil},
+
+	// Asia and Oceania.
+	{"Bangalore", "Karnataka", "IN", 12.97, 77.59, []string{"bengaluru"}},
+	{"Mumbai", "Maharashtra", "IN", 19.08, 72.88, []string{"bombay"}},
+	{"Pune", "Maharashtra", "IN", 18.52, 73.86, nil},
+	{"Delhi", "", "IN", 28.70, 77.10, []string{"new delhi"}},
+	{"Hyderabad", "Telangana", "IN", 17.39, 78.49, nil},
+	{"Chennai", "Tamil Nadu", "IN", 13.08, 80.27, []string{"madras"}},
+	{"Kolkata", "West Bengal", "IN", 22.57, 88.36, []string{"calcutta"}},
+	{"Noida", "Uttar Pradesh", "IN", 28.54, 77.39, nil},
+	{"Gurgaon", "Haryana", "IN", 28.46, 77.03, []string{"gurugram"}},
+	{"Ahmedabad", "Gujarat", "IN", 23.02, 72.57, nil},
+	{"Karachi", "", "PK", 24.86, 67.00, nil},
+	{"Lahore", "", "PK", 31.55, 74.34, nil},
+	{"Dhaka", "", "BD", 23.81, 90.41, nil},
+	{"Colombo", "", "LK", 6.93, 79.86, nil},
+	{"Kathmandu", "", "NP", 27.72, 85.32, nil},
+	{"Beijing", "", "CN", 39.90, 116.41, []string{"peking", "北京"}},
+	{"Shanghai", "", "CN", 31.23, 121.47, []string{"上海"}},
+	{"Shenzhen", "Guangdong", "CN", 22.54, 114.06, []string{"深圳"}},
+	{"Guangzhou", "Guangdong", "CN", 23.13, 113.26, []string{"广州"}},
+	{"Hangzhou", "Zhejiang", "CN", 30.27, 120.16, []string{"杭州"}},
+	{"Chengdu", "Sichuan", "CN", 30.57, 104.07, []string{"成都"}},
+	{"Hong Kong", "", "HK", 22.32, 114.17, nil},
+	{"Taipei", "", "TW", 25.03, 121.57, nil},
+	{"Tokyo", "", "JP", 35.68, 139.69, []string{"東京"}},
+	{"Osaka", "", "JP", 34.69, 135.50, nil},
+	{"Kyoto", "", "JP", 35.01, 135.77, nil},
+	{"Seoul", "", "KR", 37.57, 126.98, nil},
+	{"Singapore", "", "SG", 1.35, 103.82, nil},
+	{"Kuala Lumpur", "", "MY", 3.14, 101.69, []string{"kl"}},
+	{"Jakarta", "", "ID", -6.21, 106.85, nil},
+	{"Bandung", "", "ID", -6.92, 107.62, nil},
+	{"Bangkok", "", "TH", 13.76, 100.50, nil},
+	{"Ho Chi Minh City", "", "VN", 10.82, 106.63, []string{"saigon", "hcmc"}},
+	{"Hanoi", "", "VN", 21.03, 105.85, []string{"ha noi"}},
+	{"Manila", "", "PH", 14.60, 120.98, nil},
+	{"Sydney", "New South Wales", "AU", -33.87, 151.21, nil},
+	{"Melbourne", "Victoria", "AU", -37.81, 144.96, nil},
+	{"Brisbane", "Queensland", "AU", -27.47, 153.03, nil},
+	{"Perth", "Western Australia", "AU", -31.95, 115.86, nil},
+	{"Adelaide", "South Australia", "AU", -34.93, 138.60, nil},
+	{"Canberra", "Australian Capital Territory", "AU", -35.28, 149.13, nil},
+	{"Auckland", "", "NZ", -36.85, 174.76, nil},
+	{"Wellington", "", "NZ", -41.29, 174.78, nil},
+}
@@ -0,0 +1,267 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+// Package geo normalizes free-text GitHub profile locations against
+// an embedded gazetteer of countries, regions and cities.
+package geo
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Confidence levels of a normalized place.
+const (
+	// ConfirmedCity is a city whose region or country is also named.
+	ConfirmedCity = 0.95
+	// Country is a country named without a known city.
+	Country = 0.9
+	// City is a city named without a region or country, which is
+	// unique in the gazetteer.
+	City = 0.85
+	// Region is a region named without a known city.
+	Region = 0.8
+	// AmbiguousCity is a city named without a region or country,
+	// which shares its name with other cities in the gazetteer.
+	AmbiguousCity = 0.5
+	// embeddedFactor scales the confidence of names found within a
+	// longer phrase, e.g. "living in Berlin".
+	embeddedFactor = 0.8
+	// maxNameWords is the maximum number of words in a name matched
+	// within a longer phrase.
+	maxNameWords = 3
+)
+
+// A Place is a normalized location. Fields which could not be
+// determined are empty; a location which could not be normalized at
+// all has zero Confidence.
+type Place struct {
+	City        string  `json:"city,omitempty"`
+	Region      string  `json:"region,omitempty"`
+	Country     string  `json:"country,omitempty"`
+	CountryCode string  `json:"country_code,omitempty"` // ISO 3166-1 alpha-2
+	Lat         float64 `json:"lat"`
+	Lon         float64 `json:"lon"`
+	Confidence  float64 `json:"confidence"`
+}
+
+// Name returns the most specific name of the place, or the empty
+// string if the place is unknown.
+func (p Place) Name() string {
+	switch {
+	case len(p.City) > 0:
+		return p.City
+	case len(p.Region) > 0:
+		return p.Region
+	}
+	return p.Country
+}
+
+var (
+	countryByCode  = map[string]*country{}
+	countryByName  = map[string][]*country{}
+	regionByName   = map[string][]*region{}
+	regionByAbbrev = map[string][]*region{}
+	cityByName     = map[string][]*city{}
+)
+
+func init() {
+	for i := range countries {
+		c := &countries[i]
+		countryByCode[c.code] = c
+		for _, n := range append([]string{strings.ToLower(c.name)}, c.aliases...) {
+			countryByName[n] = append(countryByName[n], c)
+		}
+	}
+	for i := range regions {
+		r := &regions[i]
+		for _, n := range append([]string{strings.ToLower(r.name)}, r.aliases...) {
+			regionByName[n] = append(regionByName[n], r)
+		}
+		if len(r.abbrev) > 0 {
+			regionByAbbrev[r.abbrev] = append(regionByAbbrev[r.abbrev], r)
+		}
+	}
+	for i := range cities {
+		c := &cities[i]
+		for _, n := range append([]string{strings.ToLower(c.name)}, c.aliases...) {
+			cityByName[n] = append(cityByName[n], c)
+		}
+	}
+}
+
+// matches holds the gazetteer entries named in one part of a location.
+type matches struct {
+	cities    []*city
+	regions   []*region
+	countries []*country
+	embedded  bool // Found within a longer phrase
+}
+
+// Normalize maps a free-text location to a place. The location is
+// split into comma-separated (or similarly delimited) parts, each of
+// which is looked up in the gazetteer as a whole or, failing that,
+// by its runs of up to maxNameWords words. Region abbreviations and
+// country codes are only recognized as whole parts following the
+// first, as in "Austin, TX" or "Berlin, DE".
+//
+// A city is preferred when it is consistent with any region or
+// country also named; otherwise the region or country is used. A
+// city named alone is resolved to the most populous city of that
+// name.
+func Normalize(location string) Place {
+	location = strings.Replace(strings.ToLower(location), " - ", ",", -1)
+	parts := strings.FieldsFunc(location, func(r rune) bool {
+		return strings.ContainsRune(",;/|()[]·•\n", r)
+	})
+	var ms []matches
+	for i, part := range parts {
+		part = strings.Trim(strings.TrimSpace(part), ".-")
+		if len(part) == 0 {
+			continue
+		}
+		m := lookup(part)
+		if i > 0 && m.empty() {
+			m.regions = regionByAbbrev[part]
+			if c, ok := countryByCode[strings.ToUpper(part)]; ok && len(part) == 2 {
+				m.countries = append(m.countries, c)
+			}
+		}
+		if m.empty() {
+			m = lookupEmbedded(part)
+		}
+		if !m.empty() {
+			ms = append(ms, m)
+		}
+	}
+	return resolve(ms)
+}
+
+func (m matches) empty() bool {
+	return len(m.cities) == 0 && len(m.regions) == 0 && len(m.countries) == 0
+}
+
+// lookup returns the entries named by the whole of s.
+func lookup(s string) matches {
+	return matches{cities: cityByName[s], regions: regionByName[s], countries: countryByName[s]}
+}
+
+// lookupEmbedded returns the entries named by the longest runs of
+// words within s. Names of fewer than four letters are ignored to
+// avoid matching ordinary words.
+func lookupEmbedded(s string) matches {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || (unicode.IsPunct(r) && r != '-' && r != '.' && r != '\'')
+	})
+	for n := maxNameWords; n > 0; n-- {
+		var m matches
+		for i := 0; i+n <= len(words); i++ {
+			name := strings.Trim(strings.Join(words[i:i+n], " "), ".")
+			if len(name) < 4 {
+				continue
+			}
+			found := lookup(name)
+			m.cities = append(m.cities, found.cities...)
+			m.regions = append(m.regions, found.regions...)
+			m.countries = append(m.countries, found.countries...)
+		}
+		if !m.empty() {
+			m.embedded = true
+			return m
+		}
+	}
+	return matches{}
+}
+
+// resolve chooses the most specific place consistent with the
+// entries named in each part of a location.
+func resolve(ms []matches) Place {
+	inRegion := map[string]bool{}        // Keyed by country code and region name
+	regionInCountry := map[string]bool{} // Whether any region of the country is named
+	inCountry := map[string]bool{}
+	var firstRegion *region
+	var firstCountry *country
+	regionEmbedded, countryEmbedded := false, false
+	for _, m := range ms {
+		for _, r := range m.regions {
+			inRegion[r.country+"/"+r.name] = true
+			regionInCountry[r.country] = true
+			inCountry[r.country] = true
+			if firstRegion == nil {
+				firstRegion, regionEmbedded = r, m.embedded
+			}
+		}
+		for _, c := range m.countries {
+			inCountry[c.code] = true
+			if firstCountry == nil {
+				firstCountry, countryEmbedded = c, m.embedded
+			}
+		}
+	}
+
+	// Prefer a city consistent with a named region or country. A city
+	// is inconsistent with a named region of its own country, unless
+	// the region is its own.
+	for _, m := range ms {
+		for _, c := range m.cities {
+			if inCountry[c.country] && (!regionInCountry[c.country] || inRegion[c.country+"/"+c.region]) {
+				return cityPlace(c, ConfirmedCity, m.embedded)
+			}
+		}
+	}
+	if firstRegion != nil {
+		p := Place{Region: firstRegion.name, Lat: firstRegion.lat, Lon: firstRegion.lon, Confidence: Region}
+		if c := countryByCode[firstRegion.country]; c != nil {
+			p.Country, p.CountryCode = c.name, c.code
+		}
+		if regionEmbedded {
+			p.Confidence *= embeddedFactor
+		}
+		return p
+	}
+	if firstCountry != nil {
+		p := Place{Country: firstCountry.name, CountryCode: firstCountry.code,
+			Lat: firstCountry.lat, Lon: firstCountry.lon, Confidence: Country}
+		if countryEmbedded {
+			p.Confidence *= embeddedFactor
+		}
+		return p
+	}
+	for _, m := range ms {
+		if len(m.cities) == 0 {
+			continue
+		}
+		confidence := City
+		for _, c := range m.cities[1:] {
+			if c != m.cities[0] {
+				confidence = AmbiguousCity
+			}
+		}
+		return cityPlace(m.cities[0], confidence, m.embedded)
+	}
+	return Place{}
+}
+
+func cityPlace(c *city, confidence float64, embedded bool) Place {
+	p := Place{City: c.name, Region: c.region, Lat: c.lat, Lon: c.lon, Confidence: confidence}
+	if co := countryByCode[c.country]; co != nil {
+		p.Country, p.CountryCode = co.name, co.code
+	}
+	if embedded {
+		p.Confidence *= embeddedFactor
+	}
+	return p
+}
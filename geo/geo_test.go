@@ -0,0 +1,84 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		location    string
+		city        string
+		region      string
+		countryCode string
+		confidence  float64
+	}{
+		{"", "", "", "", 0},
+		{"Earth", "", "", "", 0},
+		{"Germany", "", "", "DE", Country},
+		{"UK", "", "", "GB", Country},
+		{"USA", "", "", "US", Country},
+		{"California", "", "California", "US", Region},
+		{"San Francisco, CA", "San Francisco", "California", "US", ConfirmedCity},
+		{"New York, NY", "New York", "New York", "US", ConfirmedCity},
+		{"Berlin, Germany", "Berlin", "", "DE", ConfirmedCity},
+		{"Berlin", "Berlin", "", "DE", City},
+		{"München", "Munich", "Bavaria", "DE", City},
+		{"SF Bay Area", "San Francisco", "California", "US", City},
+		{"Portland", "Portland", "Oregon", "US", AmbiguousCity},
+		{"living in Berlin", "Berlin", "", "DE", City * embeddedFactor},
+	}
+	for _, c := range testCases {
+		p := Normalize(c.location)
+		if p.City != c.city || p.Region != c.region || p.CountryCode != c.countryCode {
+			t.Errorf("%q: expected %q, %q, %q; got %q, %q, %q",
+				c.location, c.city, c.region, c.countryCode, p.City, p.Region, p.CountryCode)
+		}
+		if math.Abs(p.Confidence-c.confidence) > 1e-9 {
+			t.Errorf("%q: expected confidence %.2f; got %.2f", c.location, c.confidence, p.Confidence)
+		}
+	}
+}
+
+func TestUTCOffset(t *testing.T) {
+	testCases := []struct {
+		location string
+		offset   float64
+		ok       bool
+	}{
+		{"Earth", 0, false},
+		{"London", 0, true},
+		{"Berlin", 1, true},
+		{"Tokyo, Japan", 9, true},
+		{"New York", -5, true},
+		{"San Francisco, CA", -8, true},
+		{"California", -8, true},
+		{"Germany", 1, true},
+		// Countries spanning several time zones are unknown without a
+		// city or region.
+		{"USA", 0, false},
+		{"Canada", 0, false},
+	}
+	for _, c := range testCases {
+		offset, ok := Normalize(c.location).UTCOffset()
+		if offset != c.offset || ok != c.ok {
+			t.Errorf("%q: expected %v, %t; got %v, %t", c.location, c.offset, c.ok, offset, ok)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package geo
+
+import "math"
+
+// standardOffsets are the standard time UTC offsets, in hours, of
+// countries in the gazetteer which observe a single time zone.
+var standardOffsets = map[string]float64{
+	"AR": -3, "CL": -4, "CO": -5, "PE": -5, "GB": 0, "IE": 0, "FR": 1, "DE": 1, "NL": 1,
+	"BE": 1, "LU": 1, "CH": 1, "AT": 1, "ES": 1, "PT": 0, "IT": 1, "SE": 1, "NO": 1, "DK": 1,
+	"FI": 2, "IS": 0, "PL": 1, "CZ": 1, "SK": 1, "HU": 1, "RO": 2, "BG": 2, "GR": 2, "TR": 3,
+	"UA": 2, "BY": 3, "LT": 2, "LV": 2, "EE": 2, "RS": 1, "HR": 1, "SI": 1, "IL": 2, "AE": 4,
+	"SA": 3, "EG": 2, "NG": 1, "KE": 3, "ZA": 2, "MA": 1, "IN": 5.5, "PK": 5, "BD": 6,
+	"LK": 5.5, "NP": 5.75, "IR": 3.5, "CN": 8, "HK": 8, "TW": 8, "JP": 9, "KR": 9, "SG": 8,
+	"MY": 8, "TH": 7, "VN": 7, "PH": 8, "NZ": 12,
+}
+
+// UTCOffset returns the standard time UTC offset in hours of a place,
+// and whether it is known. Offsets of countries spanning several time
+// zones are estimated from the longitude of the city or region, and
+// unknown if only the country is.
+func (p Place) UTCOffset() (float64, bool) {
+	if p.Confidence == 0 {
+		return 0, false
+	}
+	if offset, ok := standardOffsets[p.CountryCode]; ok {
+		return offset, true
+	}
+	if len(p.City) == 0 && len(p.Region) == 0 {
+		return 0, false
+	}
+	return math.Floor(p.Lon/15 + 0.5), true
+}
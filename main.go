@@ -99,8 +99,11 @@ func runGenDoc(c *cobra.Command, args []string) error {
 func init() {
 	stargazersCmd.AddCommand(
 		cmd.AnalyzeCmd,
+		cmd.ChartCmd,
 		cmd.ClearCmd,
 		cmd.FetchCmd,
+		cmd.ForgetCmd,
+		cmd.ReportCmd,
 		genDocCmd,
 	)
 	// Map any flags registered in the standard "flag" package into the
@@ -113,6 +116,10 @@ func init() {
 	stargazersCmd.PersistentFlags().StringVarP(&cmd.Repo, "repo", "r", "", cmd.RepoDesc)
 	stargazersCmd.PersistentFlags().StringVarP(&cmd.AccessToken, "token", "t", "", cmd.AccessTokenDesc)
 	stargazersCmd.PersistentFlags().StringVarP(&cmd.CacheDir, "cache", "c", "./stargazer_cache", cmd.CacheDirDesc)
+	// The top-level command fetches and then analyzes.
+	cmd.AddFetchFlags(stargazersCmd)
+	cmd.AddAnalysisFlags(stargazersCmd)
+	stargazersCmd.Flags().StringVarP(&cmd.Format, "format", "f", "csv", cmd.FormatDesc)
 }
 
 // Run ...
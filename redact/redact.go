@@ -0,0 +1,217 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+// Package redact removes personally identifying information from
+// stargazer data before it's analyzed, so that results may be shared
+// outside the team. Logins are replaced by salted hashes; names,
+// emails, bios, blogs and URLs are dropped; locations are generalized
+// to countries and companies to normalized employers; and values
+// shared by fewer than a minimum number of stargazers are suppressed.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/spencerkimball/stargazers/company"
+	"github.com/spencerkimball/stargazers/fetch"
+	"github.com/spencerkimball/stargazers/geo"
+)
+
+// DefaultMinGroupSize is the default minimum number of stargazers
+// which must share a country, employer, organization or repo for it
+// to be kept.
+const DefaultMinGroupSize = 5
+
+// hashLen is the number of hex digits of a hashed login.
+const hashLen = 12
+
+// Options configure redaction.
+type Options struct {
+	// Salt keys the hash of logins. Hashes are consistent across runs
+	// with the same salt, so redacted results may be joined, but
+	// can't be reversed by hashing known logins without it.
+	Salt string
+	// MinGroupSize is the minimum number of stargazers which must
+	// share a country, employer, organization or starred, subscribed
+	// or contributed repo for it to be kept.
+	MinGroupSize int
+	// CompanyOverrides are applied when normalizing companies to
+	// employers, which requires the unredacted login, email, blog and
+	// organizations.
+	CompanyOverrides *company.Overrides
+}
+
+// A Redactor hashes logins with a salt.
+type Redactor struct {
+	opts Options
+}
+
+// New returns a redactor with the specified options. A salt is
+// required, as unsalted hashes of logins are trivially reversed.
+func New(opts Options) (*Redactor, error) {
+	if len(opts.Salt) == 0 {
+		return nil, errors.New("redaction requires a salt; use --redact-salt")
+	}
+	if opts.MinGroupSize < 1 {
+		opts.MinGroupSize = 1
+	}
+	return &Redactor{opts: opts}, nil
+}
+
+// Login returns the salted hash of a login. Logins are case
+// insensitive, so are hashed in lower case.
+func (r *Redactor) Login(login string) string {
+	if len(login) == 0 {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(r.opts.Salt))
+	mac.Write([]byte(strings.ToLower(login)))
+	return "u_" + hex.EncodeToString(mac.Sum(nil))[:hashLen]
+}
+
+// user redacts the identifying fields of a user.
+func (r *Redactor) user(u *fetch.User) {
+	u.Login = r.Login(u.Login)
+	u.AvatarURL = ""
+	u.GravatarID = ""
+	u.URL = ""
+	u.HtmlURL = ""
+	u.FollowersURL = ""
+	u.FollowingURL = ""
+	u.StarredURL = ""
+	u.SubscriptionsURL = ""
+	u.OrganizationsURL = ""
+	u.Name = ""
+	u.Email = ""
+	u.Blog = ""
+	u.Bio = ""
+}
+
+// Redact redacts the stargazers, the contributor statistics of the
+// repos and the snapshots in place.
+//
+// Each stargazer's company is replaced by their normalized employer
+// and their location by its country, and both are dropped unless
+// shared by at least MinGroupSize stargazers. Organizations, and
+// starred, subscribed and contributed repos, are likewise dropped
+// unless shared by at least MinGroupSize stargazers, as a personal
+// repo or small organization would identify its owner.
+func (r *Redactor) Redact(sg []*fetch.Stargazer, rs map[string]*fetch.Repo, snaps []*fetch.Snapshot) {
+	profiles := make([]company.Profile, len(sg))
+	for i, s := range sg {
+		profiles[i] = company.Profile{Login: s.Login, Company: s.Company, Email: s.Email, Blog: s.Blog, Orgs: s.Orgs}
+	}
+	employers := company.NewNormalizer(r.opts.CompanyOverrides).Normalize(profiles)
+
+	employerCounts := map[string]int{}
+	countryCounts := map[string]int{}
+	orgCounts := map[string]int{}
+	repoCounts := map[string]int{}
+	for i, s := range sg {
+		s.Company = employers[i].Name
+		s.Location = geo.Normalize(s.Location).Country
+		employerCounts[s.Company]++
+		countryCounts[s.Location]++
+		for _, org := range s.Orgs {
+			orgCounts[strings.ToLower(org)]++
+		}
+		// Count each repo once per stargazer, however it's related.
+		repos := map[string]struct{}{}
+		for _, name := range s.Starred {
+			repos[name] = struct{}{}
+		}
+		for _, name := range s.Subscribed {
+			repos[name] = struct{}{}
+		}
+		for name := range s.Contributions {
+			repos[name] = struct{}{}
+		}
+		for name := range repos {
+			repoCounts[name]++
+		}
+	}
+
+	k := r.opts.MinGroupSize
+	for _, s := range sg {
+		if employerCounts[s.Company] < k {
+			s.Company = ""
+		}
+		if countryCounts[s.Location] < k {
+			s.Location = ""
+		}
+		var orgs []string
+		for _, org := range s.Orgs {
+			if orgCounts[strings.ToLower(org)] >= k {
+				orgs = append(orgs, org)
+			}
+		}
+		s.Orgs = orgs
+		var starred, starredTimes []string
+		for i, name := range s.Starred {
+			if repoCounts[name] < k {
+				continue
+			}
+			starred = append(starred, name)
+			if i < len(s.StarredTimes) {
+				starredTimes = append(starredTimes, s.StarredTimes[i])
+			}
+		}
+		s.Starred = starred
+		if len(s.StarredTimes) > 0 {
+			s.StarredTimes = starredTimes
+		}
+		var subscribed []string
+		for _, name := range s.Subscribed {
+			if repoCounts[name] >= k {
+				subscribed = append(subscribed, name)
+			}
+		}
+		s.Subscribed = subscribed
+		for name, contrib := range s.Contributions {
+			if repoCounts[name] < k {
+				delete(s.Contributions, name)
+				continue
+			}
+			contrib.Login = r.Login(contrib.Login)
+		}
+
+		r.user(&s.User)
+		for _, u := range s.Followers {
+			r.user(u)
+		}
+		for _, u := range s.Following {
+			r.user(u)
+		}
+	}
+
+	for _, repo := range rs {
+		stats := make(map[string]*fetch.Contribution, len(repo.Statistics))
+		for login, contrib := range repo.Statistics {
+			contrib.Login = r.Login(contrib.Login)
+			stats[r.Login(login)] = contrib
+		}
+		repo.Statistics = stats
+	}
+	for _, snap := range snaps {
+		for _, e := range snap.Stargazers {
+			e.Login = r.Login(e.Login)
+		}
+	}
+}
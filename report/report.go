@@ -0,0 +1,366 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+// Package report renders analysis results as a single self-contained
+// HTML dashboard. Charts are rendered server-side as inline SVG so the
+// report may be viewed offline.
+package report
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spencerkimball/stargazers/analyze"
+	"github.com/spencerkimball/stargazers/chart"
+)
+
+const (
+	// nTopRepos is the number of correlated repos to chart.
+	nTopRepos = 20
+	// nLeaders is the number of rows in the leaderboard tables.
+	nLeaders = 25
+)
+
+// table is a titled table of preformatted cells.
+type table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// section is a titled part of the dashboard, holding any number of
+// charts followed by an optional table.
+type section struct {
+	Title  string
+	Charts []template.HTML
+	Table  *table
+}
+
+type page struct {
+	Repo     string
+	Summary  [][2]string
+	Sections []*section
+}
+
+// WriteHTML writes an HTML dashboard of the analysis results for
+// the specified repo.
+func WriteHTML(w io.Writer, repo string, res *analyze.Results) error {
+	p := &page{Repo: repo}
+
+	days := res.CumulativeStars.Days
+	if len(days) > 0 {
+		p.Summary = append(p.Summary,
+			[2]string{"Stars", strconv.Itoa(days[len(days)-1].Cumulative)},
+			[2]string{"First star", days[0].Date.Format("Jan 2, 2006")},
+			[2]string{"Latest star", days[len(days)-1].Date.Format("Jan 2, 2006")},
+		)
+	}
+	p.Summary = append(p.Summary, [2]string{"Committers", strconv.Itoa(len(res.Committers.Committers))})
+
+	var err error
+	add := func(s *section, c chart.Chart) {
+		if err != nil {
+			return
+		}
+		var buf bytes.Buffer
+		if err = c.SVG(&buf); err == nil {
+			s.Charts = append(s.Charts, template.HTML(buf.String()))
+		}
+	}
+
+	// Cumulative stars.
+	cum := &section{Title: "Cumulative stars"}
+	add(cum, chart.CumulativeStars(res.CumulativeStars))
+	p.Sections = append(p.Sections, cum)
+
+	// Star forecast.
+	if len(res.Forecast.Path) > 0 {
+		fs := &section{Title: "Star forecast (" + res.Forecast.Model + ")"}
+		add(fs, chart.Forecast(res.CumulativeStars, res.Forecast))
+		fs.Table = &table{Header: res.Forecast.Header(), Rows: res.Forecast.Rows()}
+		p.Sections = append(p.Sections, fs)
+	}
+
+	// Stargazer attributes by week.
+	attrs := &section{Title: "Stargazer attributes by week"}
+	for _, a := range chart.AttributesByTime(res.AttributesByTime) {
+		a.Width, a.Height = 480, 240
+		add(attrs, a.LineChart)
+	}
+	p.Sections = append(p.Sections, attrs)
+
+	// Overall distributions of stargazer attributes.
+	dt := &table{Header: []string{"Attribute", "Median", "95% CI", "P10", "P90", "P99", "Mean", "Gini"}}
+	for _, d := range res.Distributions.Distributions {
+		if d.Period != analyze.AllPeriods {
+			continue
+		}
+		dt.Rows = append(dt.Rows, []string{d.Metric, strconv.FormatFloat(d.Median, 'f', 1, 64),
+			strconv.FormatFloat(d.MedianLow, 'f', 1, 64) + "-" + strconv.FormatFloat(d.MedianHigh, 'f', 1, 64),
+			strconv.FormatFloat(d.P10, 'f', 1, 64), strconv.FormatFloat(d.P90, 'f', 1, 64),
+			strconv.FormatFloat(d.P99, 'f', 1, 64), strconv.FormatFloat(d.Mean, 'f', 1, 64),
+			strconv.FormatFloat(d.Gini, 'f', 2, 64)})
+	}
+	p.Sections = append(p.Sections, &section{Title: "Stargazer attribute distributions", Table: dt})
+
+	// Correlated repos and correlation histograms.
+	for _, pair := range []struct {
+		repos *analyze.CorrelatedReposResult
+		hist  *analyze.CorrelationHistogramResult
+	}{
+		{res.CorrelatedStarred, res.StarredHistogram},
+		{res.CorrelatedSubscribed, res.SubscribedHistogram},
+	} {
+		s := &section{Title: "Top correlated " + pair.repos.ListType + " repos (by " + pair.repos.Metric + ")"}
+		add(s, chart.CorrelatedRepos(pair.repos, nTopRepos))
+		add(s, chart.CorrelationHistogram(pair.hist))
+		t := &table{Header: []string{"Repository", "Count", "Lift", "Committers", "Commits"}}
+		for i, r := range pair.repos.Repos {
+			if i >= nTopRepos {
+				break
+			}
+			t.Rows = append(t.Rows, []string{r.Name, strconv.Itoa(r.Count), strconv.FormatFloat(r.Lift, 'f', 1, 64),
+				strconv.Itoa(r.Committers), strconv.Itoa(r.Commits)})
+		}
+		s.Table = t
+		p.Sections = append(p.Sections, s)
+	}
+
+	// Correlated owners.
+	for _, owners := range []*analyze.CorrelatedOwnersResult{res.StarredOwners, res.SubscribedOwners} {
+		t := &table{Header: []string{"Owner", "Stargazers", "Repos", "Committers", "Commits", "Top Repos"}}
+		for i, o := range owners.Owners {
+			if i >= nTopRepos {
+				break
+			}
+			var top []string
+			for _, r := range o.TopRepos {
+				top = append(top, r.Name)
+			}
+			t.Rows = append(t.Rows, []string{o.Owner, strconv.Itoa(o.Stargazers), strconv.Itoa(o.Repos),
+				strconv.Itoa(o.Committers), strconv.Itoa(o.Commits), strings.Join(top, ", ")})
+		}
+		p.Sections = append(p.Sections, &section{Title: "Top correlated " + owners.ListType + " repo owners", Table: t})
+	}
+
+	// Stars by hour of week and audience time zones.
+	tz := &section{Title: "Stars by hour of week"}
+	add(tz, chart.StarHeatmap(res.StarHeatmap))
+	tz.Table = &table{Header: res.Timezones.Header(), Rows: res.Timezones.Rows()}
+	p.Sections = append(p.Sections, tz)
+
+	// Stargazers by country.
+	byCountry := map[string]*countryStars{}
+	var countries []*countryStars
+	for _, m := range res.Geography.Months {
+		if _, ok := byCountry[m.Country]; !ok {
+			byCountry[m.Country] = &countryStars{country: m.Country}
+			countries = append(countries, byCountry[m.Country])
+		}
+		byCountry[m.Country].count += m.NewStars
+	}
+	sort.Stable(byCountryStars(countries))
+	gt := &table{Header: []string{"Country", "Stargazers", "Share %"}}
+	for i, c := range countries {
+		if i >= nTopRepos {
+			break
+		}
+		gt.Rows = append(gt.Rows, []string{c.country, strconv.Itoa(c.count),
+			strconv.FormatFloat(100*float64(c.count)/float64(len(res.Locations.Stargazers)), 'f', 1, 64)})
+	}
+	p.Sections = append(p.Sections, &section{Title: "Stargazers by country", Table: gt})
+
+	// Stargazers by employer.
+	et := &table{Header: []string{"Employer", "Stargazers", "Share %", "Committers", "Commits"}}
+	for i, e := range res.Employers.Employers {
+		if i >= nTopRepos {
+			break
+		}
+		et.Rows = append(et.Rows, []string{e.Employer, strconv.Itoa(e.Stargazers), strconv.FormatFloat(e.Share, 'f', 1, 64),
+			strconv.Itoa(e.Committers), strconv.Itoa(e.Commits)})
+	}
+	p.Sections = append(p.Sections, &section{Title: "Stargazers by employer", Table: et})
+
+	// Stargazer roles and bio keywords.
+	p.Sections = append(p.Sections, &section{Title: "Stargazer roles",
+		Table: &table{Header: res.Roles.Header(), Rows: res.Roles.Rows()}})
+	kt := &table{Header: []string{"Bio Keyword", "Stargazers", "Repo Description Keyword", "Repos"}}
+	for i := 0; i < nTopRepos; i++ {
+		row := make([]string, 4)
+		for j, kr := range []*analyze.KeywordsResult{res.BioKeywords, res.RepoKeywords} {
+			if i < len(kr.Keywords) && kr.Keywords[i].Kind == "word" {
+				row[2*j], row[2*j+1] = kr.Keywords[i].Term, strconv.Itoa(kr.Keywords[i].Documents)
+			}
+		}
+		if len(row[0]) > 0 || len(row[2]) > 0 {
+			kt.Rows = append(kt.Rows, row)
+		}
+	}
+	p.Sections = append(p.Sections, &section{Title: "Top keywords", Table: kt})
+
+	// Followers leaderboard.
+	leaders := append([]*analyze.FollowerStats(nil), res.Followers.Stargazers...)
+	sort.Stable(byFollowers(leaders))
+	ft := &table{Header: []string{"Login", "Name", "Company", "Location", "Followers", "Shared Followers"}}
+	for i, f := range leaders {
+		if i >= nLeaders {
+			break
+		}
+		ft.Rows = append(ft.Rows, []string{f.Login, f.Name, f.Company, f.Location,
+			strconv.Itoa(f.Followers), strconv.Itoa(f.SharedFollowers)})
+	}
+	p.Sections = append(p.Sections, &section{Title: "Followers leaderboard", Table: ft})
+
+	// Influence leaderboard.
+	it := &table{Header: []string{"Rank", "Login", "Name", "Influence", "Followers", "Stargazer Followers"}}
+	for i, s := range res.Influence.Stargazers {
+		if i >= nLeaders {
+			break
+		}
+		it.Rows = append(it.Rows, []string{strconv.Itoa(s.Rank), s.Login, s.Name, strconv.FormatFloat(s.Influence, 'f', 2, 64),
+			strconv.Itoa(s.Followers), strconv.Itoa(s.StargazerFollowers)})
+	}
+	p.Sections = append(p.Sections, &section{Title: "Influence leaderboard", Table: it})
+
+	// Committers.
+	ct := &table{Header: []string{"Login", "Commits", "Additions", "Deletions", "Top Repos", "Repo Commits", "Correlated Share %"}}
+	for i, c := range res.Committers.Committers {
+		if i >= nLeaders {
+			break
+		}
+		var top []string
+		for _, r := range c.TopRepos {
+			top = append(top, r.Name)
+		}
+		ct.Rows = append(ct.Rows, []string{c.Login, strconv.Itoa(c.Commits), strconv.Itoa(c.Additions), strconv.Itoa(c.Deletions),
+			strings.Join(top, ", "), strconv.Itoa(c.RepoCommits), strconv.FormatFloat(c.CorrelatedShare, 'f', 1, 64)})
+	}
+	p.Sections = append(p.Sections, &section{Title: "Top committers", Table: ct})
+
+	// Top leads.
+	lt := &table{Header: []string{"Rank", "Login", "Name", "Score", "Country", "Role", "Languages"}}
+	for i, l := range res.Leads.Leads {
+		if i >= nLeaders {
+			break
+		}
+		lt.Rows = append(lt.Rows, []string{strconv.Itoa(l.Rank), l.Login, l.Name, strconv.FormatFloat(l.Score, 'f', 1, 64),
+			l.Country, l.Role, strings.Join(l.Languages, ", ")})
+	}
+	p.Sections = append(p.Sections, &section{Title: "Top leads", Table: lt})
+
+	// Most correlated stargazers.
+	st := &table{Header: []string{"Login", "Name", "Starred", "Score", "Correlated Repos", "Correlated Activity"}}
+	for i, s := range res.StargazerReport.Stargazers {
+		if i >= nLeaders {
+			break
+		}
+		st.Rows = append(st.Rows, []string{s.Login, s.Name, s.StarredAt, strconv.FormatFloat(s.CorrelationScore, 'f', 3, 64),
+			strconv.Itoa(s.CorrelatedRepos), strconv.Itoa(s.CorrelatedActivity)})
+	}
+	p.Sections = append(p.Sections, &section{Title: "Stargazers by " + res.StargazerReport.SortBy, Table: st})
+
+	if err != nil {
+		return err
+	}
+	return pageTmpl.Execute(w, p)
+}
+
+type byFollowers []*analyze.FollowerStats
+
+func (slice byFollowers) Len() int {
+	return len(slice)
+}
+
+func (slice byFollowers) Less(i, j int) bool {
+	if slice[i].Followers != slice[j].Followers {
+		return slice[i].Followers > slice[j].Followers /* descending order */
+	}
+	return slice[i].Login < slice[j].Login
+}
+
+func (slice byFollowers) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+// countryStars is the count of stargazers from a country.
+type countryStars struct {
+	country string
+	count   int
+}
+
+type byCountryStars []*countryStars
+
+func (slice byCountryStars) Len() int {
+	return len(slice)
+}
+
+func (slice byCountryStars) Less(i, j int) bool {
+	if slice[i].count != slice[j].count {
+		return slice[i].count > slice[j].count /* descending order */
+	}
+	return slice[i].country < slice[j].country
+}
+
+func (slice byCountryStars) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+var pageTmpl = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Stargazers of {{.Repo}}</title>
+<style>
+body { font-family: sans-serif; color: #222; margin: 2em auto; max-width: 1000px; }
+h1 { font-weight: normal; }
+h2 { font-weight: normal; border-bottom: 1px solid #ddd; padding-bottom: 4px; margin-top: 2em; }
+.summary { display: flex; gap: 2em; }
+.summary div { background: #f5f5f5; padding: 0.8em 1.2em; border-radius: 4px; }
+.summary b { display: block; font-size: 1.6em; }
+.charts { display: flex; flex-wrap: wrap; gap: 1em; }
+table { border-collapse: collapse; margin-top: 1em; font-size: 0.9em; }
+th, td { text-align: left; padding: 4px 12px; border-bottom: 1px solid #eee; }
+</style>
+</head>
+<body>
+<h1>Stargazers of {{.Repo}}</h1>
+<div class="summary">
+{{- range .Summary}}
+<div>{{index . 0}}<b>{{index . 1}}</b></div>
+{{- end}}
+</div>
+{{- range .Sections}}
+<h2>{{.Title}}</h2>
+<div class="charts">
+{{- range .Charts}}
+{{.}}
+{{- end}}
+</div>
+{{- with .Table}}
+<table>
+<tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
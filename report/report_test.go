@@ -0,0 +1,78 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spencerkimball/stargazers/analyze"
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+// TestWriteHTMLEscapes verifies that text from stargazer profiles and
+// repo names is escaped both in tables and in inline SVG charts.
+func TestWriteHTMLEscapes(t *testing.T) {
+	const evilRepo = "evil/<script>alert(1)</script>"
+	var sg []*fetch.Stargazer
+	for i, login := range []string{"alice", "bob", "carol"} {
+		sg = append(sg, &fetch.Stargazer{
+			User: fetch.User{
+				Login:     login,
+				Name:      "<img src=x onerror=alert(1)>",
+				Company:   "Acme & <Sons>",
+				Location:  "Berlin",
+				CreatedAt: "2012-01-01T00:00:00Z",
+				Followers: i,
+			},
+			StarredAt: time.Date(2016, 1, 4+i, 12, 0, 0, 0, time.UTC).Format(time.RFC3339),
+			Starred:   []string{"owner/repo", evilRepo},
+		})
+	}
+	rs := map[string]*fetch.Repo{
+		"owner/repo": {FullName: "owner/repo", StargazersCount: 3},
+		evilRepo:     {FullName: evilRepo, StargazersCount: 3},
+	}
+	res, err := analyze.ComputeAll(sg, rs, analyze.Options{
+		Repo:  "owner/repo",
+		AsOf:  time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC),
+		Roles: analyze.DefaultRoles,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, "owner/<repo>", res); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, s := range []string{"<script>", "<img", "<Sons>", "owner/<repo>"} {
+		if strings.Contains(html, s) {
+			t.Errorf("expected %q to be escaped", s)
+		}
+	}
+	// The repo name appears both as a bar label in the correlated
+	// repos chart and in its table.
+	if n := strings.Count(html, "evil/&lt;script&gt;alert(1)&lt;/script&gt;"); n < 2 {
+		t.Errorf("expected escaped repo name in chart and table; found %d", n)
+	}
+	if !strings.Contains(html, "&lt;img src=x onerror=alert(1)&gt;") {
+		t.Error("expected escaped stargazer name")
+	}
+}
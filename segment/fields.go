@@ -0,0 +1,118 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package segment
+
+import (
+	"math"
+	"time"
+
+	"github.com/spencerkimball/stargazers/fetch"
+	"github.com/spencerkimball/stargazers/geo"
+)
+
+// kind is the type of a field's values.
+type kind int
+
+const (
+	stringKind kind = iota
+	numberKind
+	timeKind
+	boolKind
+)
+
+func (k kind) String() string {
+	return [...]string{"string", "number", "time", "bool"}[k]
+}
+
+// A record is a stargazer being matched, with attributes derived
+// from the population of stargazers.
+type record struct {
+	*fetch.Stargazer
+	employer string // Normalized employer name
+}
+
+// A field is a stargazer attribute which may be used in expressions.
+type field struct {
+	kind kind
+	get  func(r *record) interface{}
+}
+
+// fields are the attributes of a stargazer available to expressions,
+// by name. Times are parsed from the RFC 3339 timestamps of the
+// GitHub API; derived numbers are computed from the fetched lists,
+// and the employer is normalized across all stargazers as in the
+// employers analysis.
+var fields = map[string]field{
+	"login":    {stringKind, func(r *record) interface{} { return r.Login }},
+	"name":     {stringKind, func(r *record) interface{} { return r.Name }},
+	"company":  {stringKind, func(r *record) interface{} { return r.Company }},
+	"location": {stringKind, func(r *record) interface{} { return r.Location }},
+	"email":    {stringKind, func(r *record) interface{} { return r.Email }},
+	"blog":     {stringKind, func(r *record) interface{} { return r.Blog }},
+	"bio":      {stringKind, func(r *record) interface{} { return r.Bio }},
+	"type":     {stringKind, func(r *record) interface{} { return r.Type }},
+	"employer": {stringKind, func(r *record) interface{} { return r.employer }},
+	"country": {stringKind, func(r *record) interface{} {
+		return geo.Normalize(r.Location).Country
+	}},
+	"country_code": {stringKind, func(r *record) interface{} {
+		return geo.Normalize(r.Location).CountryCode
+	}},
+
+	"followers":    {numberKind, func(r *record) interface{} { return float64(r.User.Followers) }},
+	"following":    {numberKind, func(r *record) interface{} { return float64(r.User.Following) }},
+	"public_repos": {numberKind, func(r *record) interface{} { return float64(r.PublicRepos) }},
+	"public_gists": {numberKind, func(r *record) interface{} { return float64(r.PublicGists) }},
+	"starred":      {numberKind, func(r *record) interface{} { return float64(len(r.Starred)) }},
+	"subscribed":   {numberKind, func(r *record) interface{} { return float64(len(r.Subscribed)) }},
+	"orgs":         {numberKind, func(r *record) interface{} { return float64(len(r.Orgs)) }},
+	"contributed":  {numberKind, func(r *record) interface{} { return float64(len(r.Contributions)) }},
+	"commits": {numberKind, func(r *record) interface{} {
+		c, _, _ := r.TotalCommits()
+		return float64(c)
+	}},
+	"additions": {numberKind, func(r *record) interface{} {
+		_, a, _ := r.TotalCommits()
+		return float64(a)
+	}},
+	"deletions": {numberKind, func(r *record) interface{} {
+		_, _, d := r.TotalCommits()
+		return float64(d)
+	}},
+	"age_at_star": {numberKind, func(r *record) interface{} {
+		starred, err1 := time.Parse(time.RFC3339, r.StarredAt)
+		created, err2 := time.Parse(time.RFC3339, r.CreatedAt)
+		if err1 != nil || err2 != nil {
+			return math.NaN()
+		}
+		return starred.Sub(created).Hours() / 24
+	}},
+
+	"starred_at": {timeKind, func(r *record) interface{} { return parseTime(r.StarredAt) }},
+	"created_at": {timeKind, func(r *record) interface{} { return parseTime(r.CreatedAt) }},
+	"updated_at": {timeKind, func(r *record) interface{} { return parseTime(r.UpdatedAt) }},
+
+	"hireable":   {boolKind, func(r *record) interface{} { return r.Hireable }},
+	"site_admin": {boolKind, func(r *record) interface{} { return r.SiteAdmin }},
+}
+
+// parseTime parses an RFC 3339 timestamp, returning the zero time if
+// it is invalid.
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
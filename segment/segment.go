@@ -0,0 +1,511 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+// Package segment implements a small expression language for
+// selecting a segment of a repo's stargazers, such as:
+//
+//	followers > 100 && company ~ "google" && starred_at >= 2024-01-01
+//
+// Comparisons are of a field against a literal: a number, a quoted
+// string, a date (YYYY-MM-DD or an RFC 3339 time), or true or
+// false. Strings are compared without regard to case, with ~ and !~
+// matching a regular expression. Comparisons may be combined with &&,
+// || and !, and grouped in parentheses. Boolean fields may be used
+// alone, and @name refers to a named segment.
+package segment
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spencerkimball/stargazers/company"
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+// An Expr is a parsed segment expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Normalized returns the tokens of the expression's source separated
+// by single spaces, so that expressions which differ only in
+// whitespace are the same.
+func (e *Expr) Normalized() string {
+	toks, err := lex(e.src)
+	if err != nil {
+		return e.src
+	}
+	var strs []string
+	for _, t := range toks {
+		switch t.typ {
+		case tokEOF:
+			continue
+		case tokString:
+			strs = append(strs, strconv.Quote(t.val))
+		case tokSegment:
+			strs = append(strs, "@"+t.val)
+		default:
+			strs = append(strs, t.val)
+		}
+	}
+	return strings.Join(strs, " ")
+}
+
+// Parse parses a segment expression. Named segments referenced with
+// @name are looked up in segments and parsed in turn.
+func Parse(src string, segments map[string]string) (*Expr, error) {
+	p := &parser{segments: segments, parsing: map[string]bool{}}
+	root, err := p.parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %s", src, err)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// Fields returns the names of the fields which may be used in
+// expressions, in sorted order.
+func Fields() []string {
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Filter returns the stargazers matching the expression, in their
+// original order. Employers are normalized across all stargazers
+// using the overrides, which may be nil.
+func Filter(sg []*fetch.Stargazer, e *Expr, overrides *company.Overrides) []*fetch.Stargazer {
+	profiles := make([]company.Profile, len(sg))
+	for i, s := range sg {
+		profiles[i] = company.Profile{
+			Login:   s.Login,
+			Company: s.Company,
+			Email:   s.Email,
+			Blog:    s.Blog,
+			Orgs:    s.Orgs,
+		}
+	}
+	employers := company.NewNormalizer(overrides).Normalize(profiles)
+	var matched []*fetch.Stargazer
+	for i, s := range sg {
+		if e.root.eval(&record{Stargazer: s, employer: employers[i].Name}) {
+			matched = append(matched, s)
+		}
+	}
+	return matched
+}
+
+// A node is a node of a parsed expression.
+type node interface {
+	eval(r *record) bool
+}
+
+type andNode struct{ l, r node }
+
+func (n andNode) eval(r *record) bool { return n.l.eval(r) && n.r.eval(r) }
+
+type orNode struct{ l, r node }
+
+func (n orNode) eval(r *record) bool { return n.l.eval(r) || n.r.eval(r) }
+
+type notNode struct{ x node }
+
+func (n notNode) eval(r *record) bool { return !n.x.eval(r) }
+
+// A boolNode is a boolean field used alone.
+type boolNode struct{ f field }
+
+func (n boolNode) eval(r *record) bool { return n.f.get(r).(bool) }
+
+// A cmpNode compares a field against a literal. Comparisons against
+// missing times or numbers never match.
+type cmpNode struct {
+	f   field
+	op  string
+	lit interface{}
+	re  *regexp.Regexp
+}
+
+func (n cmpNode) eval(r *record) bool {
+	switch v := n.f.get(r).(type) {
+	case string:
+		switch n.op {
+		case "==":
+			return strings.EqualFold(v, n.lit.(string))
+		case "!=":
+			return !strings.EqualFold(v, n.lit.(string))
+		case "~":
+			return n.re.MatchString(v)
+		case "!~":
+			return !n.re.MatchString(v)
+		}
+	case float64:
+		if v != v {
+			return false
+		}
+		return compare(n.op, v-n.lit.(float64))
+	case time.Time:
+		if v.IsZero() {
+			return false
+		}
+		return compare(n.op, float64(v.Sub(n.lit.(time.Time))))
+	case bool:
+		return (v == n.lit.(bool)) == (n.op == "==")
+	}
+	return false
+}
+
+// compare applies a comparison operator to the difference of two
+// values.
+func compare(op string, d float64) bool {
+	switch op {
+	case "==":
+		return d == 0
+	case "!=":
+		return d != 0
+	case "<":
+		return d < 0
+	case "<=":
+		return d <= 0
+	case ">":
+		return d > 0
+	case ">=":
+		return d >= 0
+	}
+	return false
+}
+
+// operators are the comparison operators valid for each kind of
+// field.
+var operators = map[kind]map[string]bool{
+	stringKind: {"==": true, "!=": true, "~": true, "!~": true},
+	numberKind: {"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true},
+	timeKind:   {"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true},
+	boolKind:   {"==": true, "!=": true},
+}
+
+type tokenType int
+
+const (
+	tokEOF tokenType = iota
+	tokIdent
+	tokSegment
+	tokNumber
+	tokString
+	tokDate
+	tokOp
+)
+
+type token struct {
+	typ tokenType
+	val string
+	pos int
+}
+
+var (
+	identRE  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+	dateRE   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}:\d{2}(Z|[+-]\d{2}:\d{2}))?`)
+	numberRE = regexp.MustCompile(`^-?\d+(\.\d+)?`)
+	opTokens = []string{"&&", "||", "==", "!=", "!~", "<=", ">=", "<", ">", "~", "!", "(", ")"}
+)
+
+// lex splits an expression into tokens.
+func lex(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		if c := rest[0]; c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			i++
+			continue
+		}
+		if m := identRE.FindString(rest); len(m) > 0 {
+			toks = append(toks, token{tokIdent, m, i})
+			i += len(m)
+			continue
+		}
+		if rest[0] == '@' {
+			m := identRE.FindString(rest[1:])
+			if len(m) == 0 {
+				return nil, fmt.Errorf("expected segment name at offset %d", i+1)
+			}
+			toks = append(toks, token{tokSegment, m, i})
+			i += 1 + len(m)
+			continue
+		}
+		if m := dateRE.FindString(rest); len(m) > 0 {
+			toks = append(toks, token{tokDate, m, i})
+			i += len(m)
+			continue
+		}
+		if m := numberRE.FindString(rest); len(m) > 0 {
+			toks = append(toks, token{tokNumber, m, i})
+			i += len(m)
+			continue
+		}
+		if rest[0] == '"' {
+			s, n, err := lexString(rest)
+			if err != nil {
+				return nil, fmt.Errorf("%s at offset %d", err, i)
+			}
+			toks = append(toks, token{tokString, s, i})
+			i += n
+			continue
+		}
+		found := false
+		for _, op := range opTokens {
+			if strings.HasPrefix(rest, op) {
+				toks = append(toks, token{tokOp, op, i})
+				i += len(op)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unexpected %q at offset %d", rest[0], i)
+		}
+	}
+	return append(toks, token{tokEOF, "", len(src)}), nil
+}
+
+// lexString reads a double-quoted string with backslash escapes,
+// returning the unquoted string and the number of bytes read.
+func lexString(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 == len(s) {
+				break
+			}
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+type parser struct {
+	segments map[string]string
+	parsing  map[string]bool // Named segments being parsed, to detect cycles
+	toks     []token
+	pos      int
+}
+
+// parse parses an expression, returning its root node.
+func (p *parser) parse(src string) (node, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	saved, savedPos := p.toks, p.pos
+	p.toks, p.pos = toks, 0
+	defer func() { p.toks, p.pos = saved, savedPos }()
+
+	n, err := p.parseOr()
+	if err == nil && p.peek().typ != tokEOF {
+		err = p.errorf("unexpected %q", p.peek().val)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.typ != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the supplied operator.
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.typ == tokOp && t.val == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf(format+" at offset %d", append(args, p.peek().pos)...)
+}
+
+func (p *parser) parseOr() (node, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = orNode{l, r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = andNode{l, r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!") {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x}, nil
+	}
+	if p.accept("(") {
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("expected \")\"")
+		}
+		return x, nil
+	}
+	t := p.peek()
+	switch t.typ {
+	case tokSegment:
+		p.next()
+		return p.parseSegment(t.val)
+	case tokIdent:
+		return p.parseComparison()
+	}
+	if t.typ == tokEOF {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("expected field, segment or \"(\" but found %q", t.val)
+}
+
+// parseSegment parses the named segment.
+func (p *parser) parseSegment(name string) (node, error) {
+	src, ok := p.segments[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown segment %q", name)
+	}
+	if p.parsing[name] {
+		return nil, fmt.Errorf("segment %q refers to itself", name)
+	}
+	p.parsing[name] = true
+	defer delete(p.parsing, name)
+	n, err := p.parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("in segment %q: %s", name, err)
+	}
+	return n, nil
+}
+
+func (p *parser) parseComparison() (node, error) {
+	name := p.next().val
+	f, ok := fields[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q; valid fields are %s", name, strings.Join(Fields(), ", "))
+	}
+	t := p.peek()
+	if t.typ != tokOp || !operators[stringKind][t.val] && !operators[numberKind][t.val] {
+		if f.kind == boolKind {
+			return boolNode{f}, nil
+		}
+		return nil, p.errorf("expected comparison after %s", name)
+	}
+	op := p.next().val
+	if !operators[f.kind][op] {
+		return nil, fmt.Errorf("operator %s not valid for %s field %s", op, f.kind, name)
+	}
+	lit := p.next()
+	n := cmpNode{f: f, op: op}
+	var err error
+	switch f.kind {
+	case stringKind:
+		if lit.typ != tokString {
+			return nil, fmt.Errorf("expected quoted string to compare with %s", name)
+		}
+		n.lit = lit.val
+		if op == "~" || op == "!~" {
+			if n.re, err = regexp.Compile("(?i)" + lit.val); err != nil {
+				return nil, fmt.Errorf("invalid regular expression %q: %s", lit.val, err)
+			}
+		}
+	case numberKind:
+		if lit.typ != tokNumber {
+			return nil, fmt.Errorf("expected number to compare with %s", name)
+		}
+		if n.lit, err = strconv.ParseFloat(lit.val, 64); err != nil {
+			return nil, fmt.Errorf("invalid number %q: %s", lit.val, err)
+		}
+	case timeKind:
+		if lit.typ != tokDate && lit.typ != tokString {
+			return nil, fmt.Errorf("expected date to compare with %s", name)
+		}
+		if n.lit, err = parseDate(lit.val); err != nil {
+			return nil, err
+		}
+	case boolKind:
+		if lit.typ != tokIdent || lit.val != "true" && lit.val != "false" {
+			return nil, fmt.Errorf("expected true or false to compare with %s", name)
+		}
+		n.lit = lit.val == "true"
+	}
+	return n, nil
+}
+
+// parseDate parses a date as YYYY-MM-DD (midnight UTC) or as an RFC
+// 3339 time.
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q; use YYYY-MM-DD or RFC 3339", s)
+	}
+	return t, nil
+}
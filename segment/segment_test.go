@@ -0,0 +1,114 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// Author: Spencer Kimball (spencer.kimball@gmail.com)
+
+package segment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spencerkimball/stargazers/fetch"
+)
+
+func TestFilter(t *testing.T) {
+	sg := []*fetch.Stargazer{
+		{User: fetch.User{Login: "alice", Company: "@google", Followers: 150, Hireable: true},
+			StarredAt: "2024-03-01T00:00:00Z"},
+		{User: fetch.User{Login: "bob", Company: "Stripe", Followers: 50},
+			StarredAt: "2023-06-01T00:00:00Z"},
+		{User: fetch.User{Login: "carol", Company: "Google Inc.", Followers: 10},
+			StarredAt: "2024-05-01T00:00:00Z"},
+	}
+	segments := map[string]string{
+		"googlers": `employer == "google"`,
+		"popular":  `followers > 100`,
+	}
+	testCases := []struct {
+		expr     string
+		expected string
+	}{
+		{`followers > 100`, "alice"},
+		{`followers <= 50`, "bob,carol"},
+		{`company ~ "goo"`, "alice,carol"},
+		{`company !~ "goo"`, "bob"},
+		{`login == "BOB"`, "bob"},
+		{`starred_at >= 2024-01-01`, "alice,carol"},
+		{`hireable`, "alice"},
+		{`!hireable && followers < 100`, "bob,carol"},
+		{`(followers > 100 || login == "bob") && starred_at < 2024-01-01`, "bob"},
+		{`@googlers`, "alice,carol"},
+		{`@googlers && !@popular`, "carol"},
+	}
+	for _, tc := range testCases {
+		e, err := Parse(tc.expr, segments)
+		if err != nil {
+			t.Errorf("%s: %s", tc.expr, err)
+			continue
+		}
+		var logins []string
+		for _, s := range Filter(sg, e, nil) {
+			logins = append(logins, s.Login)
+		}
+		if got := strings.Join(logins, ","); got != tc.expected {
+			t.Errorf("%s: expected %s; got %s", tc.expr, tc.expected, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	segments := map[string]string{"loop": "@loop"}
+	testCases := []struct {
+		expr string
+		err  string
+	}{
+		{`followers >`, "expected number"},
+		{`followers > "a"`, "expected number"},
+		{`company > "a"`, "operator > not valid"},
+		{`nosuchfield == 1`, "unknown field"},
+		{`(followers > 1`, "expected \")\""},
+		{`followers > 1 followers`, "unexpected"},
+		{`company == "unterminated`, "unterminated string"},
+		{`company ~ "("`, "invalid regular expression"},
+		{`@missing`, "unknown segment"},
+		{`@loop`, "refers to itself"},
+	}
+	for _, tc := range testCases {
+		if _, err := Parse(tc.expr, segments); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected error containing %q; got %v", tc.expr, tc.err, err)
+		}
+	}
+}
+
+func TestNormalized(t *testing.T) {
+	a, err := Parse(`followers>100&&company ~ "x y"`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Parse(`followers > 100 && company~"x y"`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Normalized() != b.Normalized() {
+		t.Errorf("expected equal normalized expressions; got %q and %q", a.Normalized(), b.Normalized())
+	}
+	c, err := Parse(`followers < 100`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Normalized() == c.Normalized() {
+		t.Errorf("expected different normalized expressions; got %q", c.Normalized())
+	}
+}